@@ -0,0 +1,23 @@
+//go:build !windows
+
+package main
+
+import "fmt"
+
+// windowsAdapter GetAdaptersAddresses 返回的网卡信息, 仅 Windows 可用
+type windowsAdapter struct {
+	Name       string
+	Index      uint32
+	IfType     uint32
+	Type       string
+	MAC        string
+	Up         bool
+	DNSSuffix  string
+	DHCPServer string
+	IPv6       IPv6Config
+}
+
+// listWindowsAdapters 非 Windows 系统不支持
+func listWindowsAdapters() ([]windowsAdapter, error) {
+	return nil, fmt.Errorf("当前系统不支持获取Windows网卡信息")
+}
@@ -0,0 +1,98 @@
+//go:build windows
+
+package main
+
+import (
+	"net"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// windowsAdapter GetAdaptersAddresses 返回的网卡信息
+// 与 netsh 不同，这些字段不受系统语言影响
+type windowsAdapter struct {
+	Name       string     // 网卡名称(FriendlyName), 与 netsh 中的接口名称一致
+	Index      uint32     // 接口索引
+	IfType     uint32     // 接口类型, 如 IF_TYPE_IEEE80211
+	Type       string     // 网卡类型: wifi 或 ethernet, 其他类型为空
+	MAC        string     // 网卡MAC地址
+	Up         bool       // 是否已连接
+	DNSSuffix  string     // 连接的DNS后缀
+	DHCPServer string     // DHCP服务器地址
+	IPv6       IPv6Config // IPv6地址、网关和DNS
+}
+
+// listWindowsAdapters 获取所有网卡的信息
+func listWindowsAdapters() ([]windowsAdapter, error) {
+	size := uint32(15000)
+	var buf []byte
+	for {
+		buf = make([]byte, size)
+		err := windows.GetAdaptersAddresses(windows.AF_UNSPEC, windows.GAA_FLAG_INCLUDE_GATEWAYS, 0,
+			(*windows.IpAdapterAddresses)(unsafe.Pointer(&buf[0])), &size)
+		if err == nil {
+			break
+		}
+		if err != windows.ERROR_BUFFER_OVERFLOW {
+			return nil, err
+		}
+	}
+
+	var adapters []windowsAdapter
+	for aa := (*windows.IpAdapterAddresses)(unsafe.Pointer(&buf[0])); aa != nil; aa = aa.Next {
+		adapter := windowsAdapter{
+			Name:      windows.UTF16PtrToString(aa.FriendlyName),
+			Index:     aa.IfIndex,
+			IfType:    aa.IfType,
+			Up:        aa.OperStatus == windows.IfOperStatusUp,
+			DNSSuffix: windows.UTF16PtrToString(aa.DnsSuffix),
+		}
+		switch aa.IfType {
+		case windows.IF_TYPE_IEEE80211:
+			adapter.Type = InterfaceWiFi
+		case windows.IF_TYPE_ETHERNET_CSMACD:
+			adapter.Type = InterfaceEthernet
+		}
+		if aa.PhysicalAddressLength > 0 {
+			adapter.MAC = net.HardwareAddr(aa.PhysicalAddress[:aa.PhysicalAddressLength]).String()
+		}
+		if aa.Dhcpv4Server.Sockaddr != nil {
+			if ip := aa.Dhcpv4Server.IP(); ip != nil {
+				adapter.DHCPServer = ip.String()
+			}
+		}
+		adapter.IPv6 = adapterIPv6(aa)
+		adapters = append(adapters, adapter)
+	}
+	return adapters, nil
+}
+
+// adapterIPv6 读取网卡的IPv6地址、网关和DNS, 跳过链路本地地址
+func adapterIPv6(aa *windows.IpAdapterAddresses) IPv6Config {
+	var config IPv6Config
+	for ua := aa.FirstUnicastAddress; ua != nil; ua = ua.Next {
+		ip := ua.Address.IP()
+		if ip == nil || ip.To4() != nil || ip.IsLinkLocalUnicast() {
+			continue
+		}
+		config.Addresses = append(config.Addresses, IPv6Address{
+			IP:        ip.String(),
+			PrefixLen: int(ua.OnLinkPrefixLength),
+			Manual:    ua.PrefixOrigin == windows.IpPrefixOriginManual,
+		})
+	}
+	for gw := aa.FirstGatewayAddress; gw != nil; gw = gw.Next {
+		if ip := gw.Address.IP(); ip != nil && ip.To4() == nil {
+			config.Gateways = append(config.Gateways, ip.String())
+		}
+	}
+	for dns := aa.FirstDnsServerAddress; dns != nil; dns = dns.Next {
+		// 跳过未配置DNS时系统自动填入的站点本地地址 fec0:0:0:ffff::1
+		if ip := dns.Address.IP(); ip != nil && ip.To4() == nil && !strings.HasPrefix(ip.String(), "fec0:") {
+			config.DNSServers = append(config.DNSServers, ip.String())
+		}
+	}
+	return config
+}
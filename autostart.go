@@ -3,25 +3,15 @@ package main
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
-	"syscall"
 )
 
-// hideCmdWindow 隐藏命令行窗口
-func hideCmdWindow(cmd *exec.Cmd) {
-	if runtime.GOOS == "windows" {
-		cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	}
-}
+// autoStartName 开机启动任务/启动项名称
+const autoStartName = "RouterSwitcher"
 
 // EnableAutoStart 启用开机启动
-func EnableAutoStart() error {
-	if runtime.GOOS != "windows" {
-		return fmt.Errorf("当前仅支持Windows系统")
-	}
-
+func EnableAutoStart(runner CommandRunner) error {
 	// 获取可执行文件路径
 	exePath, err := os.Executable()
 	if err != nil {
@@ -34,29 +24,64 @@ func EnableAutoStart() error {
 		return fmt.Errorf("获取绝对路径失败: %v", err)
 	}
 
-	// 使用schtasks命令创建开机启动任务
-	// 任务名称
-	taskName := "RouterSwitcher"
+	switch runtime.GOOS {
+	case "windows":
+		return enableAutoStartWindows(runner, exePath)
+	case "linux":
+		return enableAutoStartLinux(exePath)
+	default:
+		return fmt.Errorf("当前仅支持Windows和Linux系统")
+	}
+}
+
+// DisableAutoStart 禁用开机启动
+func DisableAutoStart(runner CommandRunner) error {
+	switch runtime.GOOS {
+	case "windows":
+		return disableAutoStartWindows(runner)
+	case "linux":
+		return disableAutoStartLinux()
+	default:
+		return fmt.Errorf("当前仅支持Windows和Linux系统")
+	}
+}
+
+// IsAutoStartEnabled 检查是否已启用开机启动
+func IsAutoStartEnabled(runner CommandRunner) bool {
+	switch runtime.GOOS {
+	case "windows":
+		// 查询任务是否存在
+		_, err := runCmd(runner, "schtasks", "/Query", "/TN", autoStartName)
+		return err == nil
+	case "linux":
+		desktopPath, err := linuxAutoStartPath()
+		if err != nil {
+			return false
+		}
+		_, err = os.Stat(desktopPath)
+		return err == nil
+	default:
+		return false
+	}
+}
 
+// enableAutoStartWindows 使用schtasks命令创建开机启动任务
+func enableAutoStartWindows(runner CommandRunner, exePath string) error {
 	// 删除可能存在的旧任务
-	cmd := exec.Command("schtasks", "/Delete", "/TN", taskName, "/F")
-	hideCmdWindow(cmd)
-	err = cmd.Run()
-	if err != nil && err.Error() != "exit status 1" {
+	result, err := runCmd(runner, "schtasks", "/Delete", "/TN", autoStartName, "/F")
+	if err != nil && result.ExitCode != 1 {
 		// exit status 1 通常表示任务不存在，这在删除时是正常的
 		return fmt.Errorf("删除旧任务失败: %v", err)
 	}
 
 	// 创建新任务
-	cmd = exec.Command("schtasks", "/Create",
-		"/TN", taskName,
+	_, err = runCmd(runner, "schtasks", "/Create",
+		"/TN", autoStartName,
 		"/TR", fmt.Sprintf(`"%s"`, exePath),
 		"/SC", "ONLOGON",
 		"/RL", "HIGHEST",
 		"/F",
 	)
-	hideCmdWindow(cmd)
-	err = cmd.Run()
 	if err != nil {
 		return fmt.Errorf("创建开机启动任务失败: %v", err)
 	}
@@ -64,19 +89,10 @@ func EnableAutoStart() error {
 	return nil
 }
 
-// DisableAutoStart 禁用开机启动
-func DisableAutoStart() error {
-	if runtime.GOOS != "windows" {
-		return fmt.Errorf("当前仅支持Windows系统")
-	}
-
-	// 任务名称
-	taskName := "RouterSwitcher"
-
+// disableAutoStartWindows 删除开机启动任务
+func disableAutoStartWindows(runner CommandRunner) error {
 	// 删除任务
-	cmd := exec.Command("schtasks", "/Delete", "/TN", taskName, "/F")
-	hideCmdWindow(cmd)
-	err := cmd.Run()
+	_, err := runCmd(runner, "schtasks", "/Delete", "/TN", autoStartName, "/F")
 	if err != nil {
 		// 如果任务不存在(schtasks返回0x80)，不算错误
 		return nil
@@ -85,18 +101,51 @@ func DisableAutoStart() error {
 	return nil
 }
 
-// IsAutoStartEnabled 检查是否已启用开机启动
-func IsAutoStartEnabled() bool {
-	if runtime.GOOS != "windows" {
-		return false
+// linuxAutoStartPath 返回 XDG 自启动目录下的 desktop 文件路径
+func linuxAutoStartPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("获取配置目录失败: %v", err)
+	}
+	return filepath.Join(configDir, "autostart", autoStartName+".desktop"), nil
+}
+
+// enableAutoStartLinux 在 ~/.config/autostart 下创建 desktop 启动项
+func enableAutoStartLinux(exePath string) error {
+	desktopPath, err := linuxAutoStartPath()
+	if err != nil {
+		return err
 	}
 
-	// 任务名称
-	taskName := "RouterSwitcher"
+	if err := os.MkdirAll(filepath.Dir(desktopPath), 0755); err != nil {
+		return fmt.Errorf("创建自启动目录失败: %v", err)
+	}
 
-	// 查询任务是否存在
-	cmd := exec.Command("schtasks", "/Query", "/TN", taskName)
-	hideCmdWindow(cmd)
-	err := cmd.Run()
-	return err == nil
-}
\ No newline at end of file
+	content := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=%s
+Exec="%s"
+Terminal=false
+X-GNOME-Autostart-enabled=true
+`, autoStartName, exePath)
+	if err := os.WriteFile(desktopPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("创建开机启动项失败: %v", err)
+	}
+
+	return nil
+}
+
+// disableAutoStartLinux 删除 desktop 启动项
+func disableAutoStartLinux() error {
+	desktopPath, err := linuxAutoStartPath()
+	if err != nil {
+		return err
+	}
+
+	// 启动项不存在不算错误
+	if err := os.Remove(desktopPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除开机启动项失败: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// AppliedConfig FakeBackend 记录的一次配置变更
+type AppliedConfig struct {
+	Interface  string   // 网络接口名称
+	Mode       string   // dhcp, static, dns, override, override-clear, metric, metric-clear, ipv6-static, ipv6-disable 或 ipv6-reset
+	IP         string   // 静态IP地址
+	SubnetMask string   // 子网掩码
+	Gateway    string   // 网关地址
+	DNS        []string // DNS服务器地址, 按优先顺序
+}
+
+// FakeBackend 内存中的网络后端，不接触真实网卡
+// 用于在 Linux CI 等环境下测试 checkAndSwitch 和托盘逻辑
+type FakeBackend struct {
+	mu sync.Mutex
+
+	Interface  string            // 活动网络接口名称, 为空时表示没有活动接口
+	IfType     string            // 活动网络接口的网卡类型: wifi 或 ethernet
+	MAC        string            // 活动网络接口的MAC地址
+	WiFiName   string            // 当前WiFi名称, 为空时表示未连接WiFi
+	WiFiErr    error             // GetCurrentWiFiName 返回的错误, 例如 ErrLocationPermission
+	FactsErr   error             // GetNetworkFacts 返回的错误, 模拟网络命令执行失败
+	BSSID      string            // 当前接入点MAC地址
+	DHCP       bool              // 当前是否为DHCP模式
+	IP         string            // 当前IP地址
+	SubnetMask string            // 当前子网掩码
+	Gateway    string            // 当前网关
+	Metric     int               // 当前网关的默认路由跃点数
+	DNS        []string          // 当前DNS, 按优先顺序
+	StaticDNS  bool              // 当前DNS是否为手动设置
+	Lease      IPv4Config        // DHCP下发的IPv4配置, 切换到DHCP时恢复
+	GatewayMAC string            // 当前网关的MAC地址
+	DHCPServer string            // DHCP服务器地址, 静态IP时为空
+	DNSSuffix  string            // 连接的DNS后缀, 静态IP时为空
+	Reachable  map[string]bool   // Ping 的结果, 未列出的地址视为不可达
+	StaticDown map[string]bool   // 使用静态IP时 Ping 不通的地址, 模拟旁路由不接受该静态IP
+	IPv6       IPv6Config        // 当前IPv6配置
+	AutoIPv6   IPv6Config        // 路由通告和DHCPv6下发的IPv6配置, ResetIPv6 时恢复
+	IPv6Err    error             // SetStaticIPv6 和 DisableIPv6Auto 返回的错误, 模拟设置IPv6失败
+	Routes     map[string]string // AddRoute 添加的临时路由, 键为目标, 值为网关
+
+	Applied []AppliedConfig // 已应用的配置, 按时间顺序
+}
+
+// NewFakeBackend 创建内存网络后端，初始为DHCP模式, 使用 192.168.1.0/24 网段的DHCP租约
+func NewFakeBackend(iface string) *FakeBackend {
+	b := &FakeBackend{
+		Interface: iface,
+		IfType:    InterfaceWiFi,
+		Lease: IPv4Config{
+			DHCP:       true,
+			IP:         "192.168.1.100",
+			SubnetMask: "255.255.255.0",
+			Gateway:    "192.168.1.1",
+			DNS:        []string{"192.168.1.1"},
+		},
+		Metric:    100,
+		Reachable: make(map[string]bool),
+		Routes:    make(map[string]string),
+	}
+	b.applyLeaseLocked()
+	return b
+}
+
+// applyLeaseLocked 使用DHCP租约中的地址、网关和DNS, 调用前需持有锁
+func (b *FakeBackend) applyLeaseLocked() {
+	b.DHCP, b.StaticDNS = true, false
+	b.IP, b.SubnetMask, b.Gateway = b.Lease.IP, b.Lease.SubnetMask, b.Lease.Gateway
+	b.DNS = append([]string(nil), b.Lease.DNS...)
+}
+
+// ListInterfaces 列出所有无线和有线网卡
+func (b *FakeBackend) ListInterfaces() ([]NetworkInterface, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.Interface == "" {
+		return nil, nil
+	}
+	return []NetworkInterface{{Name: b.Interface, Index: 1, Type: b.IfType, MAC: b.MAC, Connected: true}}, nil
+}
+
+// GetActiveInterface 获取活动网络接口名称
+func (b *FakeBackend) GetActiveInterface(types string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.Interface == "" || !matchesInterfaceTypes(b.IfType, types) {
+		return "", fmt.Errorf("未找到活动网络接口")
+	}
+	return b.Interface, nil
+}
+
+// GetCurrentIPConfig 检查当前网络接口是否为DHCP模式
+func (b *FakeBackend) GetCurrentIPConfig(iface string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.DHCP, nil
+}
+
+// GetCurrentStaticIPConfig 检查当前网络接口是否已经是目标静态IP配置
+func (b *FakeBackend) GetCurrentStaticIPConfig(iface, staticIP, subnetMask, gateway string, dns []string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return !b.DHCP && b.IP == staticIP && b.SubnetMask == subnetMask && b.Gateway == gateway && equalStrings(b.DNS, dns), nil
+}
+
+// SetDHCP 设置网络接口为DHCP模式
+func (b *FakeBackend) SetDHCP(iface string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.applyLeaseLocked()
+	b.Applied = append(b.Applied, AppliedConfig{Interface: iface, Mode: "dhcp"})
+	return nil
+}
+
+// SetStaticIP 设置网络接口为静态IP模式
+func (b *FakeBackend) SetStaticIP(iface, ip, subnetMask, gateway string, dns []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.DHCP, b.StaticDNS = false, true
+	b.IP, b.SubnetMask, b.Gateway, b.DNS = ip, subnetMask, gateway, dns
+	b.Applied = append(b.Applied, AppliedConfig{
+		Interface:  iface,
+		Mode:       "static",
+		IP:         ip,
+		SubnetMask: subnetMask,
+		Gateway:    gateway,
+		DNS:        dns,
+	})
+	return nil
+}
+
+// SetDNS 设置静态DNS, 不改动地址
+func (b *FakeBackend) SetDNS(iface string, dns []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.DNS, b.StaticDNS = append([]string(nil), dns...), true
+	b.Applied = append(b.Applied, AppliedConfig{Interface: iface, Mode: "dns", DNS: b.DNS})
+	return nil
+}
+
+// GetIPv4Config 获取网络接口当前的IPv4地址、子网掩码、网关和DNS
+func (b *FakeBackend) GetIPv4Config(iface string) (*IPv4Config, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return &IPv4Config{
+		DHCP:       b.DHCP,
+		IP:         b.IP,
+		SubnetMask: b.SubnetMask,
+		Gateway:    b.Gateway,
+		DNS:        append([]string(nil), b.DNS...),
+		StaticDNS:  b.StaticDNS,
+	}, nil
+}
+
+// GetIPv6Config 获取网络接口当前的IPv6地址、网关和DNS
+func (b *FakeBackend) GetIPv6Config(iface string) (*IPv6Config, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	config := b.IPv6
+	return &config, nil
+}
+
+// SetStaticIPv6 设置静态IPv6地址、网关和DNS
+func (b *FakeBackend) SetStaticIPv6(iface, address, gateway string, dns []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.IPv6Err != nil {
+		return b.IPv6Err
+	}
+	ip, subnet, err := net.ParseCIDR(address)
+	if err != nil {
+		return fmt.Errorf("设置静态IPv6失败: %v", err)
+	}
+	prefix, _ := subnet.Mask.Size()
+	b.IPv6 = IPv6Config{
+		Addresses:  []IPv6Address{{IP: ip.String(), PrefixLen: prefix, Manual: true}},
+		Gateways:   []string{gateway},
+		DNSServers: dns,
+	}
+	b.Applied = append(b.Applied, AppliedConfig{Interface: iface, Mode: "ipv6-static", IP: address, Gateway: gateway, DNS: dns})
+	return nil
+}
+
+// DisableIPv6Auto 停止接受路由通告和DHCPv6, 清除IPv6默认网关和DNS
+func (b *FakeBackend) DisableIPv6Auto(iface string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.IPv6Err != nil {
+		return b.IPv6Err
+	}
+	b.IPv6 = IPv6Config{}
+	b.Applied = append(b.Applied, AppliedConfig{Interface: iface, Mode: "ipv6-disable"})
+	return nil
+}
+
+// ResetIPv6 恢复自动获取IPv6配置
+func (b *FakeBackend) ResetIPv6(iface string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.IPv6 = b.AutoIPv6
+	b.Applied = append(b.Applied, AppliedConfig{Interface: iface, Mode: "ipv6-reset"})
+	return nil
+}
+
+// AddRoute 记录一条临时路由
+func (b *FakeBackend) AddRoute(iface, destination, gateway string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.Routes[destination]; ok {
+		return fmt.Errorf("添加路由失败: %s 已存在", destination)
+	}
+	b.Routes[destination] = gateway
+	return nil
+}
+
+// DeleteRoute 删除 AddRoute 记录的路由
+func (b *FakeBackend) DeleteRoute(iface, destination, gateway string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.Routes[destination] != gateway {
+		return fmt.Errorf("删除路由失败: %s 不存在", destination)
+	}
+	delete(b.Routes, destination)
+	return nil
+}
+
+// ListRoutes 返回DHCP网关的默认路由和 Routes 中记录的路由
+// DHCP默认路由的跃点数为 Metric, 记录的路由跃点数为0
+func (b *FakeBackend) ListRoutes(iface string) ([]Route, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var routes []Route
+	if b.Gateway != "" {
+		routes = append(routes, Route{Destination: "0.0.0.0/0", Gateway: b.Gateway, Metric: b.Metric})
+	}
+	for destination, gateway := range b.Routes {
+		routes = append(routes, Route{Destination: destination, Gateway: gateway})
+	}
+	return routes, nil
+}
+
+// SetPreferredGateway 切换到DHCP地址并记录经过 gateway 的默认路由和静态DNS
+// 与真实后端一样, DHCP默认路由的跃点数为0时改为1
+func (b *FakeBackend) SetPreferredGateway(iface, gateway string, dns []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.DHCP {
+		b.applyLeaseLocked()
+	}
+	if b.Metric == 0 {
+		b.Metric = 1
+	}
+	b.Routes["0.0.0.0/0"] = gateway
+	b.DNS, b.StaticDNS = append([]string(nil), dns...), true
+	b.Applied = append(b.Applied, AppliedConfig{Interface: iface, Mode: "metric", Gateway: gateway, DNS: b.DNS})
+	return nil
+}
+
+// ClearPreferredGateway 删除 SetPreferredGateway 记录的默认路由, DHCP默认路由的跃点数按 defaults 恢复, DNS恢复为DHCP下发
+func (b *FakeBackend) ClearPreferredGateway(iface, gateway string, defaults []Route) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.Routes["0.0.0.0/0"] != gateway {
+		return fmt.Errorf("删除默认路由失败: 经过 %s 的默认路由不存在", gateway)
+	}
+	delete(b.Routes, "0.0.0.0/0")
+	current := []Route{{Destination: "0.0.0.0/0", Gateway: b.Gateway, Metric: b.Metric}}
+	for _, route := range changedDefaultRoutes(current, defaults) {
+		b.Metric = route.Metric
+	}
+	b.DNS, b.StaticDNS = append([]string(nil), b.Lease.DNS...), false
+	b.Applied = append(b.Applied, AppliedConfig{Interface: iface, Mode: "metric-clear", Gateway: gateway})
+	return nil
+}
+
+// GetRouteOverride 获取 Routes 中覆盖默认路由的网关
+func (b *FakeBackend) GetRouteOverride(iface string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	gateway := b.Routes[overrideRoutes[0]]
+	for _, destination := range overrideRoutes[1:] {
+		if b.Routes[destination] != gateway {
+			return "", nil
+		}
+	}
+	return gateway, nil
+}
+
+// SetRouteOverride 切换到DHCP地址并记录覆盖默认路由的路由和静态DNS
+func (b *FakeBackend) SetRouteOverride(iface, gateway string, dns []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.DHCP {
+		b.applyLeaseLocked()
+	}
+	for _, destination := range overrideRoutes {
+		b.Routes[destination] = gateway
+	}
+	b.DNS, b.StaticDNS = append([]string(nil), dns...), true
+	b.Applied = append(b.Applied, AppliedConfig{Interface: iface, Mode: "override", Gateway: gateway, DNS: b.DNS})
+	return nil
+}
+
+// ClearRouteOverride 删除覆盖默认路由的路由, DNS恢复为DHCP下发
+func (b *FakeBackend) ClearRouteOverride(iface string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, destination := range overrideRoutes {
+		delete(b.Routes, destination)
+	}
+	b.DNS, b.StaticDNS = append([]string(nil), b.Lease.DNS...), false
+	b.Applied = append(b.Applied, AppliedConfig{Interface: iface, Mode: "override-clear"})
+	return nil
+}
+
+// GetCurrentWiFiName 获取无线网卡 iface 当前连接的WiFi名称
+func (b *FakeBackend) GetCurrentWiFiName(iface string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.WiFiErr != nil {
+		return "", b.WiFiErr
+	}
+	if b.WiFiName == "" {
+		return "", fmt.Errorf("未找到WiFi信息")
+	}
+	return b.WiFiName, nil
+}
+
+// GetNetworkFacts 获取判断当前所在网络所需的信息
+func (b *FakeBackend) GetNetworkFacts(iface string) (*NetworkFacts, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.FactsErr != nil {
+		return nil, b.FactsErr
+	}
+	facts := &NetworkFacts{
+		Interface:     iface,
+		InterfaceType: b.IfType,
+		IPAddress:     b.IP,
+		DHCP:          b.DHCP,
+		Gateway:       b.Gateway,
+		GatewayMAC:    b.GatewayMAC,
+	}
+	if b.DHCP {
+		facts.DHCPServer = b.DHCPServer
+		facts.DNSSuffix = b.DNSSuffix
+	}
+	if errors.Is(b.WiFiErr, ErrLocationPermission) {
+		return facts, b.WiFiErr
+	}
+	if b.WiFiErr == nil && b.IfType == InterfaceWiFi {
+		facts.SSID, facts.BSSID = b.WiFiName, b.BSSID
+	}
+	return facts, nil
+}
+
+// GetCurrentNetworkStatus 获取网络接口的详细状态
+func (b *FakeBackend) GetCurrentNetworkStatus(iface string) (*NetworkStatus, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := &NetworkStatus{Interface: iface, WiFiName: "未连接"}
+	if iface != b.Interface {
+		return status, fmt.Errorf("网络接口 %s 不存在", iface)
+	}
+	status.InterfaceType = b.IfType
+	if b.IfType == InterfaceWiFi && b.WiFiErr == nil && b.WiFiName != "" {
+		status.WiFiName = b.WiFiName
+		status.WiFiConnected = true
+	}
+
+	status.IPAddress = b.IP
+	status.Gateway = b.Gateway
+	// 已持有锁, 不能调用 Ping
+	status.probe(b.DNS, b.pingLocked)
+	if b.DHCP {
+		status.IPAssignment = "自动(DHCP)"
+		status.DNSAssignment = "自动(DHCP)"
+	} else {
+		status.IPAssignment = "手动"
+		status.DNSAssignment = "手动"
+	}
+	return status, nil
+}
+
+// pingLocked 按 Reachable 返回Ping结果, 调用前需持有锁
+func (b *FakeBackend) pingLocked(hosts ...string) []*PingResult {
+	results := make([]*PingResult, len(hosts))
+	for i, host := range hosts {
+		results[i] = &PingResult{Host: host, Sent: 1, TTL: -1}
+		if b.Reachable[host] {
+			results[i].Received = 1
+			results[i].TTL = 64
+		}
+	}
+	return results
+}
+
+// Ping 测试网络连通性
+func (b *FakeBackend) Ping(host string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.DHCP && b.StaticDown[host] {
+		return false
+	}
+	return b.Reachable[host]
+}
+
+// LastApplied 返回最近一次应用的配置
+func (b *FakeBackend) LastApplied() (AppliedConfig, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.Applied) == 0 {
+		return AppliedConfig{}, false
+	}
+	return b.Applied[len(b.Applied)-1], true
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// codePageUTF8 UTF-8 代码页
+const codePageUTF8 = 65001
+
+// codePageEncodings Windows 代码页到编码的映射
+var codePageEncodings = map[uint32]encoding.Encoding{
+	437:   charmap.CodePage437,
+	850:   charmap.CodePage850,
+	852:   charmap.CodePage852,
+	855:   charmap.CodePage855,
+	858:   charmap.CodePage858,
+	862:   charmap.CodePage862,
+	866:   charmap.CodePage866,
+	874:   charmap.Windows874,
+	932:   japanese.ShiftJIS,
+	936:   simplifiedchinese.GBK,
+	949:   korean.EUCKR,
+	950:   traditionalchinese.Big5,
+	1250:  charmap.Windows1250,
+	1251:  charmap.Windows1251,
+	1252:  charmap.Windows1252,
+	1253:  charmap.Windows1253,
+	1254:  charmap.Windows1254,
+	1255:  charmap.Windows1255,
+	1256:  charmap.Windows1256,
+	1257:  charmap.Windows1257,
+	1258:  charmap.Windows1258,
+	20866: charmap.KOI8R,
+	21866: charmap.KOI8U,
+	28591: charmap.ISO8859_1,
+	54936: simplifiedchinese.GB18030,
+}
+
+// decodeCodePage 将指定代码页的字节转换为UTF-8字符串
+// 始终按报告的代码页解码，GBK 中文恰好也可能是合法的UTF-8字节序列，不能据此跳过解码
+// UTF-8 代码页、未知代码页或解码失败时原样返回
+func decodeCodePage(data []byte, codePage uint32) string {
+	enc, ok := codePageEncodings[codePage]
+	if codePage == codePageUTF8 || !ok {
+		return string(data)
+	}
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return string(data)
+	}
+	return string(decoded)
+}
+
+// decodeConsoleOutput 将控制台程序（如 netsh）的输出按当前控制台代码页转换为UTF-8
+// 中文 Windows 上 netsh 以 OEM 代码页 (CP936/GBK) 输出，直接当作UTF-8会导致中文乱码
+func decodeConsoleOutput(data []byte) string {
+	return decodeCodePage(data, consoleCodePage())
+}
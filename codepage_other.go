@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+// consoleCodePage 非 Windows 系统的命令输出统一为UTF-8
+func consoleCodePage() uint32 {
+	return codePageUTF8
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// encodeGBK 将字符串编码为 GBK, 模拟中文 Windows 上 netsh 的原始输出
+func encodeGBK(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		t.Fatalf("GBK编码失败: %v", err)
+	}
+	return data
+}
+
+func TestDecodeCodePageGBK(t *testing.T) {
+	tests := []string{
+		"以太网 2",
+		"家里的WiFi",
+		"一楼WiFi", // GBK 字节恰好也是合法的UTF-8
+		"Wi-Fi 2",
+	}
+	for _, want := range tests {
+		if got := decodeCodePage(encodeGBK(t, want), 936); got != want {
+			t.Errorf("decodeCodePage(%q, 936) = %q", want, got)
+		}
+	}
+
+	if !utf8.Valid(encodeGBK(t, "一楼WiFi")) {
+		t.Fatal("测试数据应为合法的UTF-8字节序列")
+	}
+}
+
+func TestDecodeCodePagePassthrough(t *testing.T) {
+	data := []byte("以太网 2")
+	if got := decodeCodePage(data, codePageUTF8); got != "以太网 2" {
+		t.Errorf("UTF-8 代码页 = %q", got)
+	}
+	if got := decodeCodePage(data, 12345); got != "以太网 2" {
+		t.Errorf("未知代码页 = %q", got)
+	}
+}
+
+func TestDecodeNetshGBKOutput(t *testing.T) {
+	wlan := encodeGBK(t, "    名称                   : WLAN\r\n    GUID                   : 6f1d3c2a-8b4e-4a51-9c0d-2e7f5a9b1c11\r\n    SSID                   : 一楼WiFi\r\n    BSSID                  : aa:bb:cc:dd:ee:ff\r\n")
+	ssid, bssid := parseNetshWlan(decodeCodePage(wlan, 936), "WLAN")
+	if ssid != "一楼WiFi" || bssid != "aa:bb:cc:dd:ee:ff" {
+		t.Errorf("parseNetshWlan = %q, %q", ssid, bssid)
+	}
+
+	line := encodeGBK(t, "已启用            已连接            专用               以太网 2")
+	state, ifType, name, ok := parseNetshInterfaceLine(decodeCodePage(line, 936))
+	if !ok || state != "已连接" || ifType != "专用" || name != "以太网 2" {
+		t.Errorf("parseNetshInterfaceLine = %q, %q, %q, %v", state, ifType, name, ok)
+	}
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package main
+
+import "syscall"
+
+var (
+	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procGetConsoleOutputCP = kernel32.NewProc("GetConsoleOutputCP")
+	procGetOEMCP           = kernel32.NewProc("GetOEMCP")
+)
+
+// consoleCodePage 获取控制台程序输出使用的代码页
+// GUI 程序本身没有控制台，子进程的控制台默认使用 OEM 代码页
+func consoleCodePage() uint32 {
+	if cp, _, _ := procGetConsoleOutputCP.Call(); cp != 0 {
+		return uint32(cp)
+	}
+	if cp, _, _ := procGetOEMCP.Call(); cp != 0 {
+		return uint32(cp)
+	}
+	return codePageUTF8
+}
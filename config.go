@@ -2,24 +2,32 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
+	"net"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 const (
-	ConfigFileName = "config.json"
+	ConfigFileName    = "config.json"
+	DefaultSubnetMask = "255.255.255.0" // 未设置子网掩码和前缀长度时使用
 )
 
 // LoadConfig 加载配置文件
 func LoadConfig() (*Config, error) {
 	config := &Config{
-		HomeSSID:  "HomeWiFi",
-		StaticIP:  "192.168.31.100",
-		Gateway:   "192.168.31.2",
-		DNS:       "192.168.31.2",
+		Profiles:  defaultProfiles(),
 		AutoStart: false,
 		IPMode:    "adaptive", // 默认为自适应模式
+		Backend:   "auto",
+		Locale:    "auto",
+
+		InterfaceTypes: InterfaceBoth,
+
+		TranscriptMode: "off",
 	}
 
 	// 获取可执行文件所在目录
@@ -63,15 +71,280 @@ func LoadConfig() (*Config, error) {
 		return config, nil // 返回默认配置
 	}
 
-	// 解析配置文件
+	// 解析配置文件, 网络配置列表以文件为准
+	defaults := config.Profiles
+	config.Profiles = nil
 	err = json.Unmarshal(data, config)
 	if err != nil {
+		config.Profiles = defaults
 		return config, nil // 返回默认配置
 	}
 
+	migrateLegacyConfig(config)
+	if len(config.Profiles) == 0 {
+		config.Profiles = defaults
+	}
+	if err := ValidateConfig(config); err != nil {
+		log.Printf("配置文件中存在无效配置: %v", err)
+	}
+
 	return config, nil
 }
 
+// defaultProfiles 默认网络配置
+func defaultProfiles() []Profile {
+	return []Profile{
+		{
+			Name:     "家",
+			SSIDs:    []string{"HomeWiFi"},
+			StaticIP: "192.168.31.100",
+			Gateway:  "192.168.31.2",
+			DNS:      DNSList{"192.168.31.2"},
+		},
+	}
+}
+
+// migrateLegacyConfig 将旧版本的单一 HomeSSID/StaticIP/Gateway/DNS 配置迁移为网络配置
+func migrateLegacyConfig(config *Config) {
+	if config.HomeSSID == "" && config.StaticIP == "" {
+		return
+	}
+
+	if len(config.Profiles) == 0 {
+		profile := Profile{
+			Name:     "家",
+			StaticIP: config.StaticIP,
+			Gateway:  config.Gateway,
+			DNS:      parseDNSList(config.DNS),
+		}
+		if config.HomeSSID != "" {
+			profile.SSIDs = []string{config.HomeSSID}
+		}
+		config.Profiles = []Profile{profile}
+		log.Printf("已将旧版配置迁移为网络配置: %+v\n", profile)
+	}
+
+	config.HomeSSID, config.StaticIP, config.Gateway, config.DNS = "", "", "", ""
+}
+
+// ValidateConfig 检查全部网络配置的网关、DNS、分流目标和健康检查
+// 只有自适应或静态IP模式下修改IP地址的网络配置需要静态IP, 其他情况可以不填
+func ValidateConfig(config *Config) error {
+	if err := config.Switching.validate(); err != nil {
+		return err
+	}
+	if err := config.Monitor.validate(); err != nil {
+		return err
+	}
+	changesAddress := config.changesAddress()
+	for i := range config.Profiles {
+		profile := &config.Profiles[i]
+		if err := profile.validate(changesAddress && !profile.keepsDHCPAddress()); err != nil {
+			return fmt.Errorf("网络配置「%s」: %v", profile.Name, err)
+		}
+	}
+	return nil
+}
+
+// changesAddress 是否有网卡使用会修改IP地址的模式(自适应或静态IP)
+// 设置了网卡列表时按各网卡的模式判断, 否则按全局模式判断
+func (c *Config) changesAddress() bool {
+	modes := []string{c.IPMode}
+	if len(c.Interfaces) > 0 {
+		modes = modes[:0]
+		for _, setting := range c.Interfaces {
+			mode := setting.IPMode
+			if mode == "" {
+				mode = c.IPMode
+			}
+			modes = append(modes, mode)
+		}
+	}
+	for _, mode := range modes {
+		if mode == "adaptive" || mode == "static" {
+			return true
+		}
+	}
+	return false
+}
+
+// validate 检查网络配置, useStaticIP 为 true 时检查静态IP, 网关和备用网关必须与静态IP在同一子网
+func (p *Profile) validate(useStaticIP bool) error {
+	gateway := net.ParseIP(p.Gateway).To4()
+	if gateway == nil {
+		return fmt.Errorf("无效的网关地址: %s", p.Gateway)
+	}
+	if len(p.DNS) == 0 {
+		return fmt.Errorf("DNS不能为空")
+	}
+	for _, server := range p.DNS {
+		if net.ParseIP(server).To4() == nil {
+			return fmt.Errorf("无效的DNS地址: %s", server)
+		}
+	}
+	if p.FallbackGateway != "" {
+		if net.ParseIP(p.FallbackGateway).To4() == nil {
+			return fmt.Errorf("无效的备用网关地址: %s", p.FallbackGateway)
+		}
+		for _, server := range p.FallbackDNS {
+			if net.ParseIP(server).To4() == nil {
+				return fmt.Errorf("无效的备用DNS地址: %s", server)
+			}
+		}
+	}
+	if useStaticIP {
+		if strings.TrimSpace(p.StaticIP) == "" {
+			return fmt.Errorf("静态IP不能为空")
+		}
+		ip, mask, err := p.staticAddress()
+		if err != nil {
+			return err
+		}
+		ipMask := net.IPMask(net.ParseIP(mask).To4())
+		subnet := net.IPNet{IP: net.ParseIP(ip).Mask(ipMask), Mask: ipMask}
+		if !subnet.Contains(gateway) {
+			return fmt.Errorf("网关 %s 不在子网 %s 中", p.Gateway, subnet.String())
+		}
+		if p.FallbackGateway != "" && !subnet.Contains(net.ParseIP(p.FallbackGateway)) {
+			return fmt.Errorf("备用网关 %s 不在子网 %s 中", p.FallbackGateway, subnet.String())
+		}
+	}
+	switch p.Strategy {
+	case "", StrategyAddress, StrategyMetric:
+	default:
+		return fmt.Errorf("未知的切换方式: %s", p.Strategy)
+	}
+	if p.splitRouting() {
+		if _, err := p.splitRoutes(); err != nil {
+			return err
+		}
+	}
+	if err := p.validateIPv6(); err != nil {
+		return err
+	}
+	return p.validateHealth()
+}
+
+// validateHealth 检查网络配置的旁路由健康检查设置
+func (p *Profile) validateHealth() error {
+	switch p.HealthMode {
+	case "", MatchAll, MatchAny, HealthQuorum:
+	default:
+		return fmt.Errorf("未知的健康检查组合方式: %s", p.HealthMode)
+	}
+	if p.HealthQuorum < 0 {
+		return fmt.Errorf("健康检查通过数量不能为负数")
+	}
+	for i, probe := range p.HealthChecks {
+		if err := probe.validate(); err != nil {
+			return fmt.Errorf("第%d项健康检查: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// validateIPv6 检查网络配置的IPv6设置
+func (p *Profile) validateIPv6() error {
+	switch p.IPv6Mode {
+	case IPv6Keep, IPv6Disable:
+		return nil
+	case IPv6Static:
+	default:
+		return fmt.Errorf("未知的IPv6处理方式: %s", p.IPv6Mode)
+	}
+
+	ip, _, err := net.ParseCIDR(p.staticIPv6())
+	if err != nil || ip.To4() != nil {
+		return fmt.Errorf("无效的IPv6地址: %s", p.StaticIPv6)
+	}
+	if !isIPv6(p.GatewayIPv6) {
+		return fmt.Errorf("无效的IPv6网关地址: %s", p.GatewayIPv6)
+	}
+	for _, server := range p.DNSv6 {
+		if !isIPv6(server) {
+			return fmt.Errorf("无效的IPv6 DNS地址: %s", server)
+		}
+	}
+	return nil
+}
+
+// fallbackDNS 改用主路由网关时的DNS, 未设置时使用备用网关
+func (p *Profile) fallbackDNS() []string {
+	if len(p.FallbackDNS) > 0 {
+		return p.FallbackDNS
+	}
+	return []string{p.FallbackGateway}
+}
+
+// staticIPv6 返回带前缀长度的静态IPv6地址, 未写前缀长度时使用 /64
+func (p *Profile) staticIPv6() string {
+	address := strings.TrimSpace(p.StaticIPv6)
+	if address != "" && !strings.Contains(address, "/") {
+		address += "/64"
+	}
+	return address
+}
+
+// staticAddress 解析网络配置的静态IP和子网掩码
+// StaticIP 可以写成 CIDR 形式 (10.0.0.50/16), 也可以在 SubnetMask 中单独填写子网掩码,
+// 两者都填写时必须一致, 都未填写时使用 255.255.255.0
+func (p *Profile) staticAddress() (ip, mask string, err error) {
+	address := strings.TrimSpace(p.StaticIP)
+	mask = strings.TrimSpace(p.SubnetMask)
+
+	if idx := strings.Index(address, "/"); idx != -1 {
+		prefix, convErr := strconv.Atoi(address[idx+1:])
+		if convErr != nil || prefix < 1 || prefix > 32 {
+			return "", "", fmt.Errorf("无效的前缀长度: %s", address)
+		}
+		cidrMask := net.IP(net.CIDRMask(prefix, 32)).String()
+		if mask != "" && mask != cidrMask {
+			return "", "", fmt.Errorf("子网掩码 %s 与前缀长度 /%d 不一致", mask, prefix)
+		}
+		address, mask = address[:idx], cidrMask
+	}
+
+	if net.ParseIP(address).To4() == nil {
+		return "", "", fmt.Errorf("无效的IP地址: %s", p.StaticIP)
+	}
+	if mask == "" {
+		mask = DefaultSubnetMask
+	}
+	if prefix, err := maskToPrefix(mask); err != nil {
+		return "", "", err
+	} else if prefix == 0 {
+		return "", "", fmt.Errorf("无效的子网掩码: %s", mask)
+	}
+	return address, mask, nil
+}
+
+// UnmarshalJSON 解析DNS列表, 兼容 "192.168.31.2, 223.5.5.5" 形式的字符串
+func (l *DNSList) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*l = parseDNSList(text)
+		return nil
+	}
+
+	var servers []string
+	if err := json.Unmarshal(data, &servers); err != nil {
+		return err
+	}
+	*l = servers
+	return nil
+}
+
+// parseDNSList 解析逗号分隔的DNS服务器地址
+func parseDNSList(text string) DNSList {
+	var servers DNSList
+	for _, server := range strings.Split(text, ",") {
+		if server = strings.TrimSpace(server); server != "" {
+			servers = append(servers, server)
+		}
+	}
+	return servers
+}
+
 // SaveConfig 保存配置到文件
 func SaveConfig(config *Config) error {
 	// 获取可执行文件所在目录
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateConfig(t *testing.T) {
+	base := Profile{Name: "家", StaticIP: "192.168.1.50", Gateway: "192.168.1.2", DNS: DNSList{"192.168.1.2"}}
+	metric := Profile{Name: "家", Strategy: StrategyMetric, Gateway: "192.168.1.2", DNS: DNSList{"192.168.1.2"}}
+	split := Profile{Name: "家", Gateway: "192.168.1.2", DNS: DNSList{"192.168.1.2"}, SplitRoutes: []string{"10.0.0.0/8"}}
+
+	tests := []struct {
+		name    string
+		mode    string
+		profile func(p *Profile)
+		base    Profile
+		wantErr string
+	}{
+		{"静态IP", "adaptive", nil, base, ""},
+		{"修改IP地址需要静态IP", "adaptive", func(p *Profile) { p.StaticIP = "" }, base, "静态IP不能为空"},
+		{"静态IP模式需要静态IP", "static", func(p *Profile) { p.StaticIP = "" }, base, "静态IP不能为空"},
+		{"动态IP+旁路由不需要静态IP", "override", func(p *Profile) { p.StaticIP = "" }, base, ""},
+		{"动态IP不需要静态IP", "dynamic", func(p *Profile) { p.StaticIP = "" }, base, ""},
+		{"网关不在子网中", "adaptive", func(p *Profile) { p.Gateway = "192.168.2.1" }, base, "不在子网"},
+		{"备用网关不在子网中", "adaptive", func(p *Profile) { p.FallbackGateway = "10.0.0.1" }, base, "备用网关"},
+		{"按跃点数切换不需要静态IP", "adaptive", nil, metric, ""},
+		{"分流不需要静态IP", "adaptive", nil, split, ""},
+
+		// 不使用静态IP时仍然检查网关、DNS和分流目标
+		{"没有静态IP时检查网关", "override", func(p *Profile) { p.StaticIP, p.Gateway = "", "" }, base, "无效的网关地址"},
+		{"没有静态IP时检查DNS", "dynamic", func(p *Profile) { p.StaticIP, p.DNS = "", nil }, base, "DNS不能为空"},
+		{"按跃点数切换检查DNS", "adaptive", func(p *Profile) { p.DNS = DNSList{"bad"} }, metric, "无效的DNS地址"},
+		{"分流检查网关", "adaptive", func(p *Profile) { p.Gateway = "" }, split, "无效的网关地址"},
+		{"分流目标不能是默认路由", "adaptive", func(p *Profile) { p.SplitRoutes = []string{"0.0.0.0/0"} }, split, "默认路由"},
+		{"没有静态IP时检查分流目标", "dynamic", func(p *Profile) { p.SplitRoutes = []string{"10.0.0.0/33"} }, split, "无效的分流目标"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := tt.base
+			if tt.profile != nil {
+				tt.profile(&profile)
+			}
+			err := ValidateConfig(&Config{IPMode: tt.mode, Profiles: []Profile{profile}})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateConfig: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateConfig = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateConfigInterfaceModes(t *testing.T) {
+	profile := Profile{Name: "家", Gateway: "192.168.1.2", DNS: DNSList{"192.168.1.2"}}
+
+	// 网卡都使用动态IP+旁路由时不需要静态IP, 全局模式不起作用
+	config := &Config{IPMode: "adaptive", Profiles: []Profile{profile}, Interfaces: []InterfaceSetting{{Name: "WLAN", IPMode: "override"}}}
+	if err := ValidateConfig(config); err != nil {
+		t.Errorf("ValidateConfig: %v", err)
+	}
+
+	// 没有设置模式的网卡使用全局模式
+	config.Interfaces = append(config.Interfaces, InterfaceSetting{Name: "以太网 2"})
+	if err := ValidateConfig(config); err == nil {
+		t.Error("使用全局自适应模式的网卡需要静态IP")
+	}
+}
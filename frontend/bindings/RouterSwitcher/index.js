@@ -9,5 +9,28 @@ export {
 
 export {
     Config,
-    NetworkStatus
+    DNSServerStatus,
+    HealthProbe,
+    HealthResult,
+    InterfaceSetting,
+    MatchDecision,
+    MatchRule,
+    MonitorPolicy,
+    NetworkFacts,
+    NetworkInterface,
+    NetworkStatus,
+    ProbeResult,
+    Profile,
+    ProfileTrace,
+    RuleResult,
+    SwitchPolicy,
+    SwitchResult
 } from "./models.js";
+
+import * as $models from "./models.js";
+
+/**
+ * DNSList 按优先顺序排列的DNS服务器, 第一个为首选DNS
+ * 兼容旧版本配置文件中逗号分隔的字符串写法
+ * @typedef {$models.DNSList} DNSList
+ */
@@ -15,25 +15,1120 @@ export class Config {
      * @param {Partial<Config>} [$$source = {}] - The source object to create the Config.
      */
     constructor($$source = {}) {
-        if (!("HomeSSID" in $$source)) {
+        if (!("Profiles" in $$source)) {
             /**
+             * 网络配置列表, 自适应模式下按顺序匹配当前网络
+             * @member
+             * @type {Profile[]}
+             */
+            this["Profiles"] = [];
+        }
+        if (!("AutoStart" in $$source)) {
+            /**
+             * 是否开机自启
+             * @member
+             * @type {boolean}
+             */
+            this["AutoStart"] = false;
+        }
+        if (!("IPMode" in $$source)) {
+            /**
+             * IP模式: adaptive(自适应), dynamic(动态IP), static(静态IP), override(动态IP+旁路由)
+             * @member
+             * @type {string}
+             */
+            this["IPMode"] = "";
+        }
+        if (!("Backend" in $$source)) {
+            /**
+             * 网络后端: auto(按系统自动选择), netsh, nmcli, networkd
+             * @member
+             * @type {string}
+             */
+            this["Backend"] = "";
+        }
+        if (!("Locale" in $$source)) {
+            /**
+             * netsh 输出语言: auto(自动识别), zh-CN, zh-TW, en, ja, de, fr
+             * @member
+             * @type {string}
+             */
+            this["Locale"] = "";
+        }
+        if (!("InterfaceTypes" in $$source)) {
+            /**
+             * 管理的网卡类型: wifi(无线), ethernet(有线), both(全部)
+             * @member
+             * @type {string}
+             */
+            this["InterfaceTypes"] = "";
+        }
+        if (!("Interfaces" in $$source)) {
+            /**
+             * 单独管理的网卡, 为空时管理 InterfaceTypes 类型的活动网卡
+             * @member
+             * @type {InterfaceSetting[]}
+             */
+            this["Interfaces"] = [];
+        }
+        if (!("Switching" in $$source)) {
+            /**
+             * 自适应模式的防抖和熔断设置
+             * @member
+             * @type {SwitchPolicy}
+             */
+            this["Switching"] = (new SwitchPolicy());
+        }
+        if (!("Monitor" in $$source)) {
+            /**
+             * 自适应模式的检查时间设置
+             * @member
+             * @type {MonitorPolicy}
+             */
+            this["Monitor"] = (new MonitorPolicy());
+        }
+        if (!("TranscriptMode" in $$source)) {
+            /**
+             * 命令记录模式: off(关闭), record(记录), replay(回放)
+             * @member
+             * @type {string}
+             */
+            this["TranscriptMode"] = "";
+        }
+        if (!("TranscriptFile" in $$source)) {
+            /**
+             * 命令记录文件路径
+             * @member
+             * @type {string}
+             */
+            this["TranscriptFile"] = "";
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * 以下为旧版本的单一网络配置，加载时自动迁移到 Profiles
              * 家庭WiFi的SSID
              * @member
+             * @type {string | undefined}
+             */
+            this["HomeSSID"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * 静态IP地址
+             * @member
+             * @type {string | undefined}
+             */
+            this["StaticIP"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * 网关地址
+             * @member
+             * @type {string | undefined}
+             */
+            this["Gateway"] = undefined;
+        }
+        if (/** @type {any} */(false)) {
+            /**
+             * DNS服务器地址
+             * @member
+             * @type {string | undefined}
+             */
+            this["DNS"] = undefined;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new Config instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {Config}
+     */
+    static createFrom($$source = {}) {
+        const $$createField0_0 = $$createType1;
+        const $$createField6_0 = $$createType3;
+        const $$createField7_0 = $$createType4;
+        const $$createField8_0 = $$createType5;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("Profiles" in $$parsedSource) {
+            $$parsedSource["Profiles"] = $$createField0_0($$parsedSource["Profiles"]);
+        }
+        if ("Interfaces" in $$parsedSource) {
+            $$parsedSource["Interfaces"] = $$createField6_0($$parsedSource["Interfaces"]);
+        }
+        if ("Switching" in $$parsedSource) {
+            $$parsedSource["Switching"] = $$createField7_0($$parsedSource["Switching"]);
+        }
+        if ("Monitor" in $$parsedSource) {
+            $$parsedSource["Monitor"] = $$createField8_0($$parsedSource["Monitor"]);
+        }
+        return new Config(/** @type {Partial<Config>} */($$parsedSource));
+    }
+}
+
+/**
+ * DNSList 按优先顺序排列的DNS服务器, 第一个为首选DNS
+ * 兼容旧版本配置文件中逗号分隔的字符串写法
+ * @typedef {string[]} DNSList
+ */
+
+/**
+ * DNSServerStatus 一个DNS服务器的连通性
+ */
+export class DNSServerStatus {
+    /**
+     * Creates a new DNSServerStatus instance.
+     * @param {Partial<DNSServerStatus>} [$$source = {}] - The source object to create the DNSServerStatus.
+     */
+    constructor($$source = {}) {
+        if (!("Address" in $$source)) {
+            /**
+             * DNS服务器地址
+             * @member
+             * @type {string}
+             */
+            this["Address"] = "";
+        }
+        if (!("Reachable" in $$source)) {
+            /**
+             * 是否可达
+             * @member
+             * @type {boolean}
+             */
+            this["Reachable"] = false;
+        }
+        if (!("RTT" in $$source)) {
+            /**
+             * 平均往返时间(毫秒)
+             * @member
+             * @type {number}
+             */
+            this["RTT"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new DNSServerStatus instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {DNSServerStatus}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new DNSServerStatus(/** @type {Partial<DNSServerStatus>} */($$parsedSource));
+    }
+}
+
+/**
+ * HealthProbe 网络配置的一项旁路由健康检查
+ */
+export class HealthProbe {
+    /**
+     * Creates a new HealthProbe instance.
+     * @param {Partial<HealthProbe>} [$$source = {}] - The source object to create the HealthProbe.
+     */
+    constructor($$source = {}) {
+        if (!("Type" in $$source)) {
+            /**
+             * 检查类型: icmp, tcp, http, dns, upstream
+             * @member
+             * @type {string}
+             */
+            this["Type"] = "";
+        }
+        if (!("Target" in $$source)) {
+            /**
+             * icmp 为地址, tcp 为 地址:端口 或端口, http 为URL, dns 为查询的域名, upstream 为 域名:端口; icmp 和 tcp 未写地址时使用网关
+             * @member
+             * @type {string}
+             */
+            this["Target"] = "";
+        }
+        if (!("Server" in $$source)) {
+            /**
+             * dns 和 upstream 检查使用的DNS服务器, 为空时使用网关
+             * @member
+             * @type {string}
+             */
+            this["Server"] = "";
+        }
+        if (!("ExpectStatus" in $$source)) {
+            /**
+             * http 期望的状态码, 为0时接受 2xx 和 3xx
+             * @member
+             * @type {number}
+             */
+            this["ExpectStatus"] = 0;
+        }
+        if (!("ExpectBody" in $$source)) {
+            /**
+             * http 响应中必须包含的内容, 为空时不检查
+             * @member
+             * @type {string}
+             */
+            this["ExpectBody"] = "";
+        }
+        if (!("Timeout" in $$source)) {
+            /**
+             * 超时时间(毫秒), 为0时为3000
+             * @member
+             * @type {number}
+             */
+            this["Timeout"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new HealthProbe instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {HealthProbe}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new HealthProbe(/** @type {Partial<HealthProbe>} */($$parsedSource));
+    }
+}
+
+/**
+ * HealthResult 旁路由健康检查的结果
+ */
+export class HealthResult {
+    /**
+     * Creates a new HealthResult instance.
+     * @param {Partial<HealthResult>} [$$source = {}] - The source object to create the HealthResult.
+     */
+    constructor($$source = {}) {
+        if (!("Mode" in $$source)) {
+            /**
+             * 组合方式: all, any, quorum
+             * @member
+             * @type {string}
+             */
+            this["Mode"] = "";
+        }
+        if (!("Required" in $$source)) {
+            /**
+             * 判定为健康至少需要通过的检查数量
+             * @member
+             * @type {number}
+             */
+            this["Required"] = 0;
+        }
+        if (!("Passed" in $$source)) {
+            /**
+             * 通过的检查数量
+             * @member
+             * @type {number}
+             */
+            this["Passed"] = 0;
+        }
+        if (!("Healthy" in $$source)) {
+            /**
+             * 旁路由是否健康
+             * @member
+             * @type {boolean}
+             */
+            this["Healthy"] = false;
+        }
+        if (!("Results" in $$source)) {
+            /**
+             * 各项检查的结果
+             * @member
+             * @type {ProbeResult[]}
+             */
+            this["Results"] = [];
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new HealthResult instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {HealthResult}
+     */
+    static createFrom($$source = {}) {
+        const $$createField4_0 = $$createType7;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("Results" in $$parsedSource) {
+            $$parsedSource["Results"] = $$createField4_0($$parsedSource["Results"]);
+        }
+        return new HealthResult(/** @type {Partial<HealthResult>} */($$parsedSource));
+    }
+}
+
+/**
+ * InterfaceSetting 单独管理的网卡, 按名称或MAC地址固定
+ */
+export class InterfaceSetting {
+    /**
+     * Creates a new InterfaceSetting instance.
+     * @param {Partial<InterfaceSetting>} [$$source = {}] - The source object to create the InterfaceSetting.
+     */
+    constructor($$source = {}) {
+        if (!("Name" in $$source)) {
+            /**
+             * 网卡名称, 如 "WLAN"、"以太网 2"
+             * @member
+             * @type {string}
+             */
+            this["Name"] = "";
+        }
+        if (!("MAC" in $$source)) {
+            /**
+             * 网卡MAC地址, 设置后按MAC地址查找, 网卡改名后仍然有效
+             * @member
+             * @type {string}
+             */
+            this["MAC"] = "";
+        }
+        if (!("IPMode" in $$source)) {
+            /**
+             * 该网卡的IP模式, 为空时使用全局 IPMode
+             * @member
+             * @type {string}
+             */
+            this["IPMode"] = "";
+        }
+        if (!("Profile" in $$source)) {
+            /**
+             * 该网卡使用的网络配置名称, 为空时按规则匹配全部网络配置
+             * @member
+             * @type {string}
+             */
+            this["Profile"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new InterfaceSetting instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {InterfaceSetting}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new InterfaceSetting(/** @type {Partial<InterfaceSetting>} */($$parsedSource));
+    }
+}
+
+/**
+ * MatchDecision 自适应模式的一次判断，返回给前端用于解释为什么切换或不切换
+ */
+export class MatchDecision {
+    /**
+     * Creates a new MatchDecision instance.
+     * @param {Partial<MatchDecision>} [$$source = {}] - The source object to create the MatchDecision.
+     */
+    constructor($$source = {}) {
+        if (!("Time" in $$source)) {
+            /**
+             * 判断时间
+             * @member
+             * @type {string}
+             */
+            this["Time"] = "";
+        }
+        if (!("Interface" in $$source)) {
+            /**
+             * 网卡名称
+             * @member
+             * @type {string}
+             */
+            this["Interface"] = "";
+        }
+        if (!("Facts" in $$source)) {
+            /**
+             * 当前网络信息
+             * @member
+             * @type {NetworkFacts | null}
+             */
+            this["Facts"] = null;
+        }
+        if (!("Traces" in $$source)) {
+            /**
+             * 各网络配置的匹配过程
+             * @member
+             * @type {ProfileTrace[]}
+             */
+            this["Traces"] = [];
+        }
+        if (!("Profile" in $$source)) {
+            /**
+             * 匹配到的网络配置, 没有匹配时为空
+             * @member
+             * @type {string}
+             */
+            this["Profile"] = "";
+        }
+        if (!("SideRouterChecked" in $$source)) {
+            /**
+             * 是否检测了旁路由
+             * @member
+             * @type {boolean}
+             */
+            this["SideRouterChecked"] = false;
+        }
+        if (!("SideRouterOK" in $$source)) {
+            /**
+             * 旁路由是否健康
+             * @member
+             * @type {boolean}
+             */
+            this["SideRouterOK"] = false;
+        }
+        if (!("Health" in $$source)) {
+            /**
+             * 旁路由健康检查结果
+             * @member
+             * @type {HealthResult | null}
+             */
+            this["Health"] = null;
+        }
+        if (!("Action" in $$source)) {
+            /**
+             * 最终动作: static(旁路由), fallback(保留静态IP改用主路由网关) 或 dhcp, 获取网络信息失败时为空
+             * @member
+             * @type {string}
+             */
+            this["Action"] = "";
+        }
+        if (!("Paused" in $$source)) {
+            /**
+             * 是否因切换过于频繁暂停了自动切换
+             * @member
+             * @type {boolean}
+             */
+            this["Paused"] = false;
+        }
+        if (!("Summary" in $$source)) {
+            /**
+             * 便于阅读的判断过程
+             * @member
+             * @type {string[]}
+             */
+            this["Summary"] = [];
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new MatchDecision instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {MatchDecision}
+     */
+    static createFrom($$source = {}) {
+        const $$createField2_0 = $$createType9;
+        const $$createField3_0 = $$createType11;
+        const $$createField7_0 = $$createType13;
+        const $$createField10_0 = $$createType14;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("Facts" in $$parsedSource) {
+            $$parsedSource["Facts"] = $$createField2_0($$parsedSource["Facts"]);
+        }
+        if ("Traces" in $$parsedSource) {
+            $$parsedSource["Traces"] = $$createField3_0($$parsedSource["Traces"]);
+        }
+        if ("Health" in $$parsedSource) {
+            $$parsedSource["Health"] = $$createField7_0($$parsedSource["Health"]);
+        }
+        if ("Summary" in $$parsedSource) {
+            $$parsedSource["Summary"] = $$createField10_0($$parsedSource["Summary"]);
+        }
+        return new MatchDecision(/** @type {Partial<MatchDecision>} */($$parsedSource));
+    }
+}
+
+/**
+ * MatchRule 网络配置的一条匹配规则
+ */
+export class MatchRule {
+    /**
+     * Creates a new MatchRule instance.
+     * @param {Partial<MatchRule>} [$$source = {}] - The source object to create the MatchRule.
+     */
+    constructor($$source = {}) {
+        if (!("Type" in $$source)) {
+            /**
+             * 规则类型: ssid, bssid, subnet, gateway_mac, dhcp_server, dns_suffix
+             * @member
+             * @type {string}
+             */
+            this["Type"] = "";
+        }
+        if (!("Values" in $$source)) {
+            /**
+             * 可选值, 当前网络与其中任一值相同即满足
+             * @member
+             * @type {string[]}
+             */
+            this["Values"] = [];
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new MatchRule instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {MatchRule}
+     */
+    static createFrom($$source = {}) {
+        const $$createField1_0 = $$createType14;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("Values" in $$parsedSource) {
+            $$parsedSource["Values"] = $$createField1_0($$parsedSource["Values"]);
+        }
+        return new MatchRule(/** @type {Partial<MatchRule>} */($$parsedSource));
+    }
+}
+
+/**
+ * MonitorPolicy 自适应模式的检查时间设置, 单位为秒, 为0时使用默认值
+ */
+export class MonitorPolicy {
+    /**
+     * Creates a new MonitorPolicy instance.
+     * @param {Partial<MonitorPolicy>} [$$source = {}] - The source object to create the MonitorPolicy.
+     */
+    constructor($$source = {}) {
+        if (!("StartupDelay" in $$source)) {
+            /**
+             * 启动后第一次检查前等待的时间
+             * @member
+             * @type {number}
+             */
+            this["StartupDelay"] = 0;
+        }
+        if (!("StaticInterval" in $$source)) {
+            /**
+             * 使用旁路由(静态IP)时的检查间隔
+             * @member
+             * @type {number}
+             */
+            this["StaticInterval"] = 0;
+        }
+        if (!("DHCPInterval" in $$source)) {
+            /**
+             * 动态IP时的检查间隔, 默认30; 能监听网络变化通知时默认60
+             * @member
+             * @type {number}
+             */
+            this["DHCPInterval"] = 0;
+        }
+        if (!("MaxBackoff" in $$source)) {
+            /**
+             * 网络命令连续失败时检查间隔翻倍, 最长不超过该时间
+             * @member
+             * @type {number}
+             */
+            this["MaxBackoff"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new MonitorPolicy instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {MonitorPolicy}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new MonitorPolicy(/** @type {Partial<MonitorPolicy>} */($$parsedSource));
+    }
+}
+
+/**
+ * NetworkFacts 判断当前所在网络时使用的信息
+ */
+export class NetworkFacts {
+    /**
+     * Creates a new NetworkFacts instance.
+     * @param {Partial<NetworkFacts>} [$$source = {}] - The source object to create the NetworkFacts.
+     */
+    constructor($$source = {}) {
+        if (!("Interface" in $$source)) {
+            /**
+             * 网络接口名称
+             * @member
+             * @type {string}
+             */
+            this["Interface"] = "";
+        }
+        if (!("InterfaceType" in $$source)) {
+            /**
+             * 网卡类型: wifi 或 ethernet
+             * @member
+             * @type {string}
+             */
+            this["InterfaceType"] = "";
+        }
+        if (!("SSID" in $$source)) {
+            /**
+             * WiFi名称, 有线网络为空
+             * @member
+             * @type {string}
+             */
+            this["SSID"] = "";
+        }
+        if (!("BSSID" in $$source)) {
+            /**
+             * 接入点MAC地址
+             * @member
+             * @type {string}
+             */
+            this["BSSID"] = "";
+        }
+        if (!("IPAddress" in $$source)) {
+            /**
+             * 当前IPv4地址
+             * @member
+             * @type {string}
+             */
+            this["IPAddress"] = "";
+        }
+        if (!("DHCP" in $$source)) {
+            /**
+             * 当前地址是否由DHCP分配
+             * @member
+             * @type {boolean}
+             */
+            this["DHCP"] = false;
+        }
+        if (!("Gateway" in $$source)) {
+            /**
+             * 默认网关
+             * @member
+             * @type {string}
+             */
+            this["Gateway"] = "";
+        }
+        if (!("GatewayMAC" in $$source)) {
+            /**
+             * 默认网关的MAC地址
+             * @member
+             * @type {string}
+             */
+            this["GatewayMAC"] = "";
+        }
+        if (!("DHCPServer" in $$source)) {
+            /**
+             * DHCP服务器地址
+             * @member
+             * @type {string}
+             */
+            this["DHCPServer"] = "";
+        }
+        if (!("DNSSuffix" in $$source)) {
+            /**
+             * 连接的DNS后缀
+             * @member
+             * @type {string}
+             */
+            this["DNSSuffix"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new NetworkFacts instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {NetworkFacts}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new NetworkFacts(/** @type {Partial<NetworkFacts>} */($$parsedSource));
+    }
+}
+
+/**
+ * NetworkInterface 网卡信息
+ */
+export class NetworkInterface {
+    /**
+     * Creates a new NetworkInterface instance.
+     * @param {Partial<NetworkInterface>} [$$source = {}] - The source object to create the NetworkInterface.
+     */
+    constructor($$source = {}) {
+        if (!("Name" in $$source)) {
+            /**
+             * 网卡名称, 如 "WLAN"、"以太网 2"、wlan0
+             * @member
+             * @type {string}
+             */
+            this["Name"] = "";
+        }
+        if (!("Index" in $$source)) {
+            /**
+             * 接口索引
+             * @member
+             * @type {number}
+             */
+            this["Index"] = 0;
+        }
+        if (!("Type" in $$source)) {
+            /**
+             * 网卡类型: wifi 或 ethernet
+             * @member
+             * @type {string}
+             */
+            this["Type"] = "";
+        }
+        if (!("MAC" in $$source)) {
+            /**
+             * 网卡MAC地址
+             * @member
+             * @type {string}
+             */
+            this["MAC"] = "";
+        }
+        if (!("Connected" in $$source)) {
+            /**
+             * 是否已连接
+             * @member
+             * @type {boolean}
+             */
+            this["Connected"] = false;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new NetworkInterface instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {NetworkInterface}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new NetworkInterface(/** @type {Partial<NetworkInterface>} */($$parsedSource));
+    }
+}
+
+/**
+ * NetworkStatus 网络状态结构
+ */
+export class NetworkStatus {
+    /**
+     * Creates a new NetworkStatus instance.
+     * @param {Partial<NetworkStatus>} [$$source = {}] - The source object to create the NetworkStatus.
+     */
+    constructor($$source = {}) {
+        if (!("Interface" in $$source)) {
+            /**
+             * 网络接口名称
+             * @member
+             * @type {string}
+             */
+            this["Interface"] = "";
+        }
+        if (!("InterfaceType" in $$source)) {
+            /**
+             * 网卡类型: wifi 或 ethernet
+             * @member
+             * @type {string}
+             */
+            this["InterfaceType"] = "";
+        }
+        if (!("WiFiName" in $$source)) {
+            /**
+             * WiFi名称
+             * @member
+             * @type {string}
+             */
+            this["WiFiName"] = "";
+        }
+        if (!("WiFiConnected" in $$source)) {
+            /**
+             * WiFi连接状态
+             * @member
+             * @type {boolean}
+             */
+            this["WiFiConnected"] = false;
+        }
+        if (!("IPAddress" in $$source)) {
+            /**
+             * 当前IP地址
+             * @member
+             * @type {string}
+             */
+            this["IPAddress"] = "";
+        }
+        if (!("Gateway" in $$source)) {
+            /**
+             * 当前网关
+             * @member
+             * @type {string}
+             */
+            this["Gateway"] = "";
+        }
+        if (!("GatewayReachable" in $$source)) {
+            /**
+             * 网关是否可达
+             * @member
+             * @type {boolean}
+             */
+            this["GatewayReachable"] = false;
+        }
+        if (!("GatewayRTT" in $$source)) {
+            /**
+             * 网关平均往返时间(毫秒)
+             * @member
+             * @type {number}
+             */
+            this["GatewayRTT"] = 0;
+        }
+        if (!("DNS" in $$source)) {
+            /**
+             * 当前首选DNS
+             * @member
+             * @type {string}
+             */
+            this["DNS"] = "";
+        }
+        if (!("DNSReachable" in $$source)) {
+            /**
+             * 首选DNS是否可达
+             * @member
+             * @type {boolean}
+             */
+            this["DNSReachable"] = false;
+        }
+        if (!("DNSServers" in $$source)) {
+            /**
+             * 当前全部DNS服务器, 按优先顺序
+             * @member
+             * @type {DNSServerStatus[]}
+             */
+            this["DNSServers"] = [];
+        }
+        if (!("IPAssignment" in $$source)) {
+            /**
+             * IP分配方式: "自动(DHCP)" 或 "手动"
+             * @member
+             * @type {string}
+             */
+            this["IPAssignment"] = "";
+        }
+        if (!("DNSAssignment" in $$source)) {
+            /**
+             * DNS分配方式: "自动(DHCP)" 或 "手动"
+             * @member
+             * @type {string}
+             */
+            this["DNSAssignment"] = "";
+        }
+        if (!("ActiveProfile" in $$source)) {
+            /**
+             * 当前生效的网络配置名称, 动态IP时为空
+             * @member
+             * @type {string}
+             */
+            this["ActiveProfile"] = "";
+        }
+        if (!("SplitRoutes" in $$source)) {
+            /**
+             * 已添加的经过旁路由的分流路由目标(CIDR)
+             * @member
+             * @type {string[]}
+             */
+            this["SplitRoutes"] = [];
+        }
+        if (!("SplitGateway" in $$source)) {
+            /**
+             * 分流路由经过的网关(旁路由)
+             * @member
+             * @type {string}
+             */
+            this["SplitGateway"] = "";
+        }
+        if (!("IPv6Addresses" in $$source)) {
+            /**
+             * IPv6地址(带前缀长度), 不包含链路本地地址
+             * @member
+             * @type {string[]}
+             */
+            this["IPv6Addresses"] = [];
+        }
+        if (!("IPv6Gateways" in $$source)) {
+            /**
+             * IPv6默认网关
+             * @member
+             * @type {string[]}
+             */
+            this["IPv6Gateways"] = [];
+        }
+        if (!("IPv6DNS" in $$source)) {
+            /**
+             * IPv6 DNS服务器
+             * @member
+             * @type {string[]}
+             */
+            this["IPv6DNS"] = [];
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new NetworkStatus instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {NetworkStatus}
+     */
+    static createFrom($$source = {}) {
+        const $$createField10_0 = $$createType16;
+        const $$createField14_0 = $$createType14;
+        const $$createField16_0 = $$createType14;
+        const $$createField17_0 = $$createType14;
+        const $$createField18_0 = $$createType14;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("DNSServers" in $$parsedSource) {
+            $$parsedSource["DNSServers"] = $$createField10_0($$parsedSource["DNSServers"]);
+        }
+        if ("SplitRoutes" in $$parsedSource) {
+            $$parsedSource["SplitRoutes"] = $$createField14_0($$parsedSource["SplitRoutes"]);
+        }
+        if ("IPv6Addresses" in $$parsedSource) {
+            $$parsedSource["IPv6Addresses"] = $$createField16_0($$parsedSource["IPv6Addresses"]);
+        }
+        if ("IPv6Gateways" in $$parsedSource) {
+            $$parsedSource["IPv6Gateways"] = $$createField17_0($$parsedSource["IPv6Gateways"]);
+        }
+        if ("IPv6DNS" in $$parsedSource) {
+            $$parsedSource["IPv6DNS"] = $$createField18_0($$parsedSource["IPv6DNS"]);
+        }
+        return new NetworkStatus(/** @type {Partial<NetworkStatus>} */($$parsedSource));
+    }
+}
+
+/**
+ * ProbeResult 一项健康检查的结果
+ */
+export class ProbeResult {
+    /**
+     * Creates a new ProbeResult instance.
+     * @param {Partial<ProbeResult>} [$$source = {}] - The source object to create the ProbeResult.
+     */
+    constructor($$source = {}) {
+        if (!("Probe" in $$source)) {
+            /**
+             * 检查项
+             * @member
+             * @type {HealthProbe}
+             */
+            this["Probe"] = (new HealthProbe());
+        }
+        if (!("OK" in $$source)) {
+            /**
+             * 是否通过
+             * @member
+             * @type {boolean}
+             */
+            this["OK"] = false;
+        }
+        if (!("Latency" in $$source)) {
+            /**
+             * 耗时(毫秒)
+             * @member
+             * @type {number}
+             */
+            this["Latency"] = 0;
+        }
+        if (!("Detail" in $$source)) {
+            /**
+             * 结果说明或失败原因
+             * @member
+             * @type {string}
+             */
+            this["Detail"] = "";
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ProbeResult instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ProbeResult}
+     */
+    static createFrom($$source = {}) {
+        const $$createField0_0 = $$createType17;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("Probe" in $$parsedSource) {
+            $$parsedSource["Probe"] = $$createField0_0($$parsedSource["Probe"]);
+        }
+        return new ProbeResult(/** @type {Partial<ProbeResult>} */($$parsedSource));
+    }
+}
+
+/**
+ * Profile 一个网络环境（如家、办公室、父母家）的旁路由配置
+ */
+export class Profile {
+    /**
+     * Creates a new Profile instance.
+     * @param {Partial<Profile>} [$$source = {}] - The source object to create the Profile.
+     */
+    constructor($$source = {}) {
+        if (!("Name" in $$source)) {
+            /**
+             * 配置名称
+             * @member
+             * @type {string}
+             */
+            this["Name"] = "";
+        }
+        if (!("SSIDs" in $$source)) {
+            /**
+             * 使用该配置的WiFi名称(SSID), 等同于一条 ssid 规则
+             * @member
+             * @type {string[]}
+             */
+            this["SSIDs"] = [];
+        }
+        if (!("MatchMode" in $$source)) {
+            /**
+             * 规则组合方式: all(全部满足, 默认), any(任一满足)
+             * @member
              * @type {string}
              */
-            this["HomeSSID"] = "";
+            this["MatchMode"] = "";
+        }
+        if (!("Rules" in $$source)) {
+            /**
+             * 其他匹配规则: bssid, subnet, gateway_mac, dhcp_server, dns_suffix
+             * @member
+             * @type {MatchRule[]}
+             */
+            this["Rules"] = [];
         }
         if (!("StaticIP" in $$source)) {
             /**
-             * 静态IP地址
+             * 静态IP地址, 可以带前缀长度, 如 10.0.0.50/16
              * @member
              * @type {string}
              */
             this["StaticIP"] = "";
         }
+        if (!("SubnetMask" in $$source)) {
+            /**
+             * 子网掩码, 如 255.255.0.0; 与前缀长度都未设置时为 255.255.255.0
+             * @member
+             * @type {string}
+             */
+            this["SubnetMask"] = "";
+        }
         if (!("Gateway" in $$source)) {
             /**
-             * 网关地址
+             * 网关地址(旁路由)
              * @member
              * @type {string}
              */
@@ -41,135 +1136,442 @@ export class Config {
         }
         if (!("DNS" in $$source)) {
             /**
-             * DNS服务器地址
+             * DNS服务器地址, 按优先顺序
+             * @member
+             * @type {DNSList}
+             */
+            this["DNS"] = [];
+        }
+        if (!("Strategy" in $$source)) {
+            /**
+             * 切换到旁路由的方式: address(修改IP地址, 默认), metric(保留DHCP地址, 添加跃点数更小的默认路由)
              * @member
              * @type {string}
              */
-            this["DNS"] = "";
+            this["Strategy"] = "";
         }
-        if (!("AutoStart" in $$source)) {
+        if (!("FallbackGateway" in $$source)) {
             /**
-             * 是否开机自启
+             * 旁路由不健康时改用的网关(主路由), 设置后保留静态IP只切换网关和DNS; 为空时切回动态IP
              * @member
-             * @type {boolean}
+             * @type {string}
              */
-            this["AutoStart"] = false;
+            this["FallbackGateway"] = "";
         }
-        if (!("IPMode" in $$source)) {
+        if (!("FallbackDNS" in $$source)) {
+            /**
+             * 改用主路由网关时的DNS, 为空时使用 FallbackGateway
+             * @member
+             * @type {DNSList}
+             */
+            this["FallbackDNS"] = [];
+        }
+        if (!("SplitRoutes" in $$source)) {
             /**
-             * IP模式: adaptive(自适应), dynamic(动态IP), static(静态IP)
+             * 分流: 只有这些目标(CIDR, 如 10.0.0.0/8)经过旁路由, 其他流量仍使用DHCP网关
+             * @member
+             * @type {string[]}
+             */
+            this["SplitRoutes"] = [];
+        }
+        if (!("SplitRoutesFile" in $$source)) {
+            /**
+             * 分流目标列表文件, 每行一个 CIDR, # 开头为注释; 相对路径相对于程序所在目录
              * @member
              * @type {string}
              */
-            this["IPMode"] = "";
+            this["SplitRoutesFile"] = "";
+        }
+        if (!("IPv6Mode" in $$source)) {
+            /**
+             * 静态IP时的IPv6处理方式: 空(不处理), static(静态IPv6), disable(停用IPv6自动配置和DNS)
+             * @member
+             * @type {string}
+             */
+            this["IPv6Mode"] = "";
+        }
+        if (!("StaticIPv6" in $$source)) {
+            /**
+             * 静态IPv6地址, 带前缀长度, 如 fd00::100/64; 不带前缀长度时为 /64
+             * @member
+             * @type {string}
+             */
+            this["StaticIPv6"] = "";
+        }
+        if (!("GatewayIPv6" in $$source)) {
+            /**
+             * IPv6网关地址
+             * @member
+             * @type {string}
+             */
+            this["GatewayIPv6"] = "";
+        }
+        if (!("DNSv6" in $$source)) {
+            /**
+             * IPv6 DNS服务器, 按优先顺序; 为空时不使用IPv6 DNS
+             * @member
+             * @type {DNSList}
+             */
+            this["DNSv6"] = [];
+        }
+        if (!("HealthChecks" in $$source)) {
+            /**
+             * 旁路由健康检查, 为空时只Ping网关
+             * @member
+             * @type {HealthProbe[]}
+             */
+            this["HealthChecks"] = [];
+        }
+        if (!("HealthMode" in $$source)) {
+            /**
+             * 健康检查组合方式: all(全部通过, 默认), any(任一通过), quorum(至少 HealthQuorum 项通过)
+             * @member
+             * @type {string}
+             */
+            this["HealthMode"] = "";
+        }
+        if (!("HealthQuorum" in $$source)) {
+            /**
+             * quorum 方式至少需要通过的检查数量, 为0时为过半数
+             * @member
+             * @type {number}
+             */
+            this["HealthQuorum"] = 0;
         }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new Config instance from a string or object.
+     * Creates a new Profile instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {Config}
+     * @returns {Profile}
      */
     static createFrom($$source = {}) {
+        const $$createField1_0 = $$createType14;
+        const $$createField3_0 = $$createType19;
+        const $$createField7_0 = $$createType20;
+        const $$createField10_0 = $$createType20;
+        const $$createField11_0 = $$createType14;
+        const $$createField16_0 = $$createType20;
+        const $$createField17_0 = $$createType21;
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        return new Config(/** @type {Partial<Config>} */($$parsedSource));
+        if ("SSIDs" in $$parsedSource) {
+            $$parsedSource["SSIDs"] = $$createField1_0($$parsedSource["SSIDs"]);
+        }
+        if ("Rules" in $$parsedSource) {
+            $$parsedSource["Rules"] = $$createField3_0($$parsedSource["Rules"]);
+        }
+        if ("DNS" in $$parsedSource) {
+            $$parsedSource["DNS"] = $$createField7_0($$parsedSource["DNS"]);
+        }
+        if ("FallbackDNS" in $$parsedSource) {
+            $$parsedSource["FallbackDNS"] = $$createField10_0($$parsedSource["FallbackDNS"]);
+        }
+        if ("SplitRoutes" in $$parsedSource) {
+            $$parsedSource["SplitRoutes"] = $$createField11_0($$parsedSource["SplitRoutes"]);
+        }
+        if ("DNSv6" in $$parsedSource) {
+            $$parsedSource["DNSv6"] = $$createField16_0($$parsedSource["DNSv6"]);
+        }
+        if ("HealthChecks" in $$parsedSource) {
+            $$parsedSource["HealthChecks"] = $$createField17_0($$parsedSource["HealthChecks"]);
+        }
+        return new Profile(/** @type {Partial<Profile>} */($$parsedSource));
     }
 }
 
 /**
- * NetworkStatus 网络状态结构
+ * ProfileTrace 一个网络配置的匹配过程
  */
-export class NetworkStatus {
+export class ProfileTrace {
     /**
-     * Creates a new NetworkStatus instance.
-     * @param {Partial<NetworkStatus>} [$$source = {}] - The source object to create the NetworkStatus.
+     * Creates a new ProfileTrace instance.
+     * @param {Partial<ProfileTrace>} [$$source = {}] - The source object to create the ProfileTrace.
      */
     constructor($$source = {}) {
-        if (!("WiFiName" in $$source)) {
+        if (!("Profile" in $$source)) {
             /**
-             * WiFi名称
+             * 网络配置名称
              * @member
              * @type {string}
              */
-            this["WiFiName"] = "";
+            this["Profile"] = "";
         }
-        if (!("WiFiConnected" in $$source)) {
+        if (!("MatchMode" in $$source)) {
             /**
-             * WiFi连接状态
+             * 规则组合方式
+             * @member
+             * @type {string}
+             */
+            this["MatchMode"] = "";
+        }
+        if (!("Results" in $$source)) {
+            /**
+             * 各条规则的结果
+             * @member
+             * @type {RuleResult[]}
+             */
+            this["Results"] = [];
+        }
+        if (!("Matched" in $$source)) {
+            /**
+             * 是否匹配
              * @member
              * @type {boolean}
              */
-            this["WiFiConnected"] = false;
+            this["Matched"] = false;
         }
-        if (!("IPAddress" in $$source)) {
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new ProfileTrace instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {ProfileTrace}
+     */
+    static createFrom($$source = {}) {
+        const $$createField2_0 = $$createType23;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("Results" in $$parsedSource) {
+            $$parsedSource["Results"] = $$createField2_0($$parsedSource["Results"]);
+        }
+        return new ProfileTrace(/** @type {Partial<ProfileTrace>} */($$parsedSource));
+    }
+}
+
+/**
+ * RuleResult 一条规则的判断结果
+ */
+export class RuleResult {
+    /**
+     * Creates a new RuleResult instance.
+     * @param {Partial<RuleResult>} [$$source = {}] - The source object to create the RuleResult.
+     */
+    constructor($$source = {}) {
+        if (!("Rule" in $$source)) {
             /**
-             * 当前IP地址
+             * 规则
              * @member
-             * @type {string}
+             * @type {MatchRule}
              */
-            this["IPAddress"] = "";
+            this["Rule"] = (new MatchRule());
         }
-        if (!("Gateway" in $$source)) {
+        if (!("Actual" in $$source)) {
             /**
-             * 当前网关
+             * 当前网络中的实际值
              * @member
              * @type {string}
              */
-            this["Gateway"] = "";
+            this["Actual"] = "";
         }
-        if (!("GatewayReachable" in $$source)) {
+        if (!("Matched" in $$source)) {
             /**
-             * 网关是否可达
+             * 是否满足
              * @member
              * @type {boolean}
              */
-            this["GatewayReachable"] = false;
+            this["Matched"] = false;
         }
-        if (!("DNS" in $$source)) {
+        if (!("Note" in $$source)) {
             /**
-             * 当前DNS
+             * 补充说明
              * @member
              * @type {string}
              */
-            this["DNS"] = "";
+            this["Note"] = "";
         }
-        if (!("DNSReachable" in $$source)) {
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new RuleResult instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {RuleResult}
+     */
+    static createFrom($$source = {}) {
+        const $$createField0_0 = $$createType18;
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        if ("Rule" in $$parsedSource) {
+            $$parsedSource["Rule"] = $$createField0_0($$parsedSource["Rule"]);
+        }
+        return new RuleResult(/** @type {Partial<RuleResult>} */($$parsedSource));
+    }
+}
+
+/**
+ * SwitchPolicy 自适应模式的防抖和熔断设置, 为0时使用默认值
+ */
+export class SwitchPolicy {
+    /**
+     * Creates a new SwitchPolicy instance.
+     * @param {Partial<SwitchPolicy>} [$$source = {}] - The source object to create the SwitchPolicy.
+     */
+    constructor($$source = {}) {
+        if (!("LeaveAfterFailures" in $$source)) {
+            /**
+             * 旁路由连续检查不健康多少次后切回动态IP或主路由网关
+             * @member
+             * @type {number}
+             */
+            this["LeaveAfterFailures"] = 0;
+        }
+        if (!("ReturnAfterSuccesses" in $$source)) {
+            /**
+             * 旁路由连续检查健康多少次后切回静态IP
+             * @member
+             * @type {number}
+             */
+            this["ReturnAfterSuccesses"] = 0;
+        }
+        if (!("MinDwellSeconds" in $$source)) {
+            /**
+             * 每次切换后至少保持多少秒才能再次切换
+             * @member
+             * @type {number}
+             */
+            this["MinDwellSeconds"] = 0;
+        }
+        if (!("MaxSwitchesPerHour" in $$source)) {
+            /**
+             * 一小时内最多因旁路由健康状况切换的次数, 超过后暂停自动切换并通知用户
+             * @member
+             * @type {number}
+             */
+            this["MaxSwitchesPerHour"] = 0;
+        }
+        if (!("VerifySeconds" in $$source)) {
+            /**
+             * 切换后检查新网关连通性的最长时间, 超时仍不可达时恢复切换前的配置
+             * @member
+             * @type {number}
+             */
+            this["VerifySeconds"] = 0;
+        }
+
+        Object.assign(this, $$source);
+    }
+
+    /**
+     * Creates a new SwitchPolicy instance from a string or object.
+     * @param {any} [$$source = {}]
+     * @returns {SwitchPolicy}
+     */
+    static createFrom($$source = {}) {
+        let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
+        return new SwitchPolicy(/** @type {Partial<SwitchPolicy>} */($$parsedSource));
+    }
+}
+
+/**
+ * SwitchResult 一次切换的结果, 切换失败或切换后网关不可达时记录是否已恢复切换前的配置
+ */
+export class SwitchResult {
+    /**
+     * Creates a new SwitchResult instance.
+     * @param {Partial<SwitchResult>} [$$source = {}] - The source object to create the SwitchResult.
+     */
+    constructor($$source = {}) {
+        if (!("Time" in $$source)) {
             /**
-             * DNS是否可达
+             * 切换时间
+             * @member
+             * @type {string}
+             */
+            this["Time"] = "";
+        }
+        if (!("Interface" in $$source)) {
+            /**
+             * 网卡名称
+             * @member
+             * @type {string}
+             */
+            this["Interface"] = "";
+        }
+        if (!("Target" in $$source)) {
+            /**
+             * 切换目标, 如网络配置名称或动态IP
+             * @member
+             * @type {string}
+             */
+            this["Target"] = "";
+        }
+        if (!("Success" in $$source)) {
+            /**
+             * 是否切换成功且通过连通性检查
              * @member
              * @type {boolean}
              */
-            this["DNSReachable"] = false;
+            this["Success"] = false;
         }
-        if (!("IPAssignment" in $$source)) {
+        if (!("RolledBack" in $$source)) {
             /**
-             * IP分配方式: "自动(DHCP)" 或 "手动"
+             * 是否已恢复切换前的配置
+             * @member
+             * @type {boolean}
+             */
+            this["RolledBack"] = false;
+        }
+        if (!("Error" in $$source)) {
+            /**
+             * 切换或连通性检查失败的原因
              * @member
              * @type {string}
              */
-            this["IPAssignment"] = "";
+            this["Error"] = "";
         }
-        if (!("DNSAssignment" in $$source)) {
+        if (!("RollbackError" in $$source)) {
             /**
-             * DNS分配方式: "自动(DHCP)" 或 "手动"
+             * 恢复切换前的配置失败的原因
              * @member
              * @type {string}
              */
-            this["DNSAssignment"] = "";
+            this["RollbackError"] = "";
         }
 
         Object.assign(this, $$source);
     }
 
     /**
-     * Creates a new NetworkStatus instance from a string or object.
+     * Creates a new SwitchResult instance from a string or object.
      * @param {any} [$$source = {}]
-     * @returns {NetworkStatus}
+     * @returns {SwitchResult}
      */
     static createFrom($$source = {}) {
         let $$parsedSource = typeof $$source === 'string' ? JSON.parse($$source) : $$source;
-        return new NetworkStatus(/** @type {Partial<NetworkStatus>} */($$parsedSource));
+        return new SwitchResult(/** @type {Partial<SwitchResult>} */($$parsedSource));
     }
 }
+
+// Private type creation functions
+const $$createType0 = Profile.createFrom;
+const $$createType1 = $Create.Array($$createType0);
+const $$createType2 = InterfaceSetting.createFrom;
+const $$createType3 = $Create.Array($$createType2);
+const $$createType4 = SwitchPolicy.createFrom;
+const $$createType5 = MonitorPolicy.createFrom;
+const $$createType6 = ProbeResult.createFrom;
+const $$createType7 = $Create.Array($$createType6);
+const $$createType8 = NetworkFacts.createFrom;
+const $$createType9 = $Create.Nullable($$createType8);
+const $$createType10 = ProfileTrace.createFrom;
+const $$createType11 = $Create.Array($$createType10);
+const $$createType12 = HealthResult.createFrom;
+const $$createType13 = $Create.Nullable($$createType12);
+const $$createType14 = $Create.Array($Create.Any);
+const $$createType15 = DNSServerStatus.createFrom;
+const $$createType16 = $Create.Array($$createType15);
+const $$createType17 = HealthProbe.createFrom;
+const $$createType18 = MatchRule.createFrom;
+const $$createType19 = $Create.Array($$createType18);
+var $$createType20 = /** @type {(...args: any[]) => any} */(function $$initCreateType20(...args) {
+    if ($$createType20 === $$initCreateType20) {
+        $$createType20 = $$createType14;
+    }
+    return $$createType20(...args);
+});
+const $$createType21 = $Create.Array($$createType17);
+const $$createType22 = RuleResult.createFrom;
+const $$createType23 = $Create.Array($$createType22);
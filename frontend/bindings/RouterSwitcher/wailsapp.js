@@ -33,13 +33,43 @@ export function GetConfig() {
     }));
 }
 
+/**
+ * GetInterfaces 列出所有无线和有线网卡, 用于在界面中选择要管理的网卡
+ * @returns {$CancellablePromise<$models.NetworkInterface[]>}
+ */
+export function GetInterfaces() {
+    return $Call.ByID(4177067657).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType3($result);
+    }));
+}
+
+/**
+ * GetLastDecisions 获取各网卡自适应模式最近一次的判断过程, 用于解释为什么切换或不切换
+ * @returns {$CancellablePromise<($models.MatchDecision | null)[]>}
+ */
+export function GetLastDecisions() {
+    return $Call.ByID(2819572988).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType6($result);
+    }));
+}
+
 /**
  * GetNetworkStatus 获取当前网络详细状态
  * @returns {$CancellablePromise<$models.NetworkStatus | null>}
  */
 export function GetNetworkStatus() {
     return $Call.ByID(4224989919).then(/** @type {($result: any) => any} */(($result) => {
-        return $$createType3($result);
+        return $$createType8($result);
+    }));
+}
+
+/**
+ * GetSwitchResults 获取各网卡最近一次切换的结果, 用于显示切换失败和自动恢复的情况
+ * @returns {$CancellablePromise<($models.SwitchResult | null)[]>}
+ */
+export function GetSwitchResults() {
+    return $Call.ByID(3943850585).then(/** @type {($result: any) => any} */(($result) => {
+        return $$createType11($result);
     }));
 }
 
@@ -69,7 +99,7 @@ export function IsConnectedToHomeNetwork() {
 }
 
 /**
- * IsSideRouterReachable 检查旁路由是否可达
+ * IsSideRouterReachable 检查旁路由是否可达, 按网络配置的健康检查判断
  * @returns {$CancellablePromise<boolean>}
  */
 export function IsSideRouterReachable() {
@@ -93,7 +123,7 @@ export function SwitchToAdaptive() {
 }
 
 /**
- * SwitchToDHCP 切换到动态IP模式
+ * SwitchToDHCP 将所有管理的网卡切换到动态IP模式
  * @returns {$CancellablePromise<void>}
  */
 export function SwitchToDHCP() {
@@ -101,7 +131,7 @@ export function SwitchToDHCP() {
 }
 
 /**
- * SwitchToStatic 切换到静态IP模式
+ * SwitchToStatic 将所有管理的网卡切换到静态IP模式
  * @returns {$CancellablePromise<void>}
  */
 export function SwitchToStatic() {
@@ -120,5 +150,13 @@ export function UpdateConfig(config) {
 // Private type creation functions
 const $$createType0 = $models.Config.createFrom;
 const $$createType1 = $Create.Nullable($$createType0);
-const $$createType2 = $models.NetworkStatus.createFrom;
-const $$createType3 = $Create.Nullable($$createType2);
+const $$createType2 = $models.NetworkInterface.createFrom;
+const $$createType3 = $Create.Array($$createType2);
+const $$createType4 = $models.MatchDecision.createFrom;
+const $$createType5 = $Create.Nullable($$createType4);
+const $$createType6 = $Create.Array($$createType5);
+const $$createType7 = $models.NetworkStatus.createFrom;
+const $$createType8 = $Create.Nullable($$createType7);
+const $$createType9 = $models.SwitchResult.createFrom;
+const $$createType10 = $Create.Nullable($$createType9);
+const $$createType11 = $Create.Array($$createType10);
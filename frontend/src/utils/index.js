@@ -1,6 +1,22 @@
 
 
 
+// isValidMask 检查点分十进制子网掩码, 1 必须连续
+export function isValidMask(mask) {
+    if (!isValidIp(mask)) {
+        return false
+    }
+    const bits = mask.split('.').reduce((acc, part) => acc * 256 + parseInt(part, 10), 0)
+    const inverted = (~bits) >>> 0
+    return bits !== 0 && (inverted & (inverted + 1)) === 0
+}
+
+// prefixToMask 将前缀长度转换为点分十进制子网掩码
+export function prefixToMask(prefix) {
+    const bits = prefix === 0 ? 0 : (0xffffffff << (32 - prefix)) >>> 0
+    return [24, 16, 8, 0].map(shift => (bits >>> shift) & 0xff).join('.')
+}
+
 export function isValidIp(ip) {
     return /^(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)$/.test(ip);
 }
\ No newline at end of file
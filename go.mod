@@ -2,7 +2,12 @@ module RouterSwitcher
 
 go 1.24.0
 
-require github.com/wailsapp/wails/v3 v3.0.0-alpha.41
+require (
+	github.com/wailsapp/wails/v3 v3.0.0-alpha.41
+	golang.org/x/net v0.37.0
+	golang.org/x/sys v0.38.0
+	golang.org/x/text v0.23.0
+)
 
 require (
 	dario.cat/mergo v1.0.1 // indirect
@@ -40,9 +45,6 @@ require (
 	github.com/wailsapp/mimetype v1.4.1 // indirect
 	github.com/xanzy/ssh-agent v0.3.3 // indirect
 	golang.org/x/crypto v0.36.0 // indirect
-	golang.org/x/net v0.37.0 // indirect
-	golang.org/x/sys v0.38.0 // indirect
-	golang.org/x/text v0.23.0 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/warnings.v0 v0.1.2 // indirect
 )
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// 健康检查类型
+const (
+	ProbeICMP = "icmp" // Ping 目标地址
+	ProbeTCP  = "tcp"  // 连接目标端口
+	ProbeHTTP = "http" // 请求URL并检查状态码和响应内容
+	ProbeDNS  = "dns"  // 向DNS服务器查询域名, 必须返回解析结果
+
+	// ProbeUpstream 经过旁路由访问外网: 通过旁路由的DNS解析目标域名, 再经过旁路由连接目标端口
+	ProbeUpstream = "upstream"
+)
+
+// HealthQuorum 健康检查组合方式: 至少 HealthQuorum 项检查通过, 另外两种方式与规则相同(all, any)
+const HealthQuorum = "quorum"
+
+// DefaultProbeTimeout 健康检查默认超时时间
+const DefaultProbeTimeout = 3 * time.Second
+
+// HealthProbe 网络配置的一项旁路由健康检查
+type HealthProbe struct {
+	Type         string // 检查类型: icmp, tcp, http, dns, upstream
+	Target       string // icmp 为地址, tcp 为 地址:端口 或端口, http 为URL, dns 为查询的域名, upstream 为 域名:端口; icmp 和 tcp 未写地址时使用网关
+	Server       string // dns 和 upstream 检查使用的DNS服务器, 为空时使用网关
+	ExpectStatus int    // http 期望的状态码, 为0时接受 2xx 和 3xx
+	ExpectBody   string // http 响应中必须包含的内容, 为空时不检查
+	Timeout      int    // 超时时间(毫秒), 为0时为3000
+}
+
+// ProbeResult 一项健康检查的结果
+type ProbeResult struct {
+	Probe   HealthProbe // 检查项
+	OK      bool        // 是否通过
+	Latency float64     // 耗时(毫秒)
+	Detail  string      // 结果说明或失败原因
+}
+
+// HealthResult 旁路由健康检查的结果
+type HealthResult struct {
+	Mode     string        // 组合方式: all, any, quorum
+	Required int           // 判定为健康至少需要通过的检查数量
+	Passed   int           // 通过的检查数量
+	Healthy  bool          // 旁路由是否健康
+	Results  []ProbeResult // 各项检查的结果
+}
+
+// HealthEnv 执行健康检查时的网络环境
+type HealthEnv struct {
+	Backend   NetworkBackend // 网络后端, 用于Ping和添加临时路由
+	Interface string         // 执行检查的网卡
+	Gateway   string         // 网卡当前的默认网关
+}
+
+// healthProbes 网络配置的健康检查项, 未配置时只Ping网关
+func healthProbes(profile *Profile) []HealthProbe {
+	if len(profile.HealthChecks) == 0 {
+		return []HealthProbe{{Type: ProbeICMP, Target: profile.Gateway}}
+	}
+	return profile.HealthChecks
+}
+
+// healthRequired 判定为健康至少需要通过的检查数量
+func healthRequired(mode string, quorum, total int) int {
+	switch mode {
+	case MatchAny:
+		return 1
+	case HealthQuorum:
+		if quorum <= 0 {
+			return total/2 + 1
+		}
+		if quorum > total {
+			return total
+		}
+		return quorum
+	default:
+		return total
+	}
+}
+
+// CheckHealth 同时执行网络配置的全部健康检查, 按组合方式判断旁路由是否健康
+func CheckHealth(profile *Profile, env HealthEnv) *HealthResult {
+	probes := healthProbes(profile)
+	result := &HealthResult{Mode: profile.HealthMode, Results: make([]ProbeResult, len(probes))}
+	if result.Mode != MatchAny && result.Mode != HealthQuorum {
+		result.Mode = MatchAll
+	}
+	result.Required = healthRequired(result.Mode, profile.HealthQuorum, len(probes))
+
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func(i int, probe HealthProbe) {
+			defer wg.Done()
+			result.Results[i] = runProbe(probe, profile.Gateway, env)
+		}(i, probe)
+	}
+	wg.Wait()
+
+	for _, r := range result.Results {
+		if r.OK {
+			result.Passed++
+		}
+	}
+	result.Healthy = result.Passed >= result.Required
+	return result
+}
+
+// runProbe 执行一项健康检查, gateway 为旁路由地址, 未写地址时使用
+func runProbe(probe HealthProbe, gateway string, env HealthEnv) ProbeResult {
+	result := ProbeResult{Probe: probe}
+	timeout := DefaultProbeTimeout
+	if probe.Timeout > 0 {
+		timeout = time.Duration(probe.Timeout) * time.Millisecond
+	}
+
+	start := time.Now()
+	var err error
+	switch probe.Type {
+	case ProbeICMP:
+		host := strings.TrimSpace(probe.Target)
+		if host == "" {
+			host = gateway
+		}
+		result.Probe.Target = host
+		if !env.Backend.Ping(host) {
+			err = fmt.Errorf("%s 无响应", host)
+		}
+	case ProbeTCP:
+		address := probeTCPAddress(probe.Target, gateway)
+		result.Probe.Target = address
+		err = probeTCP(address, timeout)
+	case ProbeHTTP:
+		result.Detail, err = probeHTTP(probe, timeout)
+	case ProbeDNS:
+		server := strings.TrimSpace(probe.Server)
+		if server == "" {
+			server = gateway
+		}
+		result.Probe.Server = server
+		result.Detail, err = probeDNS(server, probe.Target, timeout)
+	case ProbeUpstream:
+		server := strings.TrimSpace(probe.Server)
+		if server == "" {
+			server = gateway
+		}
+		result.Probe.Server = server
+		result.Detail, err = probeUpstream(env, server, gateway, probe.Target, timeout)
+	default:
+		err = fmt.Errorf("未知的检查类型: %s", probe.Type)
+	}
+	result.Latency = float64(time.Since(start)) / float64(time.Millisecond)
+
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	result.OK = true
+	return result
+}
+
+// probeTCPAddress 补全 tcp 检查的地址, 只写端口时使用网关地址
+func probeTCPAddress(target, gateway string) string {
+	target = strings.TrimSpace(target)
+	if _, err := strconv.Atoi(strings.TrimPrefix(target, ":")); err == nil {
+		return net.JoinHostPort(gateway, strings.TrimPrefix(target, ":"))
+	}
+	return target
+}
+
+// probeTCP 检查能否在超时时间内连接目标端口
+func probeTCP(address string, timeout time.Duration) error {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return fmt.Errorf("连接 %s 失败: %v", address, err)
+	}
+	conn.Close()
+	return nil
+}
+
+// probeHTTP 请求URL, 检查状态码和响应内容
+func probeHTTP(probe HealthProbe, timeout time.Duration) (string, error) {
+	client := &http.Client{
+		Timeout: timeout,
+		// 不跟随重定向, 3xx 本身即说明服务在工作
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		// 直接连接, 不使用系统代理
+		Transport: &http.Transport{Proxy: nil, DisableKeepAlives: true},
+	}
+	resp, err := client.Get(probe.Target)
+	if err != nil {
+		return "", fmt.Errorf("请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if probe.ExpectStatus != 0 {
+		if resp.StatusCode != probe.ExpectStatus {
+			return "", fmt.Errorf("状态码 %d, 期望 %d", resp.StatusCode, probe.ExpectStatus)
+		}
+	} else if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return "", fmt.Errorf("状态码 %d", resp.StatusCode)
+	}
+
+	if probe.ExpectBody != "" {
+		// 只读取前 64KB
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if err != nil {
+			return "", fmt.Errorf("读取响应失败: %v", err)
+		}
+		if !strings.Contains(string(body), probe.ExpectBody) {
+			return "", fmt.Errorf("响应中没有 %q", probe.ExpectBody)
+		}
+	}
+	return fmt.Sprintf("状态码 %d", resp.StatusCode), nil
+}
+
+// probeDNS 向DNS服务器查询域名, 必须返回至少一条解析结果
+func probeDNS(server, domain string, timeout time.Duration) (string, error) {
+	ips, err := lookupA(server, domain, timeout)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s 解析为 %s", domain, ips[0]), nil
+}
+
+// probeUpstream 检查旁路由能否访问外网
+// 通过旁路由的DNS解析目标域名, 当前网关不是旁路由时添加一条经过旁路由的临时路由, 再连接目标端口;
+// 旁路由的上游断开时DNS解析或连接会失败, 即使旁路由本身仍然响应Ping
+func probeUpstream(env HealthEnv, server, gateway, target string, timeout time.Duration) (string, error) {
+	host, port, err := net.SplitHostPort(strings.TrimSpace(target))
+	if err != nil {
+		return "", fmt.Errorf("无效的upstream检查目标: %s", target)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		ips, err := lookupA(server, host, timeout)
+		if err != nil {
+			return "", err
+		}
+		ip = ips[0]
+	}
+
+	if env.Gateway != gateway {
+		route := probeRoute{Interface: env.Interface, Destination: ip.String() + "/32", Gateway: gateway}
+		if err := upstreamRoutes.acquire(env.Backend, route); err != nil {
+			return "", err
+		}
+		defer upstreamRoutes.release(env.Backend, route)
+	}
+
+	if err := probeTCP(net.JoinHostPort(ip.String(), port), timeout); err != nil {
+		return "", fmt.Errorf("经过旁路由%v", err)
+	}
+	return fmt.Sprintf("经过旁路由连接 %s(%s) 成功", target, ip), nil
+}
+
+// probeRoute upstream 检查添加的一条临时路由
+type probeRoute struct {
+	Interface   string
+	Destination string
+	Gateway     string
+}
+
+// probeRoutes 按引用计数管理 upstream 检查的临时路由
+// 同时检查同一目标时共用一条路由, 最后一个检查结束后才删除, 避免一个检查删除另一个检查正在使用的路由
+type probeRoutes struct {
+	mu    sync.Mutex
+	users map[probeRoute]int
+}
+
+// upstreamRoutes 所有 upstream 检查共用的临时路由
+var upstreamRoutes = &probeRoutes{users: make(map[probeRoute]int)}
+
+// acquire 使用临时路由, 第一个使用者添加路由
+func (r *probeRoutes) acquire(backend NetworkBackend, route probeRoute) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.users[route] == 0 {
+		if err := backend.AddRoute(route.Interface, route.Destination, route.Gateway); err != nil {
+			return err
+		}
+	}
+	r.users[route]++
+	return nil
+}
+
+// release 停止使用临时路由, 最后一个使用者删除路由
+func (r *probeRoutes) release(backend NetworkBackend, route probeRoute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users[route]--
+	if r.users[route] > 0 {
+		return
+	}
+	delete(r.users, route)
+	if err := backend.DeleteRoute(route.Interface, route.Destination, route.Gateway); err != nil {
+		log.Printf("删除临时路由 %s 失败: %v", route.Destination, err)
+	}
+}
+
+// lookupA 向DNS服务器查询域名的A记录, 没有解析结果时返回错误
+// 不使用 net.Resolver: Windows 上它总是使用系统DNS, 无法指定服务器
+func lookupA(server, domain string, timeout time.Duration) ([]net.IP, error) {
+	name, err := dnsmessage.NewName(strings.TrimSuffix(strings.TrimSpace(domain), ".") + ".")
+	if err != nil {
+		return nil, fmt.Errorf("无效的域名: %s", domain)
+	}
+
+	id := uint16(time.Now().UnixNano())
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET}},
+	}
+	packet, err := query.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("生成DNS请求失败: %v", err)
+	}
+
+	conn, err := net.DialTimeout("udp", dnsServerAddress(server), timeout)
+	if err != nil {
+		return nil, fmt.Errorf("连接DNS服务器 %s 失败: %v", server, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := conn.Write(packet); err != nil {
+		return nil, fmt.Errorf("发送DNS请求失败: %v", err)
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("DNS服务器 %s 无响应: %v", server, err)
+		}
+		var reply dnsmessage.Message
+		if err := reply.Unpack(buf[:n]); err != nil || reply.ID != id || !reply.Response {
+			continue
+		}
+		if reply.RCode != dnsmessage.RCodeSuccess {
+			return nil, fmt.Errorf("DNS服务器 %s 返回 %s", server, reply.RCode)
+		}
+		var ips []net.IP
+		for _, answer := range reply.Answers {
+			if a, ok := answer.Body.(*dnsmessage.AResource); ok {
+				ips = append(ips, net.IP(a.A[:]))
+			}
+		}
+		if len(ips) > 0 {
+			return ips, nil
+		}
+		return nil, fmt.Errorf("DNS服务器 %s 没有返回 %s 的解析结果", server, domain)
+	}
+}
+
+// dnsServerAddress DNS服务器的地址和端口, 未写端口时使用53
+func dnsServerAddress(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(server, "53")
+}
+
+// validate 检查健康检查项
+func (p HealthProbe) validate() error {
+	switch p.Type {
+	case ProbeICMP:
+		return nil
+	case ProbeTCP:
+		if _, port, err := net.SplitHostPort(probeTCPAddress(p.Target, "0.0.0.0")); err != nil || port == "" {
+			return fmt.Errorf("无效的tcp检查地址: %s", p.Target)
+		}
+	case ProbeHTTP:
+		if !strings.HasPrefix(p.Target, "http://") && !strings.HasPrefix(p.Target, "https://") {
+			return fmt.Errorf("无效的http检查URL: %s", p.Target)
+		}
+	case ProbeDNS:
+		if strings.TrimSpace(p.Target) == "" {
+			return fmt.Errorf("dns检查的域名不能为空")
+		}
+		if p.Server != "" && net.ParseIP(p.Server) == nil {
+			return fmt.Errorf("无效的dns检查服务器: %s", p.Server)
+		}
+	case ProbeUpstream:
+		if host, port, err := net.SplitHostPort(strings.TrimSpace(p.Target)); err != nil || host == "" || port == "" {
+			return fmt.Errorf("无效的upstream检查目标, 应为 域名:端口: %s", p.Target)
+		}
+		if p.Server != "" && net.ParseIP(p.Server).To4() == nil {
+			return fmt.Errorf("无效的upstream检查DNS服务器: %s", p.Server)
+		}
+	default:
+		return fmt.Errorf("未知的检查类型: %s", p.Type)
+	}
+	return nil
+}
+
+// describe 生成便于阅读的健康检查过程
+func (h *HealthResult) describe(gateway string) []string {
+	verdict := "不健康"
+	if h.Healthy {
+		verdict = "健康"
+	}
+	lines := []string{fmt.Sprintf("旁路由 %s 健康检查(%s, %d/%d 项通过, 至少需要 %d 项): %s",
+		gateway, h.Mode, h.Passed, len(h.Results), h.Required, verdict)}
+	for _, r := range h.Results {
+		mark := "✗"
+		if r.OK {
+			mark = "✓"
+		}
+		target := r.Probe.Target
+		if r.Probe.Type == ProbeDNS || r.Probe.Type == ProbeUpstream {
+			target = fmt.Sprintf("%s @%s", r.Probe.Target, r.Probe.Server)
+		}
+		line := fmt.Sprintf("  %s %s %s (%.0fms)", mark, r.Probe.Type, target, r.Latency)
+		if r.Detail != "" {
+			line += ": " + r.Detail
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// startDNSResponder 在本地UDP端口上启动DNS服务器, records 为域名到A记录的映射
+// 不在 records 中的域名返回 NXDOMAIN, 映射为空字符串的域名返回没有解析结果的应答
+func startDNSResponder(t *testing.T, records map[string]string) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("启动DNS服务器失败: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) == 0 {
+				continue
+			}
+			question := query.Questions[0]
+			reply := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, RCode: dnsmessage.RCodeSuccess},
+				Questions: query.Questions,
+			}
+			ip, ok := records[strings.TrimSuffix(question.Name.String(), ".")]
+			switch {
+			case !ok:
+				reply.RCode = dnsmessage.RCodeNameError
+			case ip != "":
+				var a [4]byte
+				copy(a[:], net.ParseIP(ip).To4())
+				reply.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+					Body:   &dnsmessage.AResource{A: a},
+				}}
+			}
+			packet, err := reply.Pack()
+			if err != nil {
+				continue
+			}
+			conn.WriteTo(packet, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+// startTCPListener 在本地启动接受连接的TCP服务, 返回端口
+func startTCPListener(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("启动TCP服务失败: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	return port
+}
+
+// closedTCPPort 返回一个没有服务监听的本地端口
+func closedTCPPort(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("获取空闲端口失败: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+	return port
+}
+
+func TestLookupA(t *testing.T) {
+	server := startDNSResponder(t, map[string]string{"router.test": "192.168.31.2", "empty.test": ""})
+
+	ips, err := lookupA(server, "router.test", time.Second)
+	if err != nil || len(ips) != 1 || !ips[0].Equal(net.ParseIP("192.168.31.2")) {
+		t.Errorf("lookupA(router.test) = %v, %v", ips, err)
+	}
+	if _, err := lookupA(server, "missing.test", time.Second); err == nil || !strings.Contains(err.Error(), "NameError") {
+		t.Errorf("NXDOMAIN 应返回错误, got %v", err)
+	}
+	if _, err := lookupA(server, "empty.test", time.Second); err == nil || !strings.Contains(err.Error(), "没有返回") {
+		t.Errorf("没有解析结果时应返回错误, got %v", err)
+	}
+
+	// 不回复的服务器
+	silent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	if _, err := lookupA(silent.LocalAddr().String(), "router.test", 100*time.Millisecond); err == nil || !strings.Contains(err.Error(), "无响应") {
+		t.Errorf("服务器不回复时应返回错误, got %v", err)
+	}
+}
+
+func TestProbeUpstream(t *testing.T) {
+	open, closed := startTCPListener(t), closedTCPPort(t)
+	server := startDNSResponder(t, map[string]string{"upstream.test": "127.0.0.1"})
+	backend := NewFakeBackend("WLAN")
+	env := HealthEnv{Backend: backend, Interface: "WLAN", Gateway: "127.0.0.1"}
+
+	if detail, err := probeUpstream(env, server, "127.0.0.1", "upstream.test:"+open, time.Second); err != nil {
+		t.Errorf("probeUpstream 应成功, got %v", err)
+	} else if !strings.Contains(detail, "127.0.0.1") {
+		t.Errorf("detail = %q", detail)
+	}
+	if _, err := probeUpstream(env, server, "127.0.0.1", "upstream.test:"+closed, time.Second); err == nil {
+		t.Error("端口未监听时应失败")
+	}
+	if _, err := probeUpstream(env, server, "127.0.0.1", "missing.test:"+open, time.Second); err == nil {
+		t.Error("DNS解析失败时应失败")
+	}
+
+	// 当前网关不是旁路由时经过临时路由连接, 结束后删除
+	env.Gateway = "192.168.1.1"
+	if _, err := probeUpstream(env, server, "127.0.0.1", "upstream.test:"+open, time.Second); err != nil {
+		t.Errorf("经过临时路由的 probeUpstream 应成功, got %v", err)
+	}
+	if len(backend.Routes) != 0 {
+		t.Errorf("临时路由没有删除: %v", backend.Routes)
+	}
+	backend.Routes["127.0.0.1/32"] = "127.0.0.1"
+	if _, err := probeUpstream(env, server, "127.0.0.1", "upstream.test:"+open, time.Second); err == nil {
+		t.Error("添加临时路由失败时应失败")
+	}
+}
+
+func TestProbeRoutesShared(t *testing.T) {
+	backend := NewFakeBackend("WLAN")
+	routes := &probeRoutes{users: make(map[probeRoute]int)}
+	route := probeRoute{Interface: "WLAN", Destination: "1.1.1.1/32", Gateway: "192.168.1.2"}
+
+	// 同一目标的两个检查共用一条路由, 第二个检查不重复添加
+	for i := 0; i < 2; i++ {
+		if err := routes.acquire(backend, route); err != nil {
+			t.Fatalf("第%d个检查添加临时路由: %v", i+1, err)
+		}
+	}
+	routes.release(backend, route)
+	if backend.Routes["1.1.1.1/32"] != "192.168.1.2" {
+		t.Fatalf("仍在使用的临时路由被删除: %v", backend.Routes)
+	}
+	routes.release(backend, route)
+	if len(backend.Routes) != 0 || len(routes.users) != 0 {
+		t.Errorf("最后一个检查结束后没有删除临时路由: %v, users = %v", backend.Routes, routes.users)
+	}
+
+	// 添加失败时不计入使用者
+	backend.Routes["1.1.1.1/32"] = "192.168.1.1"
+	if err := routes.acquire(backend, route); err == nil || routes.users[route] != 0 {
+		t.Errorf("添加失败时 acquire() = %v, users = %v", err, routes.users)
+	}
+}
+
+func TestProbeUpstreamConcurrent(t *testing.T) {
+	open := startTCPListener(t)
+	server := startDNSResponder(t, map[string]string{"upstream.test": "127.0.0.1"})
+	backend := NewFakeBackend("WLAN")
+	env := HealthEnv{Backend: backend, Interface: "WLAN", Gateway: "192.168.1.1"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := probeUpstream(env, server, "127.0.0.1", "upstream.test:"+open, time.Second)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("同时检查同一目标失败: %v", err)
+		}
+	}
+	if len(backend.Routes) != 0 {
+		t.Errorf("临时路由没有删除: %v", backend.Routes)
+	}
+}
+
+func TestCheckHealthAggregation(t *testing.T) {
+	open, closed := startTCPListener(t), closedTCPPort(t)
+	server := startDNSResponder(t, map[string]string{"upstream.test": "127.0.0.1"})
+	env := HealthEnv{Backend: NewFakeBackend("WLAN"), Interface: "WLAN", Gateway: "127.0.0.1"}
+	probes := []HealthProbe{
+		{Type: ProbeTCP, Target: open, Timeout: 1000},
+		{Type: ProbeTCP, Target: closed, Timeout: 1000},
+		{Type: ProbeDNS, Target: "upstream.test", Server: server, Timeout: 1000},
+		{Type: ProbeUpstream, Target: "upstream.test:" + closed, Server: server, Timeout: 1000},
+	}
+
+	tests := []struct {
+		mode     string
+		quorum   int
+		required int
+		healthy  bool
+	}{
+		{MatchAll, 0, 4, false},
+		{"", 0, 4, false},
+		{MatchAny, 0, 1, true},
+		{HealthQuorum, 2, 2, true},
+		{HealthQuorum, 3, 3, false},
+		{HealthQuorum, 0, 3, false},
+		{HealthQuorum, 9, 4, false},
+	}
+	for _, tt := range tests {
+		profile := &Profile{Gateway: "127.0.0.1", HealthChecks: probes, HealthMode: tt.mode, HealthQuorum: tt.quorum}
+		result := CheckHealth(profile, env)
+		if result.Passed != 2 || result.Required != tt.required || result.Healthy != tt.healthy {
+			t.Errorf("%s/%d: passed=%d required=%d healthy=%v, want 2/%d/%v",
+				tt.mode, tt.quorum, result.Passed, result.Required, result.Healthy, tt.required, tt.healthy)
+		}
+		for i, want := range []bool{true, false, true, false} {
+			if result.Results[i].OK != want {
+				t.Errorf("%s/%d: 第 %d 项 = %+v", tt.mode, tt.quorum, i, result.Results[i])
+			}
+		}
+	}
+}
+
+func TestCheckHealthDefaultsToGatewayPing(t *testing.T) {
+	backend := NewFakeBackend("WLAN")
+	backend.Reachable["192.168.1.2"] = true
+	env := HealthEnv{Backend: backend, Interface: "WLAN", Gateway: "192.168.1.1"}
+
+	result := CheckHealth(&Profile{Gateway: "192.168.1.2"}, env)
+	if !result.Healthy || len(result.Results) != 1 || result.Results[0].Probe.Target != "192.168.1.2" {
+		t.Errorf("CheckHealth = %+v", result)
+	}
+	backend.Reachable["192.168.1.2"] = false
+	if result := CheckHealth(&Profile{Gateway: "192.168.1.2"}, env); result.Healthy {
+		t.Errorf("网关不可达时应不健康: %+v", result)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+import "os/exec"
+
+// hideCmdWindow 非 Windows 系统的命令没有窗口, 无需处理
+func hideCmdWindow(cmd *exec.Cmd) {}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// NetshKeywords 一种系统语言下 netsh 输出中使用的关键字
+type NetshKeywords struct {
+	Locale string // 语言代码, 如 zh-CN
+
+	// netsh interface show interface
+	Connected string // 状态列: 已连接
+	Dedicated string // 类型列: 专用
+
+	// netsh interface ip show config
+	InterfaceHeader string                      // 接口标题行中的固定文本, 接口名称在引号中
+	Fields          map[netshConfigField]string // 各字段名称
+	Yes             string                      // "是"
+	None            string                      // "无"
+
+	// netsh wlan show interfaces
+	LocationPermission []string // 位置服务被禁用时输出中的提示
+}
+
+// netshLocales 已知语言的关键字表
+var netshLocales = []*NetshKeywords{
+	{
+		Locale:          "en",
+		Connected:       "Connected",
+		Dedicated:       "Dedicated",
+		InterfaceHeader: "Configuration for interface",
+		Fields: map[netshConfigField]string{
+			fieldDHCP:            "DHCP enabled",
+			fieldIPAddress:       "IP Address",
+			fieldSubnetPrefix:    "Subnet Prefix",
+			fieldDefaultGateway:  "Default Gateway",
+			fieldGatewayMetric:   "Gateway Metric",
+			fieldInterfaceMetric: "InterfaceMetric",
+			fieldDHCPDNS:         "DNS servers configured through DHCP",
+			fieldStaticDNS:       "Statically Configured DNS Servers",
+			fieldDHCPWINS:        "WINS servers configured through DHCP",
+			fieldStaticWINS:      "Statically Configured WINS Servers",
+		},
+		Yes:  "Yes",
+		None: "None",
+		LocationPermission: []string{
+			"Network shell commands need location permission",
+			"WlanQueryInterface returned error 5",
+			"Access is denied",
+		},
+	},
+	{
+		Locale:          "zh-CN",
+		Connected:       "已连接",
+		Dedicated:       "专用",
+		InterfaceHeader: "的配置",
+		Fields: map[netshConfigField]string{
+			fieldDHCP:            "DHCP 已启用",
+			fieldIPAddress:       "IP 地址",
+			fieldSubnetPrefix:    "子网前缀",
+			fieldDefaultGateway:  "默认网关",
+			fieldGatewayMetric:   "网关跃点数",
+			fieldInterfaceMetric: "InterfaceMetric",
+			fieldDHCPDNS:         "通过 DHCP 配置的 DNS 服务器",
+			fieldStaticDNS:       "静态配置的 DNS 服务器",
+			fieldDHCPWINS:        "通过 DHCP 配置的 WINS 服务器",
+			fieldStaticWINS:      "静态配置的 WINS 服务器",
+		},
+		Yes:  "是",
+		None: "无",
+		LocationPermission: []string{
+			"命令需要位置权限才能访问",
+			"WlanQueryInterface 返回错误 5",
+			"拒绝访问",
+		},
+	},
+	{
+		Locale:          "zh-TW",
+		Connected:       "已連線",
+		Dedicated:       "專用",
+		InterfaceHeader: "的設定",
+		Fields: map[netshConfigField]string{
+			fieldDHCP:            "DHCP 已啟用",
+			fieldIPAddress:       "IP 位址",
+			fieldSubnetPrefix:    "子網路首碼",
+			fieldDefaultGateway:  "預設閘道",
+			fieldGatewayMetric:   "閘道計量",
+			fieldInterfaceMetric: "InterfaceMetric",
+			fieldDHCPDNS:         "透過 DHCP 設定的 DNS 伺服器",
+			fieldStaticDNS:       "靜態設定的 DNS 伺服器",
+			fieldDHCPWINS:        "透過 DHCP 設定的 WINS 伺服器",
+			fieldStaticWINS:      "靜態設定的 WINS 伺服器",
+		},
+		Yes:  "是",
+		None: "無",
+		LocationPermission: []string{
+			"需要位置權限",
+			"WlanQueryInterface 傳回錯誤 5",
+			"存取被拒",
+		},
+	},
+	{
+		Locale:          "ja",
+		Connected:       "接続済み",
+		Dedicated:       "専用",
+		InterfaceHeader: "の構成",
+		Fields: map[netshConfigField]string{
+			fieldDHCP:            "DHCP 有効",
+			fieldIPAddress:       "IP アドレス",
+			fieldSubnetPrefix:    "サブネット プレフィックス",
+			fieldDefaultGateway:  "デフォルト ゲートウェイ",
+			fieldGatewayMetric:   "ゲートウェイ メトリック",
+			fieldInterfaceMetric: "InterfaceMetric",
+			fieldDHCPDNS:         "DHCP 経由で構成された DNS サーバー",
+			fieldStaticDNS:       "静的に構成された DNS サーバー",
+			fieldDHCPWINS:        "DHCP 経由で構成された WINS サーバー",
+			fieldStaticWINS:      "静的に構成された WINS サーバー",
+		},
+		Yes:  "はい",
+		None: "なし",
+		LocationPermission: []string{
+			"位置情報のアクセス許可",
+			"WlanQueryInterface がエラー 5 を返しました",
+			"アクセスが拒否されました",
+		},
+	},
+	{
+		Locale:          "de",
+		Connected:       "Verbunden",
+		Dedicated:       "Dediziert",
+		InterfaceHeader: "Konfiguration für Schnittstelle",
+		Fields: map[netshConfigField]string{
+			fieldDHCP:            "DHCP aktiviert",
+			fieldIPAddress:       "IP-Adresse",
+			fieldSubnetPrefix:    "Subnetzpräfix",
+			fieldDefaultGateway:  "Standardgateway",
+			fieldGatewayMetric:   "Gatewaymetrik",
+			fieldInterfaceMetric: "InterfaceMetric",
+			fieldDHCPDNS:         "Über DHCP konfigurierte DNS-Server",
+			fieldStaticDNS:       "Statisch konfigurierte DNS-Server",
+			fieldDHCPWINS:        "Über DHCP konfigurierte WINS-Server",
+			fieldStaticWINS:      "Statisch konfigurierte WINS-Server",
+		},
+		Yes:  "Ja",
+		None: "Keine",
+		LocationPermission: []string{
+			"Standortberechtigung",
+			"WlanQueryInterface hat den Fehler 5 zurückgegeben",
+			"Zugriff verweigert",
+		},
+	},
+	{
+		Locale:          "fr",
+		Connected:       "Connecté",
+		Dedicated:       "Dédié",
+		InterfaceHeader: "Configuration pour l'interface",
+		Fields: map[netshConfigField]string{
+			fieldDHCP:            "DHCP activé",
+			fieldIPAddress:       "Adresse IP",
+			fieldSubnetPrefix:    "Préfixe de sous-réseau",
+			fieldDefaultGateway:  "Passerelle par défaut",
+			fieldGatewayMetric:   "Métrique de passerelle",
+			fieldInterfaceMetric: "InterfaceMetric",
+			fieldDHCPDNS:         "Serveurs DNS configurés via DHCP",
+			fieldStaticDNS:       "Serveurs DNS configurés statiquement",
+			fieldDHCPWINS:        "Serveurs WINS configurés via DHCP",
+			fieldStaticWINS:      "Serveurs WINS configurés statiquement",
+		},
+		Yes:  "Oui",
+		None: "Aucun",
+		LocationPermission: []string{
+			"autorisation d'accès à la localisation",
+			"WlanQueryInterface a retourné l'erreur 5",
+			"Accès refusé",
+		},
+	},
+}
+
+// KeywordCatalog netsh 解析时使用的关键字目录
+// 自动识别时包含所有已知语言，强制指定语言时只包含该语言
+type KeywordCatalog []*NetshKeywords
+
+// NewKeywordCatalog 创建关键字目录, locale 为空或 auto 时自动识别
+func NewKeywordCatalog(locale string) (KeywordCatalog, error) {
+	if locale == "" || locale == "auto" {
+		return KeywordCatalog(netshLocales), nil
+	}
+	for _, keywords := range netshLocales {
+		if strings.EqualFold(keywords.Locale, locale) {
+			return KeywordCatalog{keywords}, nil
+		}
+	}
+	return nil, fmt.Errorf("不支持的语言: %s", locale)
+}
+
+// Field 根据字段名称查找 show config 中的字段
+func (c KeywordCatalog) Field(key string) netshConfigField {
+	for _, keywords := range c {
+		for field, name := range keywords.Fields {
+			if strings.EqualFold(key, name) {
+				return field
+			}
+		}
+	}
+	return fieldUnknown
+}
+
+// IsYes 取值是否表示"是"
+func (c KeywordCatalog) IsYes(value string) bool {
+	for _, keywords := range c {
+		if strings.EqualFold(value, keywords.Yes) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNone 取值是否表示"无"
+func (c KeywordCatalog) IsNone(value string) bool {
+	for _, keywords := range c {
+		if strings.EqualFold(value, keywords.None) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsConnectedDedicated show interface 中的状态和类型是否为"已连接"的"专用"接口
+func (c KeywordCatalog) IsConnectedDedicated(state, ifaceType string) bool {
+	for _, keywords := range c {
+		if strings.EqualFold(state, keywords.Connected) && strings.EqualFold(ifaceType, keywords.Dedicated) {
+			return true
+		}
+	}
+	return false
+}
+
+// InterfaceHeader 是否为 show config 中的接口标题行, 是则返回接口名称
+// 接口名称可能用 "" 、“” 或 «» 括起来
+func (c KeywordCatalog) InterfaceHeader(line string) (string, bool) {
+	start := strings.IndexAny(line, `"“«`)
+	end := strings.LastIndexAny(line, `"”»`)
+	if start == -1 || end <= start {
+		return "", false
+	}
+	for _, keywords := range c {
+		if strings.Contains(line, keywords.InterfaceHeader) {
+			_, size := utf8.DecodeRuneInString(line[start:])
+			return strings.TrimSpace(line[start+size : end]), true
+		}
+	}
+	return "", false
+}
+
+// NeedsLocationPermission 输出是否表示位置服务被禁用
+func (c KeywordCatalog) NeedsLocationPermission(output string) bool {
+	for _, keywords := range c {
+		for _, hint := range keywords.LocationPermission {
+			if strings.Contains(output, hint) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// localeFixtures 各语言的 testdata/netsh 记录, ethernet 为已连接的有线接口名称
+var localeFixtures = []struct {
+	locale   string
+	ethernet string
+}{
+	{"en", "Ethernet 2"},
+	{"zh-CN", "以太网 2"},
+	{"zh-TW", "乙太網路 2"},
+	{"ja", "イーサネット 2"},
+	{"de", "Ethernet 2"},
+	{"fr", "Ethernet 2"},
+}
+
+func TestNetshLocalesShowConfig(t *testing.T) {
+	for _, tt := range localeFixtures {
+		for _, locale := range []string{tt.locale, "auto"} {
+			t.Run(tt.locale+"/"+locale, func(t *testing.T) {
+				catalog, err := NewKeywordCatalog(locale)
+				if err != nil {
+					t.Fatal(err)
+				}
+				output := readFixture(t, "netsh/show_config_"+tt.locale+".txt")
+
+				header := strings.TrimSpace(strings.SplitN(strings.TrimSpace(output), "\n", 2)[0])
+				if name, ok := catalog.InterfaceHeader(header); !ok || name != tt.ethernet {
+					t.Errorf("InterfaceHeader(%q) = %q, %v", header, name, ok)
+				}
+
+				got := ParseNetshIPConfig(output, catalog)
+				want := showConfigWant(tt.ethernet)
+				if len(got) != len(want) {
+					t.Fatalf("解析出 %d 个接口, want %d", len(got), len(want))
+				}
+				for i := range want {
+					if !reflect.DeepEqual(got[i], want[i]) {
+						t.Errorf("接口 %d = %+v, want %+v", i, got[i], want[i])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestNetshLocalesShowInterface(t *testing.T) {
+	for _, tt := range localeFixtures {
+		for _, locale := range []string{tt.locale, "auto"} {
+			t.Run(tt.locale+"/"+locale, func(t *testing.T) {
+				catalog, err := NewKeywordCatalog(locale)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var connected []string
+				for _, line := range strings.Split(readFixture(t, "netsh/show_interface_"+tt.locale+".txt"), "\n") {
+					state, ifType, name, ok := parseNetshInterfaceLine(line)
+					if ok && catalog.IsConnectedDedicated(state, ifType) {
+						connected = append(connected, name)
+					}
+				}
+				if want := []string{tt.ethernet}; !reflect.DeepEqual(connected, want) {
+					t.Errorf("已连接的专用接口 = %q, want %q", connected, want)
+				}
+			})
+		}
+	}
+}
+
+func TestNewKeywordCatalogUnknownLocale(t *testing.T) {
+	if _, err := NewKeywordCatalog("ko"); err == nil {
+		t.Error("不支持的语言应返回错误")
+	}
+}
@@ -3,11 +3,13 @@ package main
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"os/exec"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/wailsapp/wails/v3/pkg/application"
@@ -35,6 +37,11 @@ var redIcon []byte
 //go:embed icon/blue.png
 var blueIcon []byte
 
+// 动态IP走旁路由 - 绿色图标
+//
+//go:embed icon/green.png
+var greenIcon []byte
+
 // WailsApp struct
 type WailsApp struct {
 	ctx          context.Context
@@ -45,20 +52,70 @@ type WailsApp struct {
 	adaptiveItem *application.MenuItem
 	dynamicItem  *application.MenuItem
 	staticItem   *application.MenuItem
+	overrideItem *application.MenuItem
 	exitItem     *application.MenuItem
 	mainWindow   application.Window // 保存主窗口引用
+	backend      NetworkBackend     // 网络操作后端
+	runner       CommandRunner      // 外部命令执行器
+	watcher      *NetworkWatcher    // 系统网络变化通知
+
+	profileMu      sync.RWMutex
+	activeProfiles map[string]string        // 各网卡当前生效的网络配置名称, 动态IP的网卡不在其中
+	splitRoutes    map[string]splitRouteSet // 各网卡已添加的分流路由, 离开旁路由和退出时删除
+	defaultRoutes  map[string][]Route       // 各网卡添加优先默认路由前的其他默认路由, 删除时按它恢复跃点数
+	ipv6Modes      map[string]string        // 各网卡由程序设置的IPv6处理方式, 恢复自动获取后删除
+
+	switchMu    sync.Mutex
+	switchLocks map[string]*sync.Mutex // 各网卡的切换锁, 同一网卡同时只执行一次切换事务
+
+	decisionMu    sync.RWMutex
+	lastDecisions map[string]*MatchDecision // 各网卡自适应模式最近一次的判断过程
+	dhcpFacts     map[string]*NetworkFacts  // 各网卡最近一次DHCP状态下的网络信息
+	guards        map[string]*SwitchGuard   // 各网卡自适应切换的防抖状态
+	lastSwitches  map[string]*SwitchResult  // 各网卡最近一次切换的结果
 }
 
 // NewWailsApp creates a new WailsApp application struct
-func NewWailsApp() *WailsApp {
-	// 加载配置
-	config, err := LoadConfig()
-	if err != nil {
-		log.Printf("加载配置失败: %v", err)
+// config 为 nil 时从配置文件加载，runner 为 nil 时根据配置中的命令记录模式创建，backend 为 nil 时根据配置中的 Backend 创建
+func NewWailsApp(config *Config, backend NetworkBackend, runner CommandRunner) *WailsApp {
+	var err error
+	if config == nil {
+		// 加载配置
+		config, err = LoadConfig()
+		if err != nil {
+			log.Printf("加载配置失败: %v", err)
+		}
+	}
+
+	if runner == nil {
+		runner, err = NewCommandRunner(config.TranscriptMode, config.TranscriptFile)
+		if err != nil {
+			log.Printf("创建命令执行器失败: %v, 直接执行命令", err)
+			runner = NewExecRunner()
+		}
+	}
+
+	if backend == nil {
+		backend, err = NewBackend(config, runner)
+		if err != nil {
+			log.Printf("创建网络后端失败: %v, 使用默认后端", err)
+			backend, _ = NewBackend(&Config{Backend: "auto"}, runner)
+		}
 	}
 
 	return &WailsApp{
-		config: config,
+		config:         config,
+		backend:        backend,
+		runner:         runner,
+		activeProfiles: make(map[string]string),
+		splitRoutes:    make(map[string]splitRouteSet),
+		defaultRoutes:  make(map[string][]Route),
+		ipv6Modes:      make(map[string]string),
+		switchLocks:    make(map[string]*sync.Mutex),
+		lastDecisions:  make(map[string]*MatchDecision),
+		dhcpFacts:      make(map[string]*NetworkFacts),
+		guards:         make(map[string]*SwitchGuard),
+		lastSwitches:   make(map[string]*SwitchResult),
 	}
 }
 
@@ -99,6 +156,8 @@ func (a *WailsApp) createTrayMenu() {
 		a.systemTray.SetIcon(redIcon)
 	case "static":
 		a.systemTray.SetIcon(blueIcon)
+	case "override":
+		a.systemTray.SetIcon(greenIcon)
 	default:
 		a.systemTray.SetIcon(icon)
 	}
@@ -138,11 +197,23 @@ func (a *WailsApp) createTrayMenu() {
 		}
 	})
 
+	a.overrideItem = a.trayMenu.AddRadio("动态IP+旁路由", false)
+	a.overrideItem.OnClick(func(*application.Context) {
+		log.Println("切换到动态IP+旁路由模式")
+		a.config.IPMode = "override"
+		if err := a.UpdateConfig(a.config); err != nil {
+			log.Printf("更新配置失败: %v", err)
+		}
+	})
+
 	a.trayMenu.AddSeparator()
 
 	a.exitItem = a.trayMenu.Add("退出")
 	a.exitItem.OnClick(func(*application.Context) {
 		log.Println("退出程序")
+		if a.watcher != nil {
+			a.watcher.Close()
+		}
 		a.app.Quit()
 	})
 
@@ -207,7 +278,7 @@ func (a *WailsApp) updateTrayMenuState() {
 	log.Println("updateTrayMenuState", a.config.IPMode)
 
 	// 先清除所有勾选状态
-	for _, item := range []*application.MenuItem{a.adaptiveItem, a.dynamicItem, a.staticItem} {
+	for _, item := range []*application.MenuItem{a.adaptiveItem, a.dynamicItem, a.staticItem, a.overrideItem} {
 		if item != nil {
 			item.SetChecked(false)
 		}
@@ -236,6 +307,13 @@ func (a *WailsApp) updateTrayMenuState() {
 		if a.systemTray != nil {
 			a.systemTray.SetIcon(blueIcon)
 		}
+	case "override":
+		if a.overrideItem != nil {
+			a.overrideItem.SetChecked(true)
+		}
+		if a.systemTray != nil {
+			a.systemTray.SetIcon(greenIcon)
+		}
 	}
 
 	// 更新托盘菜单显示
@@ -254,7 +332,7 @@ func (a *WailsApp) updateTrayTooltip() {
 		return
 	}
 
-	status, err := GetCurrentNetworkStatus()
+	status, err := a.currentNetworkStatus()
 	if err != nil {
 		log.Printf("获取网络状态失败: %v", err)
 		a.systemTray.SetTooltip("路由器切换工具")
@@ -263,14 +341,26 @@ func (a *WailsApp) updateTrayTooltip() {
 
 	// 构建tooltip文本（优化为更简洁的格式，避免超出Windows tooltip长度限制）
 	tooltip := "路由器切换工具\n"
+	if a.switchPaused() {
+		tooltip += "自动切换已暂停\n"
+	}
 	tooltip += "\n"
 
-	// WiFi信息（使用符号表示状态：✓连接 ✗断开）
-	wifiStatus := "✗"
-	if status.WiFiConnected {
-		wifiStatus = "✓"
+	// 当前生效的网络配置
+	if profile := status.ActiveProfile; profile != "" {
+		tooltip += fmt.Sprintf("配置: %s\n", profile)
+	}
+
+	// WiFi信息（使用符号表示状态：✓连接 ✗断开）, 有线网卡显示接口名称
+	if status.InterfaceType == InterfaceEthernet {
+		tooltip += fmt.Sprintf("有线: %s [✓]\n", status.Interface)
+	} else {
+		wifiStatus := "✗"
+		if status.WiFiConnected {
+			wifiStatus = "✓"
+		}
+		tooltip += fmt.Sprintf("WiFi: %s [%s]\n", status.WiFiName, wifiStatus)
 	}
-	tooltip += fmt.Sprintf("WiFi: %s [%s]\n", status.WiFiName, wifiStatus)
 
 	// IP信息
 	tooltip += fmt.Sprintf("IP: %s\n", status.IPAddress)
@@ -430,6 +520,9 @@ func (a *WailsApp) GetConfig() *Config {
 // UpdateConfig 保存配置 & 应用新配置
 func (a *WailsApp) UpdateConfig(config *Config) error {
 	log.Println("UpdateConfig")
+	if err := ValidateConfig(config); err != nil {
+		return err
+	}
 	a.config = config
 	err := SaveConfig(a.config)
 	if err != nil {
@@ -437,6 +530,11 @@ func (a *WailsApp) UpdateConfig(config *Config) error {
 	}
 	log.Printf("保存配置成功: %+v\n", config)
 
+	// 清空防抖状态, 暂停的自动切换随之恢复
+	a.decisionMu.Lock()
+	a.guards = make(map[string]*SwitchGuard)
+	a.decisionMu.Unlock()
+
 	// 更新托盘菜单状态
 	a.updateTrayMenuState()
 
@@ -456,14 +554,20 @@ func (a *WailsApp) UpdateConfig(config *Config) error {
 	return nil
 }
 
-// SwitchToStatic 切换到静态IP模式
+// SwitchToStatic 将所有管理的网卡切换到静态IP模式
 func (a *WailsApp) SwitchToStatic() {
-	a.switchToStatic()
+	managed, _ := a.managedInterfaces()
+	for _, iface := range managed {
+		a.switchToStatic(iface.Name, a.staticProfile(iface))
+	}
 }
 
-// SwitchToDHCP 切换到动态IP模式
+// SwitchToDHCP 将所有管理的网卡切换到动态IP模式
 func (a *WailsApp) SwitchToDHCP() {
-	a.switchToDHCP()
+	managed, _ := a.managedInterfaces()
+	for _, iface := range managed {
+		a.switchToDHCP(iface.Name)
+	}
 }
 
 // SwitchToAdaptive 切换到自适应IP模式
@@ -482,19 +586,27 @@ func (a *WailsApp) CheckAndSwitch() {
 // IsConnectedToHomeNetwork 检查是否连接到家庭局域网
 func (a *WailsApp) IsConnectedToHomeNetwork() bool {
 	log.Println("IsConnectedToHomeNetwork")
-	return a.isConnectedToHomeNetwork()
+	iface, err := a.primaryInterface()
+	if err != nil {
+		return false
+	}
+	return a.matchCurrentProfile(iface) != nil
 }
 
-// IsSideRouterReachable 检查旁路由是否可达
+// IsSideRouterReachable 检查旁路由是否可达, 按网络配置的健康检查判断
 func (a *WailsApp) IsSideRouterReachable() bool {
 	log.Println("IsSideRouterReachable")
-	return a.isSideRouterReachable()
+	iface, err := a.primaryInterface()
+	if err != nil {
+		return false
+	}
+	return a.isSideRouterReachable(iface.Name, a.staticProfile(iface))
 }
 
 // GetNetworkStatus 获取当前网络详细状态
 func (a *WailsApp) GetNetworkStatus() *NetworkStatus {
 	log.Println("GetNetworkStatus")
-	status, err := GetCurrentNetworkStatus()
+	status, err := a.currentNetworkStatus()
 	if err != nil {
 		log.Printf("获取网络状态失败: %v", err)
 		// 返回空状态而不是nil
@@ -511,97 +623,472 @@ func (a *WailsApp) GetNetworkStatus() *NetworkStatus {
 	return status
 }
 
+// GetInterfaces 列出所有无线和有线网卡, 用于在界面中选择要管理的网卡
+func (a *WailsApp) GetInterfaces() []NetworkInterface {
+	log.Println("GetInterfaces")
+	ifaces, err := a.backend.ListInterfaces()
+	if err != nil {
+		log.Printf("获取网卡列表失败: %v", err)
+		return []NetworkInterface{}
+	}
+	return ifaces
+}
+
+// GetLastDecisions 获取各网卡自适应模式最近一次的判断过程, 用于解释为什么切换或不切换
+func (a *WailsApp) GetLastDecisions() []*MatchDecision {
+	a.decisionMu.RLock()
+	defer a.decisionMu.RUnlock()
+
+	decisions := make([]*MatchDecision, 0, len(a.lastDecisions))
+	for _, decision := range a.lastDecisions {
+		decisions = append(decisions, decision)
+	}
+	sort.Slice(decisions, func(i, j int) bool {
+		return decisions[i].Interface < decisions[j].Interface
+	})
+	return decisions
+}
+
+// GetSwitchResults 获取各网卡最近一次切换的结果, 用于显示切换失败和自动恢复的情况
+func (a *WailsApp) GetSwitchResults() []*SwitchResult {
+	a.decisionMu.RLock()
+	defer a.decisionMu.RUnlock()
+
+	results := make([]*SwitchResult, 0, len(a.lastSwitches))
+	for _, result := range a.lastSwitches {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Interface < results[j].Interface
+	})
+	return results
+}
+
 // OpenLocationSettings 打开位置设置页面
 func (a *WailsApp) OpenLocationSettings() error {
-	cmd := exec.Command("cmd", "/C", "start", "ms-settings:privacy-location")
-	hideCmdWindow(cmd)
-	return cmd.Start()
+	return a.runner.Start(Command{Name: "cmd", Args: []string{"/C", "start", "ms-settings:privacy-location"}})
 }
 
 // monitorNetwork 监控网络变化
+// 网卡状态或地址变化时立即检查, 另外按 Config.Monitor 定时检查:
+// 使用旁路由时快速检查以便尽快切回, 动态IP时慢速检查, 网络命令连续失败时逐渐延长间隔
 func (a *WailsApp) monitorNetwork() {
+	// 监听网络变化通知, 连接WiFi、插拔网线后立即检查; 不支持时只能定时检查
+	var changes <-chan struct{}
+	watcher := NewNetworkWatcher(DefaultWatchDebounce, DefaultWatchMaxDelay)
+	if err := watcher.Start(); err != nil {
+		log.Printf("监听网络变化失败: %v, 只定时检查", err)
+	} else {
+		a.watcher = watcher
+		changes = watcher.Changes()
+	}
+
 	// 延迟几秒，等待网络连接
-	time.Sleep(5 * time.Second)
-	
+	time.Sleep(time.Duration(a.config.Monitor.withDefaults(changes != nil).StartupDelay) * time.Second)
+
 	// 初始启动时要执行一次, 保证和当前配置文件一致
 	a.checkAndSwitch()
 
+	failures := 0
 	for {
-		if a.config.IPMode == "adaptive" {
-			a.checkAndSwitch()
+		policy := a.config.Monitor.withDefaults(changes != nil)
+		interval := policy.interval(a.onSideRouter(), failures)
+		if failures > 0 {
+			log.Printf("网络命令连续失败 %d 次, %s 后重试", failures, interval)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-changes:
+			log.Println("检测到网络变化, 立即检查")
+		case <-timer.C:
+		}
+		timer.Stop()
+
+		// 自适应模式的网卡需要定时检查;
+		// 动态IP+旁路由模式添加的路由在网卡重新连接后可能失效, 也需要定时检查
+		// 获取网卡或执行网络命令失败时退避; 切换后连通性检查失败不是命令失败, 不退避
+		managed, err := a.managedInterfaces()
+		failed := err != nil
+		for _, iface := range managed {
+			if iface.IPMode == "adaptive" || iface.IPMode == "override" {
+				if err := a.checkAndSwitchInterface(iface); err != nil && !errors.Is(err, ErrSwitchVerify) {
+					failed = true
+				}
+			}
+		}
+		if failed {
+			failures++
+		} else {
+			failures = 0
 		}
 		// 更新托盘tooltip以显示最新网络状态
 		a.updateTrayTooltip()
-		time.Sleep(30 * time.Second) // 每30秒检查一次
 	}
 }
 
-// checkAndSwitch 检查网络状态并切换配置
+// managedInterface 需要管理的网卡及其IP模式
+type managedInterface struct {
+	Name    string // 网卡名称
+	IPMode  string // IP模式: adaptive, dynamic, static, override
+	Profile string // 固定使用的网络配置名称, 为空时按规则匹配
+}
+
+// managedInterfaces 获取需要管理的网卡
+// 没有单独设置网卡时管理 InterfaceTypes 类型的活动网卡, 否则管理设置中已连接的网卡
+// 获取网卡失败时记录日志并返回错误
+func (a *WailsApp) managedInterfaces() ([]managedInterface, error) {
+	if len(a.config.Interfaces) == 0 {
+		iface, err := a.backend.GetActiveInterface(a.config.InterfaceTypes)
+		if err != nil {
+			log.Printf("获取网络接口失败: %v", err)
+			return nil, fmt.Errorf("获取网络接口失败: %v", err)
+		}
+		return []managedInterface{{Name: iface, IPMode: a.config.IPMode}}, nil
+	}
+
+	ifaces, err := a.backend.ListInterfaces()
+	if err != nil {
+		log.Printf("获取网卡列表失败: %v", err)
+		return nil, fmt.Errorf("获取网卡列表失败: %v", err)
+	}
+
+	var managed []managedInterface
+	for _, setting := range a.config.Interfaces {
+		iface := findInterface(ifaces, setting)
+		if iface == nil {
+			log.Printf("未找到网卡: %s %s", setting.Name, setting.MAC)
+			continue
+		}
+		if !iface.Connected {
+			log.Printf("网卡 %s 未连接, 跳过", iface.Name)
+			continue
+		}
+		mode := setting.IPMode
+		if mode == "" {
+			mode = a.config.IPMode
+		}
+		managed = append(managed, managedInterface{Name: iface.Name, IPMode: mode, Profile: setting.Profile})
+	}
+	return managed, nil
+}
+
+// primaryInterface 获取第一个需要管理的网卡, 用于界面和托盘显示
+func (a *WailsApp) primaryInterface() (managedInterface, error) {
+	managed, err := a.managedInterfaces()
+	if err != nil {
+		return managedInterface{}, err
+	}
+	if len(managed) == 0 {
+		return managedInterface{}, fmt.Errorf("未找到需要管理的网络接口")
+	}
+	return managed[0], nil
+}
+
+// checkAndSwitch 检查网络状态并切换所有管理网卡的配置
 func (a *WailsApp) checkAndSwitch() {
-	// 只有在自适应模式下才进行自动切换
-	switch mode := a.config.IPMode; mode {
+	managed, _ := a.managedInterfaces()
+	for _, iface := range managed {
+		a.checkAndSwitchInterface(iface)
+	}
+}
+
+// checkAndSwitchInterface 按网卡的IP模式检查网络状态并切换配置
+// 返回获取网络信息或切换配置时网络命令执行失败的错误
+func (a *WailsApp) checkAndSwitchInterface(iface managedInterface) error {
+	switch iface.IPMode {
 	case "adaptive":
-		// 连接到家庭局域网 且旁路由可达  设置静态IP
-		if a.isConnectedToHomeNetwork() && a.isSideRouterReachable() {
-			a.switchToStatic()
-		} else {
-			// 不是家庭局域网 或 旁路由不可达，切回动态IP
-			a.switchToDHCP()
+		profile, decision, err := a.evaluateProfiles(iface)
+		if err != nil {
+			// 不知道当前所在的网络, 不能当作离开了网络配置切回动态IP, 也不计入防抖
+			decision.Summary = append(decision.Summary, "结果: 获取网络信息失败, 保持当前配置")
+			a.recordDecision(decision)
+			return err
 		}
+
+		// 当前网络匹配某个网络配置 且该配置的旁路由可达  设置静态IP
+		if profile != nil {
+			decision.SideRouterChecked = true
+			decision.Health = a.checkSideRouterHealth(iface.Name, profile, decision.Facts)
+			decision.SideRouterOK = decision.Health.Healthy
+			decision.Summary = append(decision.Summary, decision.Health.describe(profile.Gateway)...)
+		}
+
+		// 旁路由不健康时, 设置了备用网关的配置保留静态IP只改用主路由网关
+		want := "dhcp"
+		if profile != nil && decision.SideRouterOK {
+			want = "static"
+		} else if profile != nil && profile.FallbackGateway != "" && !profile.keepsDHCPAddress() {
+			want = "fallback"
+		}
+		decision.Action = a.guardAction(iface.Name, decision, want)
+
+		switch decision.Action {
+		case "static":
+			if profile.splitRouting() {
+				decision.Summary = append(decision.Summary, fmt.Sprintf("结果: 保留动态IP, 按配置「%s」的分流目标经过旁路由", profile.Name))
+			} else if profile.Strategy == StrategyMetric {
+				decision.Summary = append(decision.Summary, fmt.Sprintf("结果: 保留动态IP, 添加经过配置「%s」旁路由的优先默认路由", profile.Name))
+			} else {
+				decision.Summary = append(decision.Summary, fmt.Sprintf("结果: 使用配置「%s」的静态IP", profile.Name))
+			}
+			a.recordDecision(decision)
+			err = a.switchToStatic(iface.Name, profile)
+		case "fallback":
+			decision.Summary = append(decision.Summary, fmt.Sprintf("结果: 保留配置「%s」的静态IP, 网关改用主路由 %s", profile.Name, profile.FallbackGateway))
+			a.recordDecision(decision)
+			err = a.switchToFallback(iface.Name, profile)
+		default:
+			// 没有匹配的网络配置 或 旁路由不可达，切回动态IP
+			decision.Summary = append(decision.Summary, "结果: 使用动态IP")
+			a.recordDecision(decision)
+			err = a.switchToDHCP(iface.Name)
+		}
+		if errors.Is(err, ErrSwitchRolledBack) {
+			a.guardRolledBack(iface.Name)
+		}
+		return err
 	case "static":
 		// 强制使用静态IP
-		a.switchToStatic()
+		return a.switchToStatic(iface.Name, a.staticProfile(iface))
 	case "dynamic":
 		// 强制使用动态IP
-		a.switchToDHCP()
+		return a.switchToDHCP(iface.Name)
+	case "override":
+		// 地址仍由主路由DHCP分配, 流量和DNS经过旁路由
+		return a.switchToOverride(iface.Name, a.staticProfile(iface))
 	}
+	return nil
 }
 
-// isConnectedToHomeNetwork 检查是否连接到家庭局域网
-func (a *WailsApp) isConnectedToHomeNetwork() bool {
-	// 执行命令获取当前WiFi信息
-	cmd := exec.Command("netsh", "wlan", "show", "interfaces")
-	hideCmdWindow(cmd)
-	output, err := cmd.Output()
+// candidateProfiles 网卡可以使用的网络配置: 固定了网络配置时只使用该配置, 否则使用全部配置
+func (a *WailsApp) candidateProfiles(iface managedInterface) []*Profile {
+	var profiles []*Profile
+	for i := range a.config.Profiles {
+		if iface.Profile == "" || a.config.Profiles[i].Name == iface.Profile {
+			profiles = append(profiles, &a.config.Profiles[i])
+		}
+	}
+	if len(profiles) == 0 && iface.Profile != "" {
+		log.Printf("网卡 %s 设置的网络配置「%s」不存在", iface.Name, iface.Profile)
+	}
+	return profiles
+}
+
+// matchCurrentProfile 查找与网卡当前网络匹配的网络配置, 没有匹配时返回nil
+func (a *WailsApp) matchCurrentProfile(iface managedInterface) *Profile {
+	profile, _, _ := a.evaluateProfiles(iface)
+	return profile
+}
+
+// evaluateProfiles 获取网卡的网络信息并按顺序匹配各网络配置的规则, 返回匹配到的配置和判断过程
+// 获取网络信息失败时返回错误, 位置服务被禁用不视为失败
+func (a *WailsApp) evaluateProfiles(iface managedInterface) (*Profile, *MatchDecision, error) {
+	decision := &MatchDecision{Time: time.Now().Format("2006-01-02 15:04:05"), Interface: iface.Name}
+
+	facts, err := a.backend.GetNetworkFacts(iface.Name)
 	if err != nil {
-		outputStr := string(output)
-		log.Printf("执行netsh命令失败: %v. %v", err, outputStr)
+		log.Printf("获取网络信息失败: %v", err)
+		decision.Summary = append(decision.Summary, fmt.Sprintf("获取网络信息失败: %v", err))
 
-		// 检查是否因为位置服务禁用导致无法获取SSID
-		if strings.Contains(outputStr, "命令需要位置权限才能访问") ||
-			strings.Contains(outputStr, "WlanQueryInterface 返回错误 5") ||
-			strings.Contains(outputStr, "拒绝访问") ||
-			strings.Contains(outputStr, "Network shell commands need location permission") {
-			log.Println("检测到位置服务被禁用，提示用户开启位置服务以获取WiFi信息")
-			a.promptUserToEnableLocationService()
+		// 位置服务被禁用时仍然可以使用SSID以外的规则判断
+		if !errors.Is(err, ErrLocationPermission) {
+			return nil, decision, err
 		}
-		return false
+		log.Println("检测到位置服务被禁用，提示用户开启位置服务以获取WiFi信息")
+		a.promptUserToEnableLocationService()
 	}
+	decision.Facts = facts
+	decision.Summary = append(decision.Summary, facts.describe())
+
+	dhcpFacts := a.rememberDHCPFacts(facts)
+
+	// 按顺序匹配各网络配置, 使用第一个匹配的配置
+	for _, profile := range a.candidateProfiles(iface) {
+		trace := MatchProfile(profile, facts, dhcpFacts)
+		decision.Traces = append(decision.Traces, trace)
+		decision.Summary = append(decision.Summary, trace.describe()...)
+		if trace.Matched {
+			decision.Profile = profile.Name
+			return profile, decision, nil
+		}
+	}
+	return nil, decision, nil
+}
 
-	// 将输出转换为字符串并按行分割
-	outputStr := string(output)
-	lines := strings.Split(outputStr, "\n")
-
-	// 查找包含SSID的行
-	for _, line := range lines {
-		// 查找包含"SSID"但不包含"BSSID"的行
-		if strings.Contains(line, "SSID") && !strings.Contains(line, "BSSID") {
-			// 提取SSID值
-			parts := strings.Split(line, ":")
-			if len(parts) >= 2 {
-				// 去除空格和换行符
-				currentSSID := strings.TrimSpace(parts[1])
-				// 比较当前SSID与配置中的HomeSSID
-				if currentSSID == a.config.HomeSSID {
-					return true
-				}
-			}
+// guardAction 按防抖设置决定网卡的实际动作, 切换过于频繁时暂停自动切换并通知用户
+func (a *WailsApp) guardAction(iface string, decision *MatchDecision, want string) string {
+	a.decisionMu.Lock()
+	guard := a.guards[iface]
+	if guard == nil {
+		guard = &SwitchGuard{}
+		a.guards[iface] = guard
+	}
+	action, notes, tripped := guard.Decide(a.config.Switching, want, decision.Profile, time.Now())
+	decision.Paused = guard.Tripped
+	a.decisionMu.Unlock()
+
+	decision.Summary = append(decision.Summary, notes...)
+	if tripped {
+		a.notifySwitchPaused(iface)
+	}
+	return action
+}
+
+// guardRolledBack 切换失败并恢复了切换前的配置后, 防抖状态回到恢复后的动作并计入切换次数
+// 否则下次检查仍按切换失败的动作判断, 会不断重复切换和恢复
+func (a *WailsApp) guardRolledBack(iface string) {
+	action := restoredAction(a.getActiveProfile(iface))
+	a.decisionMu.Lock()
+	guard := a.guards[iface]
+	tripped := guard != nil && guard.RolledBack(a.config.Switching, action, time.Now())
+	a.decisionMu.Unlock()
+
+	log.Printf("网卡 %s 切换失败, 防抖状态恢复为%s", iface, actionName(action))
+	if tripped {
+		a.notifySwitchPaused(iface)
+	}
+}
+
+// restoredAction 按网卡当前生效的网络配置名称判断自适应模式的动作
+func restoredAction(activeProfile string) string {
+	switch {
+	case activeProfile == "":
+		return "dhcp"
+	case strings.HasSuffix(activeProfile, "(主路由网关)"):
+		return "fallback"
+	default:
+		return "static"
+	}
+}
+
+// onSideRouter 是否有自适应模式的网卡正在使用旁路由
+func (a *WailsApp) onSideRouter() bool {
+	a.decisionMu.RLock()
+	defer a.decisionMu.RUnlock()
+
+	for _, guard := range a.guards {
+		if guard.Action == "static" {
+			return true
 		}
 	}
+	return false
+}
+
+// switchPaused 是否有网卡因切换过于频繁暂停了自动切换
+func (a *WailsApp) switchPaused() bool {
+	a.decisionMu.RLock()
+	defer a.decisionMu.RUnlock()
 
+	for _, guard := range a.guards {
+		if guard.Tripped {
+			return true
+		}
+	}
 	return false
 }
 
+// notifySwitchPaused 通知用户网卡的自动切换已暂停
+func (a *WailsApp) notifySwitchPaused(iface string) {
+	message := fmt.Sprintf(`网卡 %s 的旁路由状态反复变化，一小时内切换次数过多，已暂停自动切换。
+
+请检查旁路由是否稳定，之后在托盘菜单中重新选择「自适应IP」或保存配置即可恢复。`, iface)
+	log.Printf("网卡 %s 切换过于频繁, 已暂停自动切换", iface)
+
+	if a.app == nil {
+		return
+	}
+	if a.app.Event != nil {
+		go a.app.Event.Emit("switchPaused", iface)
+	}
+	dialog := a.app.Dialog.Warning()
+	dialog.SetTitle("已暂停自动切换")
+	dialog.SetMessage(message)
+	dialog.AddButton("确定")
+	dialog.Show()
+}
+
+// rememberDHCPFacts 记录DHCP状态下的网络信息, 返回该接口最近一次的记录
+func (a *WailsApp) rememberDHCPFacts(facts *NetworkFacts) *NetworkFacts {
+	a.decisionMu.Lock()
+	defer a.decisionMu.Unlock()
+
+	if facts.DHCP {
+		a.dhcpFacts[facts.Interface] = facts
+	}
+	return a.dhcpFacts[facts.Interface]
+}
+
+// recordDecision 记录并通知前端自适应模式的判断过程
+func (a *WailsApp) recordDecision(decision *MatchDecision) {
+	log.Printf("自适应模式判断过程(%s):", decision.Interface)
+	for _, line := range decision.Summary {
+		log.Println(line)
+	}
+
+	a.decisionMu.Lock()
+	a.lastDecisions[decision.Interface] = decision
+	a.decisionMu.Unlock()
+
+	if a.app != nil && a.app.Event != nil {
+		go a.app.Event.Emit("matchDecision", decision)
+	}
+}
+
+// staticProfile 静态IP模式和动态IP+旁路由模式使用的网络配置: 优先使用与当前网络匹配的配置, 否则使用第一个可用的配置
+func (a *WailsApp) staticProfile(iface managedInterface) *Profile {
+	if profile := a.matchCurrentProfile(iface); profile != nil {
+		return profile
+	}
+	if profiles := a.candidateProfiles(iface); len(profiles) > 0 {
+		return profiles[0]
+	}
+	return nil
+}
+
+// currentNetworkStatus 获取第一个管理网卡的详细状态
+func (a *WailsApp) currentNetworkStatus() (*NetworkStatus, error) {
+	iface, err := a.primaryInterface()
+	if err != nil {
+		return nil, err
+	}
+	status, err := a.backend.GetCurrentNetworkStatus(iface.Name)
+	if err != nil {
+		return nil, err
+	}
+	status.ActiveProfile = a.getActiveProfile(iface.Name)
+	a.profileMu.RLock()
+	if set, ok := a.splitRoutes[iface.Name]; ok {
+		status.SplitRoutes = append([]string(nil), set.Destinations...)
+		status.SplitGateway = set.Gateway
+	}
+	a.profileMu.RUnlock()
+	if ipv6, err := a.backend.GetIPv6Config(iface.Name); err == nil {
+		status.setIPv6(ipv6)
+	} else {
+		log.Printf("获取IPv6配置失败: %v", err)
+	}
+	return status, nil
+}
+
+// getActiveProfile 获取网卡当前生效的网络配置名称
+func (a *WailsApp) getActiveProfile(iface string) string {
+	a.profileMu.RLock()
+	defer a.profileMu.RUnlock()
+	return a.activeProfiles[iface]
+}
+
+// setActiveProfile 记录网卡当前生效的网络配置名称
+func (a *WailsApp) setActiveProfile(iface, name string) {
+	a.profileMu.Lock()
+	defer a.profileMu.Unlock()
+	if name == "" {
+		delete(a.activeProfiles, iface)
+		return
+	}
+	a.activeProfiles[iface] = name
+}
+
 // 添加一个全局变量来跟踪是否已经显示过弹窗
 var locationServicePromptShown = false
 
@@ -656,89 +1143,876 @@ func (a *WailsApp) promptUserToEnableLocationService() {
 			dialog.Show()
 
 			// 自动打开位置设置页面
-			cmd := exec.Command("cmd", "/C", "start", "ms-settings:privacy-location")
-			hideCmdWindow(cmd)
-			if err := cmd.Start(); err != nil {
+			if err := a.OpenLocationSettings(); err != nil {
 				log.Printf("打开位置设置页面失败: %v", err)
 			}
 		}
 	}
 }
 
-// isSideRouterReachable 检查旁路由是否可达
-func (a *WailsApp) isSideRouterReachable() bool {
-	// 使用系统ping命令检测旁路由地址是否可达
-	addr := a.config.Gateway
-	return Ping(addr)
+// isSideRouterReachable 检查网卡上网络配置中的旁路由是否健康
+func (a *WailsApp) isSideRouterReachable(iface string, profile *Profile) bool {
+	if profile == nil {
+		return false
+	}
+	return a.checkSideRouterHealth(iface, profile, nil).Healthy
 }
 
-// switchToStatic 切换到静态IP模式
-func (a *WailsApp) switchToStatic() {
-	log.Printf("开始切换静态IP")
+// checkSideRouterHealth 在网卡上执行网络配置的旁路由健康检查
+// facts 为网卡当前的网络信息, 用于判断当前网关是否已经是旁路由; 为nil时重新获取
+func (a *WailsApp) checkSideRouterHealth(iface string, profile *Profile, facts *NetworkFacts) *HealthResult {
+	if facts == nil {
+		var err error
+		if facts, err = a.backend.GetNetworkFacts(iface); facts == nil {
+			log.Printf("获取网卡 %s 当前网关失败: %v", iface, err)
+			facts = &NetworkFacts{}
+		}
+	}
+	return CheckHealth(profile, HealthEnv{Backend: a.backend, Interface: iface, Gateway: facts.Gateway})
+}
+
+// switchToStatic 将网卡切换到静态IP模式, 返回网络命令执行失败的错误
+func (a *WailsApp) switchToStatic(iface string, profile *Profile) error {
+	if profile == nil {
+		log.Println("没有可用的网络配置, 无法切换静态IP")
+		return nil
+	}
+	// 设置了分流的网络配置不修改IP, 只把分流目标的路由指向旁路由
+	if profile.splitRouting() {
+		return a.switchToSplit(iface, profile)
+	}
+	// 按跃点数切换的网络配置不修改IP, 只添加一条更优先的默认路由
+	if profile.Strategy == StrategyMetric {
+		return a.switchToMetric(iface, profile)
+	}
+	log.Printf("开始切换静态IP, 网卡: %s, 网络配置: %s", iface, profile.Name)
 
-	// 获取活动网络接口
-	iface, err := GetActiveInterface()
+	ip, mask, err := profile.staticAddress()
 	if err != nil {
-		log.Printf("获取网络接口失败: %v", err)
-		return
+		log.Printf("网络配置「%s」无效: %v", profile.Name, err)
+		return nil
 	}
 
 	// 检查当前是否已经是目标静态IP配置
-	isStatic, err := GetCurrentStaticIPConfig(iface, a.config.StaticIP, a.config.Gateway, a.config.DNS)
-	if err == nil && isStatic {
-		log.Printf("当前已经是目标静态IP配置, 无需重复设置: IP=%s, Gateway=%s, DNS=%s\n", a.config.StaticIP, a.config.Gateway, a.config.DNS)
-		return
+	isStatic, err := a.backend.GetCurrentStaticIPConfig(iface, ip, mask, profile.Gateway, profile.DNS)
+	applied := err == nil && isStatic
+	if applied && !a.ipv6Pending(iface, profile) {
+		a.setActiveProfile(iface, profile.Name)
+		log.Printf("当前已经是目标静态IP配置, 无需重复设置: IP=%s, Mask=%s, Gateway=%s, DNS=%s\n", ip, mask, profile.Gateway, strings.Join(profile.DNS, ","))
+		return nil
 	}
 
-	// 设置静态IP (这里使用默认子网掩码 255.255.255.0)
-	err = SetStaticIP(iface, a.config.StaticIP, "255.255.255.0", a.config.Gateway, a.config.DNS)
+	err = a.switchTransaction(iface, profile.Name, profile.Gateway, func() error {
+		if !applied {
+			if err := a.clearSideRouterRoutes(iface); err != nil {
+				return err
+			}
+			if err := a.backend.SetStaticIP(iface, ip, mask, profile.Gateway, profile.DNS); err != nil {
+				return err
+			}
+		}
+		a.setActiveProfile(iface, profile.Name)
+		return a.applyIPv6(iface, profile)
+	})
 	if err != nil {
 		log.Printf("设置静态IP失败: %v", err)
-		return
+		return err
+	}
+	log.Printf("成功切换到静态IP模式: IP=%s, Mask=%s, Gateway=%s, DNS=%s\n", ip, mask, profile.Gateway, strings.Join(profile.DNS, ","))
+	return nil
+}
+
+// switchToFallback 保留网络配置的静态IP, 只把网关和DNS改为主路由, 返回网络命令执行失败的错误
+// 端口转发、共享文件夹映射等依赖本机IP的服务不受影响
+func (a *WailsApp) switchToFallback(iface string, profile *Profile) error {
+	log.Printf("开始切换到主路由网关, 网卡: %s, 网络配置: %s", iface, profile.Name)
+
+	ip, mask, err := profile.staticAddress()
+	if err != nil {
+		log.Printf("网络配置「%s」无效: %v", profile.Name, err)
+		return nil
+	}
+	// 状态中显示当前使用的是主路由网关
+	activeName := profile.Name + "(主路由网关)"
+	dns := profile.fallbackDNS()
+
+	isStatic, err := a.backend.GetCurrentStaticIPConfig(iface, ip, mask, profile.FallbackGateway, dns)
+	applied := err == nil && isStatic
+	if applied && !a.fallbackIPv6Pending(iface, profile) {
+		a.setActiveProfile(iface, activeName)
+		log.Printf("当前已经是主路由网关配置, 无需重复设置: IP=%s, Gateway=%s", ip, profile.FallbackGateway)
+		return nil
+	}
+
+	err = a.switchTransaction(iface, activeName, profile.FallbackGateway, func() error {
+		if !applied {
+			if err := a.clearSideRouterRoutes(iface); err != nil {
+				return err
+			}
+			if err := a.backend.SetStaticIP(iface, ip, mask, profile.FallbackGateway, dns); err != nil {
+				return err
+			}
+		}
+		a.setActiveProfile(iface, activeName)
+		return a.applyFallbackIPv6(iface, profile)
+	})
+	if err != nil {
+		log.Printf("切换到主路由网关失败: %v", err)
+		return err
+	}
+	log.Printf("成功切换到主路由网关: IP=%s, Mask=%s, Gateway=%s, DNS=%s\n", ip, mask, profile.FallbackGateway, strings.Join(dns, ","))
+	return nil
+}
+
+// fallbackIPv6Pending 切换到主路由网关时IPv6是否还需要处理
+// 静态IPv6的网关是旁路由, 仍有手动设置的IPv6时需要恢复自动获取; 停用IPv6的配置保持停用
+func (a *WailsApp) fallbackIPv6Pending(iface string, profile *Profile) bool {
+	switch profile.IPv6Mode {
+	case IPv6Static:
+		if a.getIPv6Mode(iface) != "" {
+			return true
+		}
+		current, err := a.backend.GetIPv6Config(iface)
+		return err != nil || hasManualIPv6(current)
+	case IPv6Disable:
+		return a.ipv6Pending(iface, profile)
+	default:
+		return false
 	}
+}
 
-	log.Printf("成功切换到静态IP模式: IP=%s, Gateway=%s, DNS=%s\n", a.config.StaticIP, a.config.Gateway, a.config.DNS)
+// applyFallbackIPv6 切换到主路由网关时处理IPv6: 静态IPv6改为自动获取主路由下发的配置, 停用IPv6的配置保持停用
+func (a *WailsApp) applyFallbackIPv6(iface string, profile *Profile) error {
+	if !a.fallbackIPv6Pending(iface, profile) {
+		return nil
+	}
+	if profile.IPv6Mode == IPv6Static {
+		return a.resetIPv6(iface)
+	}
+	return a.applyIPv6(iface, profile)
 }
 
-// switchToDHCP 切换到自动获取IP模式
-func (a *WailsApp) switchToDHCP() {
-	log.Println("开始切换动态IP")
+// applyIPv6 按网络配置处理IPv6, 避免切换到旁路由后仍然经由主路由的IPv6访问网络和解析域名
+// 在切换事务中调用, 设置失败时返回错误, 由事务恢复切换前的配置
+func (a *WailsApp) applyIPv6(iface string, profile *Profile) error {
+	if !a.ipv6Pending(iface, profile) {
+		return nil
+	}
 
-	// 获取活动网络接口
-	iface, err := GetActiveInterface()
+	var err error
+	if profile.IPv6Mode == IPv6Static {
+		err = a.backend.SetStaticIPv6(iface, profile.staticIPv6(), profile.GatewayIPv6, profile.DNSv6)
+	} else {
+		err = a.backend.DisableIPv6Auto(iface)
+	}
 	if err != nil {
-		log.Printf("获取网络接口失败: %v", err)
+		return fmt.Errorf("设置IPv6失败: %v", err)
+	}
+	a.setIPv6Mode(iface, profile.IPv6Mode)
+	log.Printf("成功设置IPv6: 网卡 %s, 方式 %s", iface, profile.IPv6Mode)
+	return nil
+}
+
+// ipv6Pending 网卡当前的IPv6配置是否还不符合网络配置的要求, 获取失败时视为不符合
+func (a *WailsApp) ipv6Pending(iface string, profile *Profile) bool {
+	if profile.IPv6Mode == IPv6Keep {
+		return false
+	}
+	current, err := a.backend.GetIPv6Config(iface)
+	return err != nil || !ipv6Applied(current, profile)
+}
+
+// resetIPv6 恢复网卡自动获取IPv6配置
+func (a *WailsApp) resetIPv6(iface string) error {
+	if err := a.backend.ResetIPv6(iface); err != nil {
+		return fmt.Errorf("恢复IPv6自动配置失败: %v", err)
+	}
+	a.setIPv6Mode(iface, IPv6Keep)
+	return nil
+}
+
+// getIPv6Mode 获取程序为网卡设置的IPv6处理方式, 没有设置过或已恢复自动获取时为空
+func (a *WailsApp) getIPv6Mode(iface string) string {
+	a.profileMu.RLock()
+	defer a.profileMu.RUnlock()
+	return a.ipv6Modes[iface]
+}
+
+// setIPv6Mode 记录程序为网卡设置的IPv6处理方式, mode 为空时删除记录
+func (a *WailsApp) setIPv6Mode(iface, mode string) {
+	a.profileMu.Lock()
+	defer a.profileMu.Unlock()
+	if mode == IPv6Keep {
+		delete(a.ipv6Modes, iface)
 		return
 	}
+	a.ipv6Modes[iface] = mode
+}
+
+// managesIPv6 是否有网络配置设置了IPv6处理方式, 没有时切换到DHCP也不改动IPv6
+func (a *WailsApp) managesIPv6() bool {
+	for _, profile := range a.config.Profiles {
+		if profile.IPv6Mode != IPv6Keep {
+			return true
+		}
+	}
+	return false
+}
+
+// switchToDHCP 将网卡切换到自动获取IP模式, 返回网络命令执行失败的错误
+func (a *WailsApp) switchToDHCP(iface string) error {
+	log.Printf("开始切换动态IP, 网卡: %s", iface)
 
 	// 检查当前是否已经是DHCP模式
-	isDHCP, err := GetCurrentIPConfig(iface)
-	if err == nil && isDHCP {
+	// 动态IP+旁路由、分流和按跃点数切换同样是DHCP地址, 还需要删除经过旁路由的路由
+	isDHCP, err := a.backend.GetCurrentIPConfig(iface)
+	if err == nil && isDHCP && !a.hasSideRouterRoutes(iface) {
+		a.setActiveProfile(iface, "")
 		log.Println("当前已经是DHCP模式, 无需重复设置")
-		return
+		return nil
 	}
 
-	// 设置为DHCP
-	err = SetDHCP(iface)
+	// 新网关由DHCP分配, 切换后检查DHCP下发的网关
+	err = a.switchTransaction(iface, "动态IP", "", func() error {
+		if err := a.clearSideRouterRoutes(iface); err != nil {
+			return err
+		}
+		if isDHCP, err := a.backend.GetCurrentIPConfig(iface); err != nil || !isDHCP {
+			if err := a.backend.SetDHCP(iface); err != nil {
+				return err
+			}
+		}
+		a.setActiveProfile(iface, "")
+
+		// 恢复自动获取IPv6配置
+		if a.managesIPv6() {
+			return a.resetIPv6(iface)
+		}
+		return nil
+	})
 	if err != nil {
 		log.Printf("设置DHCP失败: %v", err)
+		return err
+	}
+	log.Println("成功切换到DHCP模式")
+	return nil
+}
+
+// switchToOverride 保留主路由DHCP分配的地址, 把默认路由和DNS改为网络配置的旁路由, 返回网络命令执行失败的错误
+// 主路由的DHCP租约列表和地址保留仍然有效
+func (a *WailsApp) switchToOverride(iface string, profile *Profile) error {
+	if profile == nil {
+		log.Println("没有可用的网络配置, 无法覆盖默认路由")
+		return nil
+	}
+	log.Printf("开始切换到动态IP+旁路由, 网卡: %s, 网络配置: %s", iface, profile.Name)
+	activeName := profile.Name + "(动态IP+旁路由)"
+
+	// 检查当前是否已经是DHCP地址且默认路由经过旁路由, 路由存在时认为DNS也已设置
+	applied := false
+	isDHCP, err := a.backend.GetCurrentIPConfig(iface)
+	if err == nil && isDHCP {
+		gateway, err := a.backend.GetRouteOverride(iface)
+		applied = err == nil && gateway == profile.Gateway
+	}
+	if applied && !a.ipv6Pending(iface, profile) {
+		a.setActiveProfile(iface, activeName)
+		log.Printf("当前已经是动态IP+旁路由配置, 无需重复设置: Gateway=%s", profile.Gateway)
+		return nil
+	}
+
+	err = a.switchTransaction(iface, activeName, profile.Gateway, func() error {
+		if !applied {
+			a.clearSplitRoutes(iface)
+			if err := a.clearPreferredGateway(iface); err != nil {
+				return err
+			}
+			if err := a.backend.SetRouteOverride(iface, profile.Gateway, profile.DNS); err != nil {
+				return err
+			}
+		}
+		a.setActiveProfile(iface, activeName)
+		return a.applyIPv6(iface, profile)
+	})
+	if err != nil {
+		log.Printf("切换到动态IP+旁路由失败: %v", err)
+		return err
+	}
+	log.Printf("成功切换到动态IP+旁路由: Gateway=%s, DNS=%s\n", profile.Gateway, strings.Join(profile.DNS, ","))
+	return nil
+}
+
+// switchToSplit 保留DHCP地址, 只把网络配置的分流目标路由到旁路由, 返回网络命令执行失败的错误
+func (a *WailsApp) switchToSplit(iface string, profile *Profile) error {
+	routes, err := profile.splitRoutes()
+	if err != nil {
+		log.Printf("网络配置「%s」的分流目标无效: %v", profile.Name, err)
+		return nil
+	}
+	log.Printf("开始切换到分流, 网卡: %s, 网络配置: %s, 分流目标 %d 个", iface, profile.Name, len(routes))
+
+	activeName := profile.Name + "(分流)"
+
+	// 检查当前是否已经是DHCP地址且分流路由都已添加
+	isDHCP, err := a.backend.GetCurrentIPConfig(iface)
+	applied := err == nil && isDHCP && a.splitRoutesApplied(iface, profile.Gateway, routes)
+	if applied && !a.ipv6Pending(iface, profile) {
+		a.setActiveProfile(iface, activeName)
+		return nil
+	}
+
+	err = a.switchTransaction(iface, activeName, profile.Gateway, func() error {
+		if !applied {
+			if err := a.clearRouteOverride(iface); err != nil {
+				return err
+			}
+			if err := a.clearPreferredGateway(iface); err != nil {
+				return err
+			}
+			if isDHCP, err := a.backend.GetCurrentIPConfig(iface); err != nil || !isDHCP {
+				if err := a.backend.SetDHCP(iface); err != nil {
+					return err
+				}
+			}
+			if err := a.syncSplitRoutes(iface, profile.Gateway, routes); err != nil {
+				return err
+			}
+		}
+		a.setActiveProfile(iface, activeName)
+		return a.applyIPv6(iface, profile)
+	})
+	if err != nil {
+		log.Printf("切换到分流失败: %v", err)
+		return err
+	}
+	return nil
+}
+
+// splitRoutesApplied 网卡上是否已经只有目标分流路由经过旁路由, 没有覆盖默认路由和优先默认路由
+func (a *WailsApp) splitRoutesApplied(iface, gateway string, destinations []string) bool {
+	a.profileMu.RLock()
+	previous, ok := a.splitRoutes[iface]
+	a.profileMu.RUnlock()
+	if !ok || previous.Gateway != gateway || len(previous.Destinations) != len(destinations) {
+		return false
+	}
+
+	current, err := a.backend.ListRoutes(iface)
+	if err != nil {
+		log.Printf("获取路由失败: %v", err)
+		return false
+	}
+	for i, destination := range destinations {
+		if previous.Destinations[i] != destination || !hasRoute(current, destination, gateway) {
+			return false
+		}
+	}
+	if gateway, err := a.backend.GetRouteOverride(iface); err != nil || gateway != "" {
+		return false
+	}
+	return a.addedPreferredGateway(iface) == ""
+}
+
+// syncSplitRoutes 使网卡上的分流路由与目标一致
+// 删除不再需要的路由, 添加缺少的路由(如网卡重新连接后失效的路由)
+func (a *WailsApp) syncSplitRoutes(iface, gateway string, destinations []string) error {
+	current, err := a.backend.ListRoutes(iface)
+	if err != nil {
+		log.Printf("获取路由失败: %v", err)
+		return err
+	}
+
+	a.profileMu.RLock()
+	previous := a.splitRoutes[iface]
+	a.profileMu.RUnlock()
+
+	wanted := make(map[string]bool, len(destinations))
+	for _, destination := range destinations {
+		wanted[destination] = true
+	}
+	for _, destination := range previous.Destinations {
+		if wanted[destination] && previous.Gateway == gateway {
+			continue
+		}
+		if hasRoute(current, destination, previous.Gateway) {
+			if err := a.backend.DeleteRoute(iface, destination, previous.Gateway); err != nil {
+				log.Printf("删除分流路由失败: %v", err)
+			}
+		}
+	}
+
+	// 部分路由添加失败时仍然记录全部目标, 下次检查时重试
+	var firstErr error
+	added := 0
+	for _, destination := range destinations {
+		if hasRoute(current, destination, gateway) {
+			continue
+		}
+		if err := a.backend.AddRoute(iface, destination, gateway); err != nil {
+			log.Printf("添加分流路由失败: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		added++
+	}
+
+	a.profileMu.Lock()
+	a.splitRoutes[iface] = splitRouteSet{Gateway: gateway, Destinations: destinations}
+	a.profileMu.Unlock()
+
+	if added > 0 {
+		log.Printf("已添加 %d 条经过 %s 的分流路由", added, gateway)
+	}
+	return firstErr
+}
+
+// clearSplitRoutes 离开旁路由时删除网卡上已添加的分流路由
+func (a *WailsApp) clearSplitRoutes(iface string) {
+	a.profileMu.Lock()
+	set, ok := a.splitRoutes[iface]
+	delete(a.splitRoutes, iface)
+	a.profileMu.Unlock()
+	if !ok {
 		return
 	}
 
-	log.Println("成功切换到DHCP模式")
+	for _, destination := range set.Destinations {
+		// 网卡重新连接后路由可能已经失效, 删除失败只记录日志
+		if err := a.backend.DeleteRoute(iface, destination, set.Gateway); err != nil {
+			log.Printf("删除分流路由失败: %v", err)
+		}
+	}
+	log.Printf("已删除网卡 %s 经过 %s 的分流路由", iface, set.Gateway)
+}
+
+// clearAllSplitRoutes 删除所有网卡上已添加的分流路由
+func (a *WailsApp) clearAllSplitRoutes() {
+	a.profileMu.RLock()
+	ifaces := make([]string, 0, len(a.splitRoutes))
+	for iface := range a.splitRoutes {
+		ifaces = append(ifaces, iface)
+	}
+	a.profileMu.RUnlock()
+
+	for _, iface := range ifaces {
+		a.clearSplitRoutes(iface)
+	}
+}
+
+// switchToMetric 保留DHCP地址, 添加一条经过旁路由、跃点数更小的默认路由并设置DNS, 返回网络命令执行失败的错误
+// 在旁路由和主路由之间切换只改路由, DHCP租约和已有的TCP连接不受影响
+func (a *WailsApp) switchToMetric(iface string, profile *Profile) error {
+	log.Printf("开始按跃点数切换到旁路由, 网卡: %s, 网络配置: %s", iface, profile.Name)
+	activeName := profile.Name + "(跃点)"
+
+	// 检查当前是否已经是DHCP地址且旁路由的默认路由最优先, 路由存在时认为DNS也已设置
+	applied := false
+	isDHCP, err := a.backend.GetCurrentIPConfig(iface)
+	if err == nil && isDHCP {
+		routes, err := a.backend.ListRoutes(iface)
+		applied = err == nil && preferredGateway(routes) == profile.Gateway
+	}
+	if applied && !a.ipv6Pending(iface, profile) {
+		a.setActiveProfile(iface, activeName)
+		log.Printf("旁路由的默认路由已经最优先, 无需重复设置: Gateway=%s", profile.Gateway)
+		return nil
+	}
+
+	err = a.switchTransaction(iface, activeName, profile.Gateway, func() error {
+		if !applied {
+			a.clearSplitRoutes(iface)
+			if err := a.clearRouteOverride(iface); err != nil {
+				return err
+			}
+			a.rememberDefaultRoutes(iface)
+			if err := a.backend.SetPreferredGateway(iface, profile.Gateway, profile.DNS); err != nil {
+				return err
+			}
+		}
+		a.setActiveProfile(iface, activeName)
+		return a.applyIPv6(iface, profile)
+	})
+	if err != nil {
+		log.Printf("添加旁路由默认路由失败: %v", err)
+		return err
+	}
+	log.Printf("成功按跃点数切换到旁路由: Gateway=%s, DNS=%s\n", profile.Gateway, strings.Join(profile.DNS, ","))
+	return nil
+}
+
+// clearPreferredGateway 离开旁路由时删除按跃点数切换添加的默认路由, 恢复其他默认路由的跃点数和DHCP下发的DNS
+// 只在有网络配置使用按跃点数切换时检查路由表; 按路由表判断, 程序重启后仍然能删除, 但不再知道原来的跃点数
+func (a *WailsApp) clearPreferredGateway(iface string) error {
+	gateway := a.addedPreferredGateway(iface)
+	if gateway == "" {
+		return nil
+	}
+	if err := a.backend.ClearPreferredGateway(iface, gateway, a.getDefaultRoutes(iface)); err != nil {
+		log.Printf("删除旁路由默认路由失败: %v", err)
+		return err
+	}
+	a.setDefaultRoutes(iface, nil)
+	log.Printf("已删除经过 %s 的优先默认路由", gateway)
+	return nil
+}
+
+// rememberDefaultRoutes 添加优先默认路由前记录网卡上的默认路由和跃点数, 删除优先默认路由时按它恢复
+// 已有优先默认路由时其他默认路由的跃点数可能已被调整, 保留之前的记录
+func (a *WailsApp) rememberDefaultRoutes(iface string) {
+	if a.addedPreferredGateway(iface) != "" {
+		return
+	}
+	routes, err := a.backend.ListRoutes(iface)
+	if err != nil {
+		log.Printf("获取路由失败: %v", err)
+		return
+	}
+	var defaults []Route
+	for _, route := range routes {
+		if route.Destination == "0.0.0.0/0" {
+			defaults = append(defaults, route)
+		}
+	}
+	a.setDefaultRoutes(iface, defaults)
+}
+
+// getDefaultRoutes 获取网卡添加优先默认路由前的默认路由, 没有记录时返回nil
+func (a *WailsApp) getDefaultRoutes(iface string) []Route {
+	a.profileMu.RLock()
+	defer a.profileMu.RUnlock()
+	return append([]Route(nil), a.defaultRoutes[iface]...)
+}
+
+// setDefaultRoutes 记录网卡添加优先默认路由前的默认路由, routes 为空时删除记录
+func (a *WailsApp) setDefaultRoutes(iface string, routes []Route) {
+	a.profileMu.Lock()
+	defer a.profileMu.Unlock()
+	if len(routes) == 0 {
+		delete(a.defaultRoutes, iface)
+		return
+	}
+	a.defaultRoutes[iface] = append([]Route(nil), routes...)
+}
+
+// addedPreferredGateway 获取按跃点数切换添加的优先默认路由经过的旁路由, 没有时返回空
+func (a *WailsApp) addedPreferredGateway(iface string) string {
+	gateways := make(map[string]bool)
+	for _, profile := range a.config.Profiles {
+		if profile.Strategy == StrategyMetric {
+			gateways[profile.Gateway] = true
+		}
+	}
+	if len(gateways) == 0 {
+		return ""
+	}
+
+	routes, err := a.backend.ListRoutes(iface)
+	if err != nil {
+		log.Printf("获取路由失败: %v", err)
+		return ""
+	}
+	if gateway := preferredGateway(routes); gateways[gateway] {
+		return gateway
+	}
+	return ""
+}
+
+// clearRouteOverride 离开动态IP+旁路由模式时删除覆盖默认路由的路由并恢复DHCP下发的DNS
+func (a *WailsApp) clearRouteOverride(iface string) error {
+	gateway, err := a.backend.GetRouteOverride(iface)
+	if err != nil {
+		log.Printf("获取覆盖默认路由失败: %v", err)
+		return nil
+	}
+	if gateway == "" {
+		return nil
+	}
+	if err := a.backend.ClearRouteOverride(iface); err != nil {
+		log.Printf("删除覆盖默认路由失败: %v", err)
+		return err
+	}
+	log.Printf("已删除经过 %s 的覆盖默认路由", gateway)
+	return nil
+}
+
+// clearSideRouterRoutes 删除分流路由、优先默认路由和覆盖默认路由, 离开旁路由或改用静态IP前调用
+func (a *WailsApp) clearSideRouterRoutes(iface string) error {
+	a.clearSplitRoutes(iface)
+	if err := a.clearPreferredGateway(iface); err != nil {
+		return err
+	}
+	return a.clearRouteOverride(iface)
+}
+
+// hasSideRouterRoutes 网卡上是否有经过旁路由的分流路由、优先默认路由或覆盖默认路由
+func (a *WailsApp) hasSideRouterRoutes(iface string) bool {
+	override, preferred, split := a.sideRouterRoutes(iface)
+	return override != "" || preferred != "" || split != nil
+}
+
+// sideRouterRoutes 获取网卡上覆盖默认路由和优先默认路由经过的旁路由, 以及已添加的分流路由
+func (a *WailsApp) sideRouterRoutes(iface string) (override, preferred string, split *splitRouteSet) {
+	if gateway, err := a.backend.GetRouteOverride(iface); err == nil {
+		override = gateway
+	} else {
+		log.Printf("获取覆盖默认路由失败: %v", err)
+	}
+	preferred = a.addedPreferredGateway(iface)
+
+	a.profileMu.RLock()
+	if set, ok := a.splitRoutes[iface]; ok {
+		split = &splitRouteSet{Gateway: set.Gateway, Destinations: append([]string(nil), set.Destinations...)}
+	}
+	a.profileMu.RUnlock()
+	return override, preferred, split
+}
+
+// switchSnapshot 切换前网卡的配置, 切换失败时按它恢复
+type switchSnapshot struct {
+	IPv4      *IPv4Config    // IPv4地址、网关和DNS
+	Override  string         // 覆盖默认路由经过的旁路由, 没有时为空
+	Preferred string         // 按跃点数切换添加的优先默认路由经过的旁路由, 没有时为空
+	Defaults  []Route        // 添加优先默认路由前网卡上的默认路由和跃点数, 没有记录时为nil
+	Split     *splitRouteSet // 已添加的分流路由, 没有时为nil
+	IPv6      *IPv6Config    // IPv6地址、网关和DNS, 没有网络配置处理IPv6时为nil
+	IPv6Mode  string         // IPv6处理方式: static, disable, 为空时为自动获取
+	Profile   string         // 当前生效的网络配置名称
+	Reachable bool           // 切换前实际使用的网关是否可达
+}
+
+// takeSnapshot 保存网卡切换前的配置, 并检查切换前实际使用的网关是否可达
+func (a *WailsApp) takeSnapshot(iface string) (*switchSnapshot, error) {
+	ipv4, err := a.backend.GetIPv4Config(iface)
+	if err != nil {
+		return nil, fmt.Errorf("获取IPv4配置失败: %v", err)
+	}
+	snapshot := &switchSnapshot{IPv4: ipv4, Profile: a.getActiveProfile(iface)}
+	snapshot.Override, snapshot.Preferred, snapshot.Split = a.sideRouterRoutes(iface)
+	snapshot.Defaults = a.getDefaultRoutes(iface)
+	if a.managesIPv6() {
+		if ipv6, err := a.backend.GetIPv6Config(iface); err == nil {
+			snapshot.IPv6, snapshot.IPv6Mode = ipv6, a.getIPv6Mode(iface)
+			if snapshot.IPv6Mode == IPv6Keep && hasManualIPv6(ipv6) {
+				snapshot.IPv6Mode = IPv6Static
+			}
+		} else {
+			log.Printf("获取IPv6配置失败, 切换失败时不恢复IPv6: %v", err)
+		}
+	}
+
+	gateway := ipv4.Gateway
+	if snapshot.Override != "" {
+		gateway = snapshot.Override
+	} else if snapshot.Preferred != "" {
+		gateway = snapshot.Preferred
+	}
+	snapshot.Reachable = gateway != "" && a.backend.Ping(gateway)
+	return snapshot, nil
+}
+
+// restoreSnapshot 恢复切换前的配置
+// 先删除经过旁路由的路由, 再恢复地址和DNS, 然后重新添加切换前经过旁路由的路由, 最后恢复IPv6; 某一步失败时继续执行后续步骤
+func (a *WailsApp) restoreSnapshot(iface string, snapshot *switchSnapshot) error {
+	var failures []string
+	fail := func(err error) {
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	fail(a.clearSideRouterRoutes(iface))
+	ipv4 := snapshot.IPv4
+	if !ipv4.DHCP {
+		// DNS为空时同样按空列表设置, 不使用任何DNS服务器
+		fail(a.backend.SetStaticIP(iface, ipv4.IP, ipv4.SubnetMask, ipv4.Gateway, ipv4.DNS))
+	} else {
+		if isDHCP, err := a.backend.GetCurrentIPConfig(iface); err != nil || !isDHCP {
+			fail(a.backend.SetDHCP(iface))
+		}
+		switch {
+		case snapshot.Override != "":
+			fail(a.backend.SetRouteOverride(iface, snapshot.Override, ipv4.DNS))
+		case snapshot.Preferred != "":
+			a.setDefaultRoutes(iface, snapshot.Defaults)
+			fail(a.backend.SetPreferredGateway(iface, snapshot.Preferred, ipv4.DNS))
+		case ipv4.StaticDNS:
+			// DHCP地址上手动设置的DNS在切换到DHCP或删除旁路由的路由时被恢复为DHCP下发, 重新设置
+			fail(a.backend.SetDNS(iface, ipv4.DNS))
+		}
+		if snapshot.Split != nil {
+			fail(a.syncSplitRoutes(iface, snapshot.Split.Gateway, snapshot.Split.Destinations))
+		}
+	}
+	if snapshot.IPv6 != nil {
+		fail(a.restoreIPv6(iface, snapshot))
+	}
+	a.setActiveProfile(iface, snapshot.Profile)
+
+	if len(failures) > 0 {
+		return fmt.Errorf("恢复切换前的配置失败: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// restoreIPv6 按切换前的处理方式恢复IPv6: 静态IPv6重新设置手动地址、网关和DNS, 停用的保持停用, 其余恢复自动获取
+// 处理方式没有变化且不是静态IPv6时切换没有改动IPv6, 不再设置
+func (a *WailsApp) restoreIPv6(iface string, snapshot *switchSnapshot) error {
+	mode := snapshot.IPv6Mode
+	if mode != IPv6Static && a.getIPv6Mode(iface) == mode {
+		return nil
+	}
+
+	var err error
+	switch mode {
+	case IPv6Static:
+		address, gateway := "", ""
+		for _, addr := range snapshot.IPv6.Addresses {
+			if addr.Manual {
+				address = fmt.Sprintf("%s/%d", addr.IP, addr.PrefixLen)
+				break
+			}
+		}
+		if len(snapshot.IPv6.Gateways) > 0 {
+			gateway = snapshot.IPv6.Gateways[0]
+		}
+		if address == "" {
+			return a.resetIPv6(iface)
+		}
+		err = a.backend.SetStaticIPv6(iface, address, gateway, snapshot.IPv6.DNSServers)
+	case IPv6Disable:
+		err = a.backend.DisableIPv6Auto(iface)
+	default:
+		return a.resetIPv6(iface)
+	}
+	if err != nil {
+		return fmt.Errorf("恢复IPv6配置失败: %v", err)
+	}
+	a.setIPv6Mode(iface, mode)
+	return nil
+}
+
+// verifySwitch 切换后检查新网关的连通性, 在设置的时间内每秒检查一次
+// gateway 为空时检查DHCP下发的网关
+func (a *WailsApp) verifySwitch(iface, gateway string) error {
+	seconds := a.config.Switching.withDefaults().VerifySeconds
+	deadline := time.Now().Add(time.Duration(seconds) * time.Second)
+	for {
+		target := gateway
+		if target == "" {
+			if facts, _ := a.backend.GetNetworkFacts(iface); facts != nil {
+				target = facts.Gateway
+			}
+		}
+		if target != "" && a.backend.Ping(target) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if target == "" {
+				return fmt.Errorf("%d秒内没有获取到DHCP下发的网关", seconds)
+			}
+			return fmt.Errorf("%d秒内网关 %s 不可达", seconds, target)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// ErrSwitchVerify 切换后新网关在设置的时间内不可达
+var ErrSwitchVerify = errors.New("切换后连通性检查失败")
+
+// ErrSwitchRolledBack 切换失败并已恢复切换前的配置
+var ErrSwitchRolledBack = errors.New("已恢复切换前的配置")
+
+// switchTransaction 保存切换前的配置, 执行 apply 中的切换步骤并检查新网关 gateway 的连通性
+// 任一步骤失败, 或切换后网关不可达而切换前的网关可达时, 恢复切换前的配置并返回错误, 恢复成功时错误包含 ErrSwitchRolledBack;
+// 切换前的网关同样不可达时保留新配置, 只记录检查失败。切换结果通知前端
+// 定时检查、保存配置后的检查和前端操作可能同时切换同一网卡, 同一网卡的事务依次执行
+func (a *WailsApp) switchTransaction(iface, target, gateway string, apply func() error) error {
+	lock := a.interfaceLock(iface)
+	lock.Lock()
+	defer lock.Unlock()
+
+	result := &SwitchResult{Time: time.Now().Format("2006-01-02 15:04:05"), Interface: iface, Target: target}
+	defer a.recordSwitchResult(result)
+
+	snapshot, err := a.takeSnapshot(iface)
+	if err != nil {
+		log.Printf("保存网卡 %s 切换前的配置失败, 切换失败时无法恢复: %v", iface, err)
+	}
+
+	err = apply()
+	if err == nil {
+		verifyErr := a.verifySwitch(iface, gateway)
+		if verifyErr == nil {
+			result.Success = true
+			return nil
+		}
+		if snapshot == nil || !snapshot.Reachable {
+			result.Error = fmt.Sprintf("切换后连通性检查失败: %v, 切换前的网关同样不可达, 保留新配置", verifyErr)
+			return nil
+		}
+		err = fmt.Errorf("%w: %v", ErrSwitchVerify, verifyErr)
+	}
+	result.Error = err.Error()
+	if snapshot == nil {
+		return err
+	}
+
+	log.Printf("网卡 %s 切换到「%s」失败, 恢复切换前的配置", iface, target)
+	if rollbackErr := a.restoreSnapshot(iface, snapshot); rollbackErr != nil {
+		result.RollbackError = rollbackErr.Error()
+		return err
+	}
+	result.RolledBack = true
+	return fmt.Errorf("%w: %w", ErrSwitchRolledBack, err)
+}
+
+// interfaceLock 获取网卡的切换锁, 第一次使用时创建
+func (a *WailsApp) interfaceLock(iface string) *sync.Mutex {
+	a.switchMu.Lock()
+	defer a.switchMu.Unlock()
+	lock, ok := a.switchLocks[iface]
+	if !ok {
+		lock = &sync.Mutex{}
+		a.switchLocks[iface] = lock
+	}
+	return lock
+}
+
+// recordSwitchResult 记录并通知前端切换的结果
+func (a *WailsApp) recordSwitchResult(result *SwitchResult) {
+	switch {
+	case result.Success:
+		log.Printf("网卡 %s 已切换到「%s」, 连通性检查通过", result.Interface, result.Target)
+	case result.RolledBack:
+		log.Printf("网卡 %s 切换到「%s」失败, 已恢复切换前的配置: %s", result.Interface, result.Target, result.Error)
+	case result.RollbackError != "":
+		log.Printf("网卡 %s 切换到「%s」失败: %s; %s", result.Interface, result.Target, result.Error, result.RollbackError)
+	default:
+		log.Printf("网卡 %s 切换到「%s」: %s", result.Interface, result.Target, result.Error)
+	}
+
+	a.decisionMu.Lock()
+	a.lastSwitches[result.Interface] = result
+	a.decisionMu.Unlock()
+
+	if a.app != nil && a.app.Event != nil {
+		go a.app.Event.Emit("switchResult", result)
+	}
 }
 
 // handleAutoStart 处理开机启动
 func (a *WailsApp) handleAutoStart() {
 	if a.config.AutoStart {
-		err := EnableAutoStart()
+		err := EnableAutoStart(a.runner)
 		if err != nil {
 			log.Printf("启用开机启动失败: %v", err)
 		} else {
 			log.Println("已启用开机启动")
 		}
 	} else {
-		err := DisableAutoStart()
+		err := DisableAutoStart(a.runner)
 		if err != nil {
 			log.Printf("禁用开机启动失败: %v", err)
 		} else {
@@ -771,7 +2045,7 @@ func main() {
 	*/
 
 	// Create an instance of the app structure
-	app := NewWailsApp()
+	app := NewWailsApp(nil, nil, nil)
 
 	// Create application with options
 	appInstance := application.New(application.Options{
@@ -800,6 +2074,8 @@ func main() {
 		// 设置 OnShutdown 回调用于调试
 		OnShutdown: func() {
 			log.Println("========== OnShutdown 被调用 - 应用正在关闭 ==========")
+			// 退出后不再维护分流路由, 删除已添加的路由
+			app.clearAllSplitRoutes()
 		},
 	})
 
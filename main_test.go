@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestApp 创建使用内存网络后端的应用, 不读写配置文件
+func newTestApp(t *testing.T, mode string) (*WailsApp, *FakeBackend) {
+	t.Helper()
+	backend := NewFakeBackend("WLAN")
+	backend.WiFiName = "HomeWiFi"
+	backend.Reachable["192.168.1.1"] = true
+	backend.Reachable["192.168.1.2"] = true
+
+	config := &Config{
+		Profiles: []Profile{{
+			Name:     "家",
+			SSIDs:    []string{"HomeWiFi"},
+			StaticIP: "192.168.1.50",
+			Gateway:  "192.168.1.2",
+			DNS:      DNSList{"192.168.1.2"},
+		}},
+		IPMode:         mode,
+		InterfaceTypes: InterfaceBoth,
+		Switching:      SwitchPolicy{VerifySeconds: 1},
+	}
+	return NewWailsApp(config, backend, &stubRunner{}), backend
+}
+
+// stubRunner 不执行任何命令的命令执行器, 记录命令行并按命令行返回预设的输出
+type stubRunner struct {
+	outputs  map[string]string // 命令行对应的标准输出
+	failures map[string]bool   // 执行失败的命令行
+	commands []string          // 已执行的命令行, 按执行顺序
+}
+
+func (r *stubRunner) Run(ctx context.Context, cmd Command) (*CommandResult, error) {
+	line := cmd.String()
+	r.commands = append(r.commands, line)
+	if r.failures[line] {
+		return &CommandResult{ExitCode: 1}, errors.New("exit status 1")
+	}
+	return &CommandResult{Stdout: []byte(r.outputs[line])}, nil
+}
+
+func (r *stubRunner) Start(cmd Command) error {
+	r.commands = append(r.commands, cmd.String())
+	return nil
+}
+
+func TestCheckAndSwitchAdaptiveHome(t *testing.T) {
+	app, backend := newTestApp(t, "adaptive")
+	app.checkAndSwitch()
+
+	applied, ok := backend.LastApplied()
+	if !ok || applied.Mode != "static" || applied.IP != "192.168.1.50" || applied.Gateway != "192.168.1.2" {
+		t.Fatalf("applied = %+v, want static 192.168.1.50 via 192.168.1.2", applied)
+	}
+	if got := app.getActiveProfile("WLAN"); got != "家" {
+		t.Errorf("active profile = %q, want 家", got)
+	}
+
+	// 已经是目标配置时不重复设置
+	count := len(backend.Applied)
+	app.checkAndSwitch()
+	if len(backend.Applied) != count {
+		t.Errorf("reapplied unchanged config: %+v", backend.Applied[count:])
+	}
+}
+
+func TestCheckAndSwitchAdaptiveSideRouterDown(t *testing.T) {
+	app, backend := newTestApp(t, "adaptive")
+	backend.Reachable["192.168.1.2"] = false
+	app.checkAndSwitch()
+
+	if len(backend.Applied) != 0 || !backend.DHCP {
+		t.Fatalf("applied = %+v, want to stay on DHCP", backend.Applied)
+	}
+	decisions := app.GetLastDecisions()
+	if len(decisions) != 1 || decisions[0].Action != "dhcp" || decisions[0].SideRouterOK {
+		t.Fatalf("decisions = %+v, want dhcp with unhealthy side router", decisions)
+	}
+}
+
+func TestCheckAndSwitchAdaptiveLeaveHome(t *testing.T) {
+	app, backend := newTestApp(t, "adaptive")
+	app.checkAndSwitch()
+	if backend.DHCP {
+		t.Fatal("expected static IP at home")
+	}
+
+	// 离开家后匹配的网络配置变化, 立即切回动态IP
+	backend.WiFiName = "Cafe"
+	backend.Lease.Gateway = "10.0.0.1"
+	backend.Reachable["10.0.0.1"] = true
+	app.checkAndSwitch()
+
+	applied, _ := backend.LastApplied()
+	if applied.Mode != "dhcp" || !backend.DHCP {
+		t.Fatalf("applied = %+v, want dhcp", applied)
+	}
+	if got := app.getActiveProfile("WLAN"); got != "" {
+		t.Errorf("active profile = %q, want empty", got)
+	}
+}
+
+func TestCheckAndSwitchForcedModes(t *testing.T) {
+	app, backend := newTestApp(t, "static")
+	backend.WiFiName = "Cafe"
+	app.checkAndSwitch()
+	if applied, _ := backend.LastApplied(); applied.Mode != "static" {
+		t.Fatalf("static mode applied = %+v", applied)
+	}
+
+	app.config.IPMode = "dynamic"
+	app.checkAndSwitch()
+	if applied, _ := backend.LastApplied(); applied.Mode != "dhcp" {
+		t.Fatalf("dynamic mode applied = %+v", applied)
+	}
+}
+
+func TestCheckAndSwitchAdaptiveFactsError(t *testing.T) {
+	app, backend := newTestApp(t, "adaptive")
+	app.checkAndSwitch()
+	count := len(backend.Applied)
+	if backend.DHCP {
+		t.Fatal("expected static IP at home")
+	}
+
+	// 获取网络信息失败时不知道所在网络, 保持当前配置
+	backend.FactsErr = errors.New("执行netsh命令失败")
+	managed, _ := app.managedInterfaces()
+	for range 3 {
+		if err := app.checkAndSwitchInterface(managed[0]); err == nil {
+			t.Fatal("expected facts error")
+		}
+	}
+	if len(backend.Applied) != count || backend.DHCP {
+		t.Fatalf("switched on facts error: %+v", backend.Applied[count:])
+	}
+	if decisions := app.GetLastDecisions(); len(decisions) != 1 || decisions[0].Action != "" {
+		t.Errorf("decisions = %+v, want no action", decisions)
+	}
+
+	// 恢复后仍在同一网络, 不重新切换
+	backend.FactsErr = nil
+	app.checkAndSwitch()
+	if len(backend.Applied) != count || app.getActiveProfile("WLAN") != "家" {
+		t.Errorf("applied after recovery = %+v", backend.Applied[count:])
+	}
+}
+
+func TestMetricStrategyRestoresDefaultRouteMetric(t *testing.T) {
+	app, backend := newTestApp(t, "adaptive")
+	app.config.Profiles[0].Strategy = StrategyMetric
+	backend.Metric = 0
+
+	app.checkAndSwitch()
+	if applied, _ := backend.LastApplied(); applied.Mode != "metric" || backend.Metric != 1 {
+		t.Fatalf("applied = %+v, metric = %d, want metric with DHCP route metric 1", applied, backend.Metric)
+	}
+
+	// 离开旁路由时删除优先默认路由, DHCP默认路由恢复原来的跃点数
+	if err := app.clearPreferredGateway("WLAN"); err != nil {
+		t.Fatalf("clearPreferredGateway: %v", err)
+	}
+	if backend.Metric != 0 {
+		t.Errorf("DHCP默认路由跃点数 = %d, want 0", backend.Metric)
+	}
+	if routes := app.getDefaultRoutes("WLAN"); routes != nil {
+		t.Errorf("删除后仍有记录: %v", routes)
+	}
+}
+
+// lastSwitchResult 获取网卡 WLAN 最近一次切换的结果
+func lastSwitchResult(t *testing.T, app *WailsApp) *SwitchResult {
+	t.Helper()
+	results := app.GetSwitchResults()
+	if len(results) != 1 {
+		t.Fatalf("switch results = %+v, want 1", results)
+	}
+	return results[0]
+}
+
+func TestSwitchTransactionRollsBackAfterVerifyFailure(t *testing.T) {
+	app, backend := newTestApp(t, "static")
+	backend.Reachable["192.168.1.2"] = false
+	// DHCP地址上手动设置的DNS同样恢复
+	backend.DNS, backend.StaticDNS = []string{"223.5.5.5"}, true
+
+	if err := app.switchToStatic("WLAN", &app.config.Profiles[0]); err == nil {
+		t.Fatal("连通性检查失败时应返回错误")
+	}
+	result := lastSwitchResult(t, app)
+	if result.Success || !result.RolledBack || result.RollbackError != "" {
+		t.Fatalf("result = %+v, want rolled back", result)
+	}
+	if !backend.DHCP || backend.IP != "192.168.1.100" || app.getActiveProfile("WLAN") != "" {
+		t.Errorf("没有恢复DHCP地址: dhcp=%v ip=%s profile=%q", backend.DHCP, backend.IP, app.getActiveProfile("WLAN"))
+	}
+	if !backend.StaticDNS || len(backend.DNS) != 1 || backend.DNS[0] != "223.5.5.5" {
+		t.Errorf("DNS = %v, static=%v, want 223.5.5.5", backend.DNS, backend.StaticDNS)
+	}
+}
+
+func TestSwitchTransactionRestoresStaticWithoutDNS(t *testing.T) {
+	app, backend := newTestApp(t, "override")
+	backend.Reachable["192.168.1.2"] = false
+	backend.SetStaticIP("WLAN", "192.168.1.60", "255.255.255.0", "192.168.1.1", nil)
+
+	if err := app.switchToOverride("WLAN", &app.config.Profiles[0]); err == nil {
+		t.Fatal("连通性检查失败时应返回错误")
+	}
+	applied, _ := backend.LastApplied()
+	if applied.Mode != "static" || applied.IP != "192.168.1.60" || len(applied.DNS) != 0 {
+		t.Fatalf("applied = %+v, want static 192.168.1.60 without DNS", applied)
+	}
+	if !lastSwitchResult(t, app).RolledBack {
+		t.Errorf("result = %+v, want rolled back", lastSwitchResult(t, app))
+	}
+}
+
+func TestSwitchTransactionRollsBackIPv6Failure(t *testing.T) {
+	app, backend := newTestApp(t, "static")
+	app.config.Profiles[0].IPv6Mode = IPv6Disable
+	backend.AutoIPv6 = IPv6Config{Gateways: []string{"fe80::1"}, DNSServers: []string{"fe80::1"}}
+	backend.IPv6 = backend.AutoIPv6
+	backend.IPv6Err = errors.New("netsh 执行失败")
+
+	if err := app.switchToStatic("WLAN", &app.config.Profiles[0]); err == nil {
+		t.Fatal("设置IPv6失败时应返回错误")
+	}
+	if result := lastSwitchResult(t, app); !result.RolledBack {
+		t.Fatalf("result = %+v, want rolled back", result)
+	}
+	if !backend.DHCP {
+		t.Error("设置IPv6失败后没有恢复DHCP地址")
+	}
+
+	// 切换到动态IP时恢复了IPv6自动获取, 检查失败后IPv6恢复为切换前的停用
+	backend.IPv6Err = nil
+	if err := app.switchToStatic("WLAN", &app.config.Profiles[0]); err != nil {
+		t.Fatalf("switchToStatic: %v", err)
+	}
+	if app.getIPv6Mode("WLAN") != IPv6Disable || len(backend.IPv6.Gateways) != 0 {
+		t.Fatalf("IPv6 = %+v, mode = %q, want disabled", backend.IPv6, app.getIPv6Mode("WLAN"))
+	}
+	backend.Reachable["192.168.1.1"] = false
+	if err := app.switchToDHCP("WLAN"); err == nil {
+		t.Fatal("连通性检查失败时应返回错误")
+	}
+	if applied, _ := backend.LastApplied(); applied.Mode != "ipv6-disable" || app.getIPv6Mode("WLAN") != IPv6Disable {
+		t.Errorf("applied = %+v, mode = %q, want ipv6-disable", applied, app.getIPv6Mode("WLAN"))
+	}
+	if backend.DHCP || !lastSwitchResult(t, app).RolledBack {
+		t.Errorf("没有恢复切换前的静态IP: %+v", lastSwitchResult(t, app))
+	}
+}
+
+func TestSwitchToSplitAppliesIPv6(t *testing.T) {
+	app, backend := newTestApp(t, "static")
+	profile := &app.config.Profiles[0]
+	profile.SplitRoutes = []string{"10.0.0.0/8"}
+	profile.IPv6Mode = IPv6Disable
+	backend.AutoIPv6 = IPv6Config{Gateways: []string{"fe80::1"}, DNSServers: []string{"fe80::1"}}
+	backend.IPv6 = backend.AutoIPv6
+
+	if err := app.switchToStatic("WLAN", profile); err != nil {
+		t.Fatalf("switchToStatic: %v", err)
+	}
+	if applied, _ := backend.LastApplied(); applied.Mode != "ipv6-disable" || app.getIPv6Mode("WLAN") != IPv6Disable {
+		t.Fatalf("applied = %+v, mode = %q, want ipv6-disable", applied, app.getIPv6Mode("WLAN"))
+	}
+	if !backend.DHCP || app.getActiveProfile("WLAN") != "家(分流)" {
+		t.Errorf("dhcp = %v, profile = %q, want 家(分流) on DHCP", backend.DHCP, app.getActiveProfile("WLAN"))
+	}
+
+	// 分流路由和IPv6都已生效时不重复设置
+	count := len(backend.Applied)
+	if err := app.switchToStatic("WLAN", profile); err != nil {
+		t.Fatalf("switchToStatic: %v", err)
+	}
+	if len(backend.Applied) != count {
+		t.Errorf("重复设置: %+v", backend.Applied[count:])
+	}
+}
+
+func TestSwitchTransactionSerializesInterface(t *testing.T) {
+	app, _ := newTestApp(t, "static")
+	lock := app.interfaceLock("WLAN")
+	lock.Lock()
+
+	done := make(chan struct{})
+	go func() {
+		app.switchTransaction("WLAN", "家", "192.168.1.2", func() error { return nil })
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("同一网卡的切换事务应等待正在执行的事务结束")
+	case <-time.After(50 * time.Millisecond):
+	}
+	lock.Unlock()
+	<-done
+}
+
+func TestCheckAndSwitchAdaptiveRollbackResetsGuard(t *testing.T) {
+	app, backend := newTestApp(t, "adaptive")
+	// 健康检查时旁路由可达, 改为静态IP后不可达, 连通性检查失败后恢复动态IP
+	backend.StaticDown = map[string]bool{"192.168.1.2": true}
+
+	app.checkAndSwitch()
+	applied, _ := backend.LastApplied()
+	if applied.Mode != "dhcp" || !backend.DHCP || !lastSwitchResult(t, app).RolledBack {
+		t.Fatalf("applied = %+v, want rolled back to dhcp", applied)
+	}
+	count := len(backend.Applied)
+
+	// 防抖状态回到动态IP, 下次检查不立即重新切换
+	app.checkAndSwitch()
+	if len(backend.Applied) != count {
+		t.Errorf("rollback 后立即重新切换: %+v", backend.Applied[count:])
+	}
+	guard := app.guards["WLAN"]
+	if guard.Action != "dhcp" || len(guard.Switches) != 1 {
+		t.Errorf("guard = %+v, want dhcp with 1 switch", guard)
+	}
+}
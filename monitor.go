@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// 自适应模式检查时间的默认值(秒)
+const (
+	DefaultStartupDelay        = 5   // 启动后等待网络连接
+	DefaultStaticInterval      = 3   // 使用旁路由时快速检查, 旁路由故障时尽快切回
+	DefaultDHCPInterval        = 30  // 动态IP时的检查间隔
+	DefaultWatchedDHCPInterval = 60  // 能监听网络变化通知时, 回到家会立即检查, 动态IP时可以检查得更慢
+	DefaultMaxBackoff          = 300 // 网络命令连续失败时检查间隔的上限
+)
+
+// MonitorPolicy 自适应模式的检查时间设置, 单位为秒, 为0时使用默认值
+type MonitorPolicy struct {
+	StartupDelay   int // 启动后第一次检查前等待的时间
+	StaticInterval int // 使用旁路由(静态IP)时的检查间隔
+	DHCPInterval   int // 动态IP时的检查间隔, 默认30; 能监听网络变化通知时默认60
+	MaxBackoff     int // 网络命令连续失败时检查间隔翻倍, 最长不超过该时间
+}
+
+// withDefaults 返回填充了默认值的设置, watching 为是否能监听网络变化通知
+func (p MonitorPolicy) withDefaults(watching bool) MonitorPolicy {
+	if p.StartupDelay <= 0 {
+		p.StartupDelay = DefaultStartupDelay
+	}
+	if p.StaticInterval <= 0 {
+		p.StaticInterval = DefaultStaticInterval
+	}
+	if p.DHCPInterval <= 0 {
+		p.DHCPInterval = DefaultDHCPInterval
+		if watching {
+			p.DHCPInterval = DefaultWatchedDHCPInterval
+		}
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultMaxBackoff
+	}
+	return p
+}
+
+// validate 检查检查时间设置
+func (p MonitorPolicy) validate() error {
+	if p.StartupDelay < 0 || p.StaticInterval < 0 || p.DHCPInterval < 0 || p.MaxBackoff < 0 {
+		return fmt.Errorf("检查时间设置不能为负数")
+	}
+	return nil
+}
+
+// interval 下一次检查前等待的时间
+// onSideRouter 为是否有网卡正在使用旁路由, failures 为网络命令连续失败的检查次数,
+// 连续失败时在基础间隔上按指数退避
+func (p MonitorPolicy) interval(onSideRouter bool, failures int) time.Duration {
+	interval := time.Duration(p.DHCPInterval) * time.Second
+	if onSideRouter {
+		interval = time.Duration(p.StaticInterval) * time.Second
+	}
+
+	// 基础间隔已经超过上限时不退避
+	maxBackoff := time.Duration(p.MaxBackoff) * time.Second
+	for i := 0; i < failures && interval < maxBackoff; i++ {
+		interval *= 2
+		if interval > maxBackoff {
+			interval = maxBackoff
+		}
+	}
+	return interval
+}
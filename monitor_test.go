@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitorPolicyInterval(t *testing.T) {
+	policy := MonitorPolicy{StaticInterval: 3, DHCPInterval: 30, MaxBackoff: 300}
+
+	tests := []struct {
+		name         string
+		policy       MonitorPolicy
+		onSideRouter bool
+		failures     int
+		want         time.Duration
+	}{
+		{"使用旁路由时快速检查", policy, true, 0, 3 * time.Second},
+		{"动态IP时慢速检查", policy, false, 0, 30 * time.Second},
+		{"失败1次翻倍", policy, true, 1, 6 * time.Second},
+		{"失败3次翻3倍", policy, false, 3, 240 * time.Second},
+		{"不超过上限", policy, false, 4, 300 * time.Second},
+		{"失败很多次仍为上限", policy, true, 100, 300 * time.Second},
+		{"基础间隔超过上限时不退避", MonitorPolicy{StaticInterval: 3, DHCPInterval: 600, MaxBackoff: 300}, false, 2, 600 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.interval(tt.onSideRouter, tt.failures); got != tt.want {
+				t.Errorf("interval(%v, %d) = %s, want %s", tt.onSideRouter, tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonitorPolicyDefaults(t *testing.T) {
+	if got := (MonitorPolicy{}).withDefaults(false).DHCPInterval; got != DefaultDHCPInterval {
+		t.Errorf("DHCPInterval = %d, want %d", got, DefaultDHCPInterval)
+	}
+	// 能监听网络变化通知时动态IP检查得更慢
+	if got := (MonitorPolicy{}).withDefaults(true).DHCPInterval; got != DefaultWatchedDHCPInterval {
+		t.Errorf("watched DHCPInterval = %d, want %d", got, DefaultWatchedDHCPInterval)
+	}
+	if got := (MonitorPolicy{DHCPInterval: 10}).withDefaults(true).DHCPInterval; got != 10 {
+		t.Errorf("设置的 DHCPInterval 被覆盖: %d", got)
+	}
+}
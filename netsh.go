@@ -0,0 +1,669 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+)
+
+// NetshBackend 基于 Windows netsh 命令的网络后端
+type NetshBackend struct {
+	runner  CommandRunner
+	catalog KeywordCatalog // 解析输出时使用的关键字目录
+	pinger  *Pinger
+}
+
+// NewNetshBackend 创建 netsh 网络后端
+func NewNetshBackend(runner CommandRunner, catalog KeywordCatalog) *NetshBackend {
+	pinger := NewPinger(DefaultPingCount, DefaultPingTimeout)
+	pinger.Runner = runner
+	return &NetshBackend{runner: runner, catalog: catalog, pinger: pinger}
+}
+
+// netsh 执行 netsh 命令，返回按控制台代码页转换为UTF-8后的输出
+func (b *NetshBackend) netsh(args ...string) (string, error) {
+	result, err := runCmd(b.runner, "netsh", args...)
+	return decodeConsoleOutput(result.Stdout) + decodeConsoleOutput(result.Stderr), err
+}
+
+// adapters 通过系统接口获取所有网卡的信息, 结果与 netsh 命令一起记录和回放
+func (b *NetshBackend) adapters() ([]windowsAdapter, error) {
+	return recordCall(b.runner, Command{Name: "GetAdaptersAddresses"}, listWindowsAdapters)
+}
+
+// interfaceTypes 获取各网卡的类型, 键为网卡名称
+func (b *NetshBackend) interfaceTypes() (map[string]string, error) {
+	adapters, err := b.adapters()
+	if err != nil {
+		return nil, fmt.Errorf("获取网卡信息失败: %v", err)
+	}
+	types := make(map[string]string, len(adapters))
+	for _, adapter := range adapters {
+		types[adapter.Name] = adapter.Type
+	}
+	return types, nil
+}
+
+// ListInterfaces 列出所有无线和有线网卡
+func (b *NetshBackend) ListInterfaces() ([]NetworkInterface, error) {
+	adapters, err := b.adapters()
+	if err != nil {
+		return nil, fmt.Errorf("获取网卡信息失败: %v", err)
+	}
+
+	var ifaces []NetworkInterface
+	for _, adapter := range adapters {
+		// 跳过环回、隧道等虚拟接口
+		if adapter.Type == "" {
+			continue
+		}
+		ifaces = append(ifaces, NetworkInterface{
+			Name:      adapter.Name,
+			Index:     int(adapter.Index),
+			Type:      adapter.Type,
+			MAC:       adapter.MAC,
+			Connected: adapter.Up,
+		})
+	}
+	return ifaces, nil
+}
+
+// GetActiveInterface 获取活动网络接口名称
+func (b *NetshBackend) GetActiveInterface(types string) (string, error) {
+	// 使用netsh命令获取网络接口信息
+	output, err := b.netsh("interface", "show", "interface")
+	if err != nil {
+		return "", err
+	}
+
+	// netsh 不区分无线和有线网卡, 网卡类型从系统接口读取
+	ifTypes, typeErr := b.interfaceTypes()
+	if typeErr != nil {
+		log.Printf("%v", typeErr)
+	}
+
+	lines := strings.Split(output, "\n")
+	for _, line := range lines {
+		// 查找已连接的专用网络接口
+		state, connType, name, ok := parseNetshInterfaceLine(line)
+		if ok && b.catalog.IsConnectedDedicated(state, connType) {
+			// 获取不到网卡类型时, 只有管理全部类型的网卡才使用该接口
+			if typeErr != nil && types != InterfaceWiFi && types != InterfaceEthernet {
+				return name, nil
+			}
+			if matchesInterfaceTypes(ifTypes[name], types) {
+				return name, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("未找到活动网络接口")
+}
+
+// ShowConfig 获取并解析网络接口的IP配置
+func (b *NetshBackend) ShowConfig(iface string) (*InterfaceConfig, error) {
+	// 使用netsh命令获取接口IP配置
+	output, err := b.netsh("interface", "ip", "show", "config", iface)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := ParseNetshIPConfig(output, b.catalog)
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("解析接口 %s 的配置失败", iface)
+	}
+	return configs[0], nil
+}
+
+// GetCurrentIPConfig 检查当前网络接口是否为DHCP模式
+func (b *NetshBackend) GetCurrentIPConfig(iface string) (isDHCP bool, err error) {
+	config, err := b.ShowConfig(iface)
+	if err != nil {
+		return false, err
+	}
+	return config.DHCP, nil
+}
+
+// GetCurrentStaticIPConfig 检查当前网络接口是否已经是目标静态IP配置
+func (b *NetshBackend) GetCurrentStaticIPConfig(iface, staticIP, subnetMask, gateway string, dns []string) (isStatic bool, err error) {
+	config, err := b.ShowConfig(iface)
+	if err != nil {
+		return false, err
+	}
+
+	// 如果当前是DHCP模式，则肯定不是目标静态IP配置
+	if config.DHCP {
+		return false, nil
+	}
+
+	// 检查IP地址、子网掩码、网关和DNS是否精确匹配目标配置
+	return config.HasAddressWithMask(staticIP, subnetMask) &&
+		config.HasGateway(gateway) &&
+		config.DNSSource == "static" && equalStrings(config.DNSServers, dns), nil
+}
+
+// SetDHCP 设置网络接口为DHCP模式
+func (b *NetshBackend) SetDHCP(iface string) error {
+	// 设置为DHCP自动获取IP
+	output, err := b.netsh("interface", "ip", "set", "address", iface, "dhcp")
+	if err != nil {
+		return fmt.Errorf("设置DHCP IP失败: %v. %s", err, strings.TrimSpace(output))
+	}
+
+	// 设置DNS为自动获取
+	output, err = b.netsh("interface", "ip", "set", "dns", iface, "dhcp")
+	if err != nil {
+		return fmt.Errorf("设置DHCP DNS失败: %v. %s", err, strings.TrimSpace(output))
+	}
+
+	return nil
+}
+
+// SetStaticIP 设置网络接口为静态IP模式
+func (b *NetshBackend) SetStaticIP(iface, ip, subnetMask, gateway string, dns []string) error {
+	// 设置静态IP地址、子网掩码和网关
+	output, err := b.netsh("interface", "ip", "set", "address", iface, "static", ip, subnetMask, gateway)
+	if err != nil {
+		return fmt.Errorf("设置静态IP失败: %v. %s", err, strings.TrimSpace(output))
+	}
+
+	return b.setDNS(iface, dns)
+}
+
+// SetDNS 设置网络接口的静态DNS, 不改动地址
+func (b *NetshBackend) SetDNS(iface string, dns []string) error {
+	return b.setDNS(iface, dns)
+}
+
+// setDNS 按优先顺序设置静态DNS, dns 为空时清空静态DNS
+func (b *NetshBackend) setDNS(iface string, dns []string) error {
+	if len(dns) == 0 {
+		output, err := b.netsh("interface", "ip", "set", "dns", iface, "static", "none")
+		if err != nil {
+			return fmt.Errorf("清空静态DNS失败: %v. %s", err, strings.TrimSpace(output))
+		}
+		return nil
+	}
+
+	// 设置首选DNS服务器, 再按顺序添加备用DNS服务器
+	output, err := b.netsh("interface", "ip", "set", "dns", iface, "static", dns[0])
+	if err != nil {
+		return fmt.Errorf("设置静态DNS失败: %v. %s", err, strings.TrimSpace(output))
+	}
+	for i, server := range dns[1:] {
+		output, err = b.netsh("interface", "ip", "add", "dns", iface, server, fmt.Sprintf("index=%d", i+2))
+		if err != nil {
+			return fmt.Errorf("添加备用DNS失败: %v. %s", err, strings.TrimSpace(output))
+		}
+	}
+
+	return nil
+}
+
+// GetIPv4Config 获取网络接口当前的IPv4地址、子网掩码、网关和DNS
+func (b *NetshBackend) GetIPv4Config(iface string) (*IPv4Config, error) {
+	config, err := b.ShowConfig(iface)
+	if err != nil {
+		return nil, err
+	}
+	result := &IPv4Config{
+		DHCP:      config.DHCP,
+		DNS:       config.DNSServers,
+		StaticDNS: config.DNSSource == "static",
+	}
+	if len(config.Addresses) > 0 {
+		result.IP, result.SubnetMask = config.Addresses[0].IP, config.Addresses[0].Mask
+	}
+	if len(config.Gateways) > 0 {
+		result.Gateway = config.Gateways[0].Address
+	}
+	return result, nil
+}
+
+// GetIPv6Config 获取网络接口当前的IPv6地址、网关和DNS
+// netsh 的IPv6输出同样受系统语言影响, 这里通过系统接口读取
+func (b *NetshBackend) GetIPv6Config(iface string) (*IPv6Config, error) {
+	adapters, err := b.adapters()
+	if err != nil {
+		return nil, fmt.Errorf("获取网卡信息失败: %v", err)
+	}
+	for _, adapter := range adapters {
+		if adapter.Name == iface {
+			config := adapter.IPv6
+			return &config, nil
+		}
+	}
+	return nil, fmt.Errorf("网络接口 %s 不存在", iface)
+}
+
+// setIPv6Autoconf 开启或关闭路由通告、DHCPv6地址和DHCPv6其他配置(DNS)
+func (b *NetshBackend) setIPv6Autoconf(iface string, enabled bool) error {
+	state := "disabled"
+	if enabled {
+		state = "enabled"
+	}
+	output, err := b.netsh("interface", "ipv6", "set", "interface", iface,
+		"routerdiscovery="+state, "managedaddress="+state, "otherstateful="+state)
+	if err != nil {
+		return fmt.Errorf("设置IPv6自动配置失败: %v. %s", err, strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// deleteIPv6Routes 删除IPv6默认路由, 没有默认路由时忽略错误
+func (b *NetshBackend) deleteIPv6Routes(iface string, config *IPv6Config) {
+	for _, gateway := range config.Gateways {
+		b.netsh("interface", "ipv6", "delete", "route", "::/0", iface, gateway)
+	}
+}
+
+// SetStaticIPv6 设置静态IPv6地址、网关和DNS
+func (b *NetshBackend) SetStaticIPv6(iface, address, gateway string, dns []string) error {
+	config, err := b.GetIPv6Config(iface)
+	if err != nil {
+		return err
+	}
+	if err := b.setIPv6Autoconf(iface, false); err != nil {
+		return err
+	}
+
+	// 删除其他手动设置的地址, 目标地址已存在时不重复添加
+	ip, _, _ := net.ParseCIDR(address)
+	exists := false
+	for _, addr := range config.Addresses {
+		if ip.Equal(net.ParseIP(addr.IP)) {
+			exists = true
+		} else if addr.Manual {
+			b.netsh("interface", "ipv6", "delete", "address", iface, addr.IP)
+		}
+	}
+	if !exists {
+		output, err := b.netsh("interface", "ipv6", "add", "address", iface, address)
+		if err != nil {
+			return fmt.Errorf("设置静态IPv6失败: %v. %s", err, strings.TrimSpace(output))
+		}
+	}
+
+	b.deleteIPv6Routes(iface, config)
+	output, err := b.netsh("interface", "ipv6", "add", "route", "::/0", iface, gateway)
+	if err != nil {
+		return fmt.Errorf("设置IPv6网关失败: %v. %s", err, strings.TrimSpace(output))
+	}
+
+	return b.setIPv6DNS(iface, dns)
+}
+
+// AddRoute 添加一条经过指定网关的临时路由, store=active 表示重启后失效
+func (b *NetshBackend) AddRoute(iface, destination, gateway string) error {
+	output, err := b.netsh("interface", "ipv4", "add", "route", destination, iface, gateway, "store=active")
+	if err != nil {
+		return fmt.Errorf("添加路由失败: %v. %s", err, strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// DeleteRoute 删除 AddRoute 添加的路由
+func (b *NetshBackend) DeleteRoute(iface, destination, gateway string) error {
+	output, err := b.netsh("interface", "ipv4", "delete", "route", destination, iface, gateway, "store=active")
+	if err != nil {
+		return fmt.Errorf("删除路由失败: %v. %s", err, strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// ListRoutes 列出网卡上经过网关的IPv4路由
+// 路由表通过 netsh 读取, 按接口索引筛选出该网卡的路由
+func (b *NetshBackend) ListRoutes(iface string) ([]Route, error) {
+	adapters, err := b.adapters()
+	if err != nil {
+		return nil, fmt.Errorf("获取网卡信息失败: %v", err)
+	}
+	index := -1
+	for _, adapter := range adapters {
+		if adapter.Name == iface {
+			index = int(adapter.Index)
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("网络接口 %s 不存在", iface)
+	}
+
+	output, err := b.netsh("interface", "ipv4", "show", "route")
+	if err != nil {
+		return nil, fmt.Errorf("获取路由失败: %v. %s", err, strings.TrimSpace(output))
+	}
+	var routes []Route
+	for _, route := range ParseNetshRoutes(output) {
+		// 直连路由的网关列为接口名称
+		if route.Index == index && net.ParseIP(route.Gateway) != nil {
+			routes = append(routes, Route{Destination: route.Prefix, Gateway: route.Gateway, Metric: route.Metric})
+		}
+	}
+	return routes, nil
+}
+
+// GetRouteOverride 获取覆盖默认路由的网关
+func (b *NetshBackend) GetRouteOverride(iface string) (string, error) {
+	routes, err := b.ListRoutes(iface)
+	if err != nil {
+		return "", err
+	}
+	gateway := ""
+	for i, destination := range overrideRoutes {
+		via := ""
+		for _, route := range routes {
+			if route.Destination == destination {
+				via = route.Gateway
+			}
+		}
+		if via == "" || (i > 0 && via != gateway) {
+			return "", nil
+		}
+		gateway = via
+	}
+	return gateway, nil
+}
+
+// SetPreferredGateway 保留DHCP地址, 添加经过 gateway、跃点数更小的默认路由并设置静态DNS
+// DHCP下发的默认路由跃点数通常为0, 先改为1, 再以跃点数0添加旁路由的默认路由, ClearPreferredGateway 时改回;
+// 路由使用 store=active, 网卡断开或续租后可能恢复, 由自适应检查重新设置
+func (b *NetshBackend) SetPreferredGateway(iface, gateway string, dns []string) error {
+	config, err := b.ShowConfig(iface)
+	if err != nil {
+		return fmt.Errorf("获取IP配置失败: %v", err)
+	}
+	if !config.DHCP {
+		output, err := b.netsh("interface", "ip", "set", "address", iface, "dhcp")
+		if err != nil {
+			return fmt.Errorf("设置DHCP IP失败: %v. %s", err, strings.TrimSpace(output))
+		}
+	}
+
+	routes, err := b.ListRoutes(iface)
+	if err != nil {
+		return err
+	}
+	metric := -1
+	for _, route := range routes {
+		if route.Destination != "0.0.0.0/0" {
+			continue
+		}
+		if route.Gateway == gateway {
+			// 删除之前添加的路由, 按其他默认路由重新计算跃点数
+			b.DeleteRoute(iface, route.Destination, gateway)
+			continue
+		}
+		if route.Metric == 0 {
+			output, err := b.netsh("interface", "ipv4", "set", "route", route.Destination, iface, route.Gateway, "metric=1", "store=active")
+			if err != nil {
+				return fmt.Errorf("调整默认路由跃点数失败: %v. %s", err, strings.TrimSpace(output))
+			}
+			route.Metric = 1
+		}
+		if metric < 0 || route.Metric-1 < metric {
+			metric = route.Metric - 1
+		}
+	}
+	if metric < 0 {
+		metric = 0
+	}
+
+	output, err := b.netsh("interface", "ipv4", "add", "route", "0.0.0.0/0", iface, gateway, fmt.Sprintf("metric=%d", metric), "store=active")
+	if err != nil {
+		return fmt.Errorf("添加默认路由失败: %v. %s", err, strings.TrimSpace(output))
+	}
+	return b.setDNS(iface, dns)
+}
+
+// ClearPreferredGateway 删除经过 gateway 的默认路由, 把被调整过跃点数的默认路由改回 defaults 中的跃点数, 恢复DHCP下发的DNS
+func (b *NetshBackend) ClearPreferredGateway(iface, gateway string, defaults []Route) error {
+	if err := b.DeleteRoute(iface, "0.0.0.0/0", gateway); err != nil {
+		return err
+	}
+	routes, err := b.ListRoutes(iface)
+	if err != nil {
+		return err
+	}
+	for _, route := range changedDefaultRoutes(routes, defaults) {
+		output, err := b.netsh("interface", "ipv4", "set", "route", route.Destination, iface, route.Gateway, fmt.Sprintf("metric=%d", route.Metric), "store=active")
+		if err != nil {
+			return fmt.Errorf("恢复默认路由跃点数失败: %v. %s", err, strings.TrimSpace(output))
+		}
+	}
+	output, err := b.netsh("interface", "ip", "set", "dns", iface, "dhcp")
+	if err != nil {
+		return fmt.Errorf("恢复DHCP DNS失败: %v. %s", err, strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// SetRouteOverride 保留DHCP地址, 添加经过 gateway 的 overrideRoutes 并设置静态DNS
+// 路由使用 store=active, 网卡断开或重启后失效, 由自适应检查重新添加
+func (b *NetshBackend) SetRouteOverride(iface, gateway string, dns []string) error {
+	config, err := b.ShowConfig(iface)
+	if err != nil {
+		return fmt.Errorf("获取IP配置失败: %v", err)
+	}
+	if !config.DHCP {
+		output, err := b.netsh("interface", "ip", "set", "address", iface, "dhcp")
+		if err != nil {
+			return fmt.Errorf("设置DHCP IP失败: %v. %s", err, strings.TrimSpace(output))
+		}
+	}
+
+	// 删除之前经过其他网关的覆盖路由
+	if current, err := b.GetRouteOverride(iface); err == nil && current != "" && current != gateway {
+		for _, destination := range overrideRoutes {
+			b.DeleteRoute(iface, destination, current)
+		}
+	}
+	for _, destination := range overrideRoutes {
+		// 路由已存在时 netsh 返回错误, 先删除再添加
+		b.DeleteRoute(iface, destination, gateway)
+		if err := b.AddRoute(iface, destination, gateway); err != nil {
+			return fmt.Errorf("覆盖默认路由失败: %v", err)
+		}
+	}
+
+	return b.setDNS(iface, dns)
+}
+
+// ClearRouteOverride 删除覆盖默认路由的路由, 恢复DHCP下发的DNS
+func (b *NetshBackend) ClearRouteOverride(iface string) error {
+	gateway, err := b.GetRouteOverride(iface)
+	if err != nil {
+		return err
+	}
+	if gateway != "" {
+		for _, destination := range overrideRoutes {
+			if err := b.DeleteRoute(iface, destination, gateway); err != nil {
+				return err
+			}
+		}
+	}
+
+	output, err := b.netsh("interface", "ip", "set", "dns", iface, "dhcp")
+	if err != nil {
+		return fmt.Errorf("恢复DHCP DNS失败: %v. %s", err, strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// setIPv6DNS 按顺序设置静态IPv6 DNS, dns 为空时清空IPv6 DNS
+func (b *NetshBackend) setIPv6DNS(iface string, dns []string) error {
+	primary := "none"
+	if len(dns) > 0 {
+		primary = dns[0]
+	}
+	output, err := b.netsh("interface", "ipv6", "set", "dns", iface, "static", primary)
+	if err != nil {
+		return fmt.Errorf("设置IPv6 DNS失败: %v. %s", err, strings.TrimSpace(output))
+	}
+	for i := 1; i < len(dns); i++ {
+		output, err = b.netsh("interface", "ipv6", "add", "dns", iface, dns[i], fmt.Sprintf("index=%d", i+1))
+		if err != nil {
+			return fmt.Errorf("添加备用IPv6 DNS失败: %v. %s", err, strings.TrimSpace(output))
+		}
+	}
+	return nil
+}
+
+// DisableIPv6Auto 停止接受路由通告和DHCPv6, 清除IPv6默认网关和DNS
+func (b *NetshBackend) DisableIPv6Auto(iface string) error {
+	config, err := b.GetIPv6Config(iface)
+	if err != nil {
+		return err
+	}
+	if err := b.setIPv6Autoconf(iface, false); err != nil {
+		return err
+	}
+	b.deleteIPv6Routes(iface, config)
+	return b.setIPv6DNS(iface, nil)
+}
+
+// ResetIPv6 恢复自动获取IPv6配置, 删除手动设置的IPv6地址和网关
+func (b *NetshBackend) ResetIPv6(iface string) error {
+	config, err := b.GetIPv6Config(iface)
+	if err != nil {
+		return err
+	}
+	for _, addr := range config.Addresses {
+		if addr.Manual {
+			b.netsh("interface", "ipv6", "delete", "address", iface, addr.IP)
+		}
+	}
+	b.deleteIPv6Routes(iface, config)
+
+	if err := b.setIPv6Autoconf(iface, true); err != nil {
+		return err
+	}
+	output, err := b.netsh("interface", "ipv6", "set", "dns", iface, "dhcp")
+	if err != nil {
+		return fmt.Errorf("恢复IPv6 DNS失败: %v. %s", err, strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// Ping 测试网络连通性
+func (b *NetshBackend) Ping(host string) bool {
+	return b.pinger.Ping(host).Reachable()
+}
+
+// wlanInfo 获取无线网卡 iface 当前连接的WiFi名称和接入点MAC地址
+func (b *NetshBackend) wlanInfo(iface string) (ssid, bssid string, err error) {
+	outputStr, err := b.netsh("wlan", "show", "interfaces")
+	if err != nil {
+		// 检查是否因为位置服务禁用导致无法获取SSID
+		if b.catalog.NeedsLocationPermission(outputStr) {
+			return "", "", fmt.Errorf("%w: %v", ErrLocationPermission, err)
+		}
+		return "", "", fmt.Errorf("执行netsh命令失败: %v. %v", err, outputStr)
+	}
+
+	ssid, bssid = parseNetshWlan(outputStr, iface)
+	if ssid == "" {
+		return "", "", fmt.Errorf("未找到WiFi信息")
+	}
+	return ssid, normalizeMAC(bssid), nil
+}
+
+// GetCurrentWiFiName 获取无线网卡 iface 当前连接的WiFi名称
+func (b *NetshBackend) GetCurrentWiFiName(iface string) (string, error) {
+	ssid, _, err := b.wlanInfo(iface)
+	return ssid, err
+}
+
+// GetNetworkFacts 获取判断当前所在网络所需的信息
+func (b *NetshBackend) GetNetworkFacts(iface string) (*NetworkFacts, error) {
+	facts := &NetworkFacts{Interface: iface}
+
+	config, err := b.ShowConfig(iface)
+	if err != nil {
+		return facts, fmt.Errorf("获取网络配置失败: %v", err)
+	}
+	facts.DHCP = config.DHCP
+	if len(config.Addresses) > 0 {
+		facts.IPAddress = config.Addresses[0].IP
+	}
+	if len(config.Gateways) > 0 {
+		facts.Gateway = config.Gateways[0].Address
+		// 网关的MAC地址从ARP缓存中读取, 获取不到时留空
+		if result, err := runCmd(b.runner, "arp", "-a", facts.Gateway); err == nil {
+			facts.GatewayMAC = parseArpMAC(decodeConsoleOutput(result.Stdout), facts.Gateway)
+		}
+	}
+
+	// DHCP服务器和DNS后缀 netsh 不显示, 通过系统接口读取
+	adapters, err := b.adapters()
+	if err != nil {
+		log.Printf("获取网卡信息失败: %v", err)
+	}
+	for _, adapter := range adapters {
+		if adapter.Name == iface {
+			facts.InterfaceType = adapter.Type
+			facts.DHCPServer = adapter.DHCPServer
+			facts.DNSSuffix = adapter.DNSSuffix
+			break
+		}
+	}
+
+	// 有线网络没有WiFi信息, 只有位置服务被禁用时才返回错误
+	if facts.InterfaceType == InterfaceEthernet {
+		return facts, nil
+	}
+	facts.SSID, facts.BSSID, err = b.wlanInfo(iface)
+	if errors.Is(err, ErrLocationPermission) {
+		return facts, err
+	}
+	return facts, nil
+}
+
+// GetCurrentNetworkStatus 获取网络接口的详细状态
+func (b *NetshBackend) GetCurrentNetworkStatus(iface string) (*NetworkStatus, error) {
+	status := &NetworkStatus{Interface: iface}
+	if ifTypes, err := b.interfaceTypes(); err == nil {
+		status.InterfaceType = ifTypes[iface]
+	}
+
+	// 获取WiFi名称, 有线网卡不显示WiFi
+	status.WiFiName = "未连接"
+	if status.InterfaceType != InterfaceEthernet {
+		if wifiName, err := b.GetCurrentWiFiName(iface); err == nil {
+			status.WiFiName = wifiName
+			status.WiFiConnected = true
+		}
+	}
+
+	// 获取网络接口配置
+	config, err := b.ShowConfig(iface)
+	if err != nil {
+		return status, fmt.Errorf("获取网络配置失败: %v", err)
+	}
+
+	if len(config.Addresses) > 0 {
+		status.IPAddress = config.Addresses[0].IP
+	}
+	if len(config.Gateways) > 0 {
+		status.Gateway = config.Gateways[0].Address
+	}
+
+	// 设置分配方式
+	if config.DHCP {
+		status.IPAssignment = "自动(DHCP)"
+	} else {
+		status.IPAssignment = "手动"
+	}
+
+	if config.DNSSource == "dhcp" {
+		status.DNSAssignment = "自动(DHCP)"
+	} else {
+		status.DNSAssignment = "手动"
+	}
+
+	// 同时测试网关和DNS连通性
+	status.probe(config.DNSServers, b.pinger.PingAll)
+
+	return status, nil
+}
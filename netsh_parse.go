@@ -0,0 +1,336 @@
+package main
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// IPv4Address 接口上的一个IPv4地址
+type IPv4Address struct {
+	IP        string // IP地址
+	PrefixLen int    // 前缀长度, 未知时为 -1
+	Mask      string // 子网掩码
+}
+
+// GatewayInfo 默认网关及其跃点数
+type GatewayInfo struct {
+	Address string // 网关地址
+	Metric  int    // 网关跃点数, 未知时为 -1
+}
+
+// InterfaceConfig `netsh interface ip show config` 中一个接口的配置
+type InterfaceConfig struct {
+	Name            string        // 接口名称
+	DHCP            bool          // 是否启用DHCP
+	Addresses       []IPv4Address // IPv4地址
+	Gateways        []GatewayInfo // 默认网关
+	InterfaceMetric int           // 接口跃点数, 未知时为 -1
+	DNSServers      []string      // DNS服务器
+	DNSSource       string        // DNS来源: dhcp 或 static, 没有DNS信息时为空
+	WINSServers     []string      // WINS服务器
+	WINSSource      string        // WINS来源: dhcp 或 static, 没有WINS信息时为空
+}
+
+// HasAddress 接口上是否有指定IP地址（精确匹配）
+func (c *InterfaceConfig) HasAddress(ip string) bool {
+	for _, addr := range c.Addresses {
+		if addr.IP == ip {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAddressWithMask 接口上是否有指定IP地址且子网掩码相同
+func (c *InterfaceConfig) HasAddressWithMask(ip, subnetMask string) bool {
+	prefix, err := maskToPrefix(subnetMask)
+	if err != nil {
+		return false
+	}
+	for _, addr := range c.Addresses {
+		if addr.IP != ip {
+			continue
+		}
+		if addr.PrefixLen >= 0 {
+			return addr.PrefixLen == prefix
+		}
+		return addr.Mask == subnetMask
+	}
+	return false
+}
+
+// HasGateway 是否配置了指定默认网关
+func (c *InterfaceConfig) HasGateway(gateway string) bool {
+	for _, gw := range c.Gateways {
+		if gw.Address == gateway {
+			return true
+		}
+	}
+	return false
+}
+
+// HasDNS 是否配置了指定DNS服务器
+func (c *InterfaceConfig) HasDNS(dns string) bool {
+	for _, server := range c.DNSServers {
+		if server == dns {
+			return true
+		}
+	}
+	return false
+}
+
+// netshConfigField show config 输出中的字段
+type netshConfigField int
+
+const (
+	fieldUnknown netshConfigField = iota
+	fieldDHCP
+	fieldIPAddress
+	fieldSubnetPrefix
+	fieldDefaultGateway
+	fieldGatewayMetric
+	fieldInterfaceMetric
+	fieldDHCPDNS
+	fieldStaticDNS
+	fieldDHCPWINS
+	fieldStaticWINS
+)
+
+// parseSubnetPrefix 解析 "192.168.31.0/24 (mask 255.255.255.0)" 形式的子网前缀
+func parseSubnetPrefix(value string) (prefixLen int, mask string) {
+	prefixLen = -1
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return prefixLen, ""
+	}
+	if idx := strings.Index(fields[0], "/"); idx != -1 {
+		if n, err := strconv.Atoi(fields[0][idx+1:]); err == nil {
+			prefixLen = n
+		}
+	}
+	if len(fields) >= 3 {
+		mask = strings.TrimRight(fields[len(fields)-1], ")")
+	}
+	return prefixLen, mask
+}
+
+// parseMetric 解析跃点数
+func parseMetric(value string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// ParseNetshIPConfig 解析 `netsh interface ip show config [name=接口]` 的输出
+// 字段名称从关键字目录中查找；多个值的字段（如多个DNS服务器）在后续行中只包含值，会归入上一个字段
+func ParseNetshIPConfig(output string, catalog KeywordCatalog) []*InterfaceConfig {
+	var configs []*InterfaceConfig
+	var current *InterfaceConfig
+	var field netshConfigField
+	var gatewayMetrics []int
+
+	// finish 将网关与跃点数按顺序配对
+	finish := func() {
+		if current == nil {
+			return
+		}
+		for i := range current.Gateways {
+			if i < len(gatewayMetrics) {
+				current.Gateways[i].Metric = gatewayMetrics[i]
+			}
+		}
+		gatewayMetrics = nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		if name, ok := catalog.InterfaceHeader(trimmed); ok {
+			finish()
+			current = &InterfaceConfig{Name: name, InterfaceMetric: -1}
+			configs = append(configs, current)
+			field = fieldUnknown
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		value := trimmed
+		if idx := strings.Index(trimmed, ":"); idx != -1 {
+			field = catalog.Field(strings.TrimSpace(trimmed[:idx]))
+			value = strings.TrimSpace(trimmed[idx+1:])
+		}
+		if value == "" {
+			continue
+		}
+
+		switch field {
+		case fieldDHCP:
+			current.DHCP = catalog.IsYes(value)
+		case fieldIPAddress:
+			current.Addresses = append(current.Addresses, IPv4Address{IP: value, PrefixLen: -1})
+		case fieldSubnetPrefix:
+			if n := len(current.Addresses); n > 0 {
+				current.Addresses[n-1].PrefixLen, current.Addresses[n-1].Mask = parseSubnetPrefix(value)
+			}
+		case fieldDefaultGateway:
+			current.Gateways = append(current.Gateways, GatewayInfo{Address: value, Metric: -1})
+		case fieldGatewayMetric:
+			gatewayMetrics = append(gatewayMetrics, parseMetric(value))
+		case fieldInterfaceMetric:
+			current.InterfaceMetric = parseMetric(value)
+		case fieldDHCPDNS, fieldStaticDNS:
+			current.DNSSource = "static"
+			if field == fieldDHCPDNS {
+				current.DNSSource = "dhcp"
+			}
+			if !catalog.IsNone(value) {
+				current.DNSServers = append(current.DNSServers, value)
+			}
+		case fieldDHCPWINS, fieldStaticWINS:
+			current.WINSSource = "static"
+			if field == fieldDHCPWINS {
+				current.WINSSource = "dhcp"
+			}
+			if !catalog.IsNone(value) {
+				current.WINSServers = append(current.WINSServers, value)
+			}
+		}
+	}
+	finish()
+
+	return configs
+}
+
+// parseNetshInterfaceLine 解析 `netsh interface show interface` 的一行
+// 各列依次为: 管理员状态 状态 类型 接口名称, 接口名称中可能包含空格, 如 "Wi-Fi 2"、"以太网 2"
+func parseNetshInterfaceLine(line string) (state, ifType, name string, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return "", "", "", false
+	}
+
+	// 跳过前三列, 剩余部分为接口名称
+	rest := line
+	for _, field := range fields[:3] {
+		rest = strings.TrimLeft(rest, " \t")
+		rest = rest[len(field):]
+	}
+	return fields[1], fields[2], strings.TrimSpace(rest), true
+}
+
+// NetshRoute `netsh interface ipv4 show route` 中的一条路由
+type NetshRoute struct {
+	Prefix  string // 目标前缀, 如 0.0.0.0/1
+	Metric  int    // 路由跃点数, 不包含接口跃点数
+	Index   int    // 接口索引
+	Gateway string // 网关, 直连路由时为接口名称
+}
+
+// ParseNetshRoutes 解析 `netsh interface ipv4 show route` 的输出
+// 表头和发布、类型两列随系统语言变化, 只按列的内容识别路由行: 第3列为跃点数, 第4列为前缀, 第5列为接口索引
+func ParseNetshRoutes(output string) []NetshRoute {
+	var routes []NetshRoute
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(fields[3]); err != nil {
+			continue
+		}
+		metric, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		index, err := strconv.Atoi(fields[4])
+		if err != nil {
+			continue
+		}
+		routes = append(routes, NetshRoute{Prefix: fields[3], Metric: metric, Index: index, Gateway: strings.Join(fields[5:], " ")})
+	}
+	return routes
+}
+
+// NetshWlan `netsh wlan show interfaces` 中一个无线网卡的连接信息
+type NetshWlan struct {
+	Name  string // 网卡名称
+	SSID  string // 当前连接的WiFi名称, 未连接时为空
+	BSSID string // 当前接入点MAC地址
+}
+
+// parseNetshWlans 解析 `netsh wlan show interfaces` 的输出，返回各无线网卡的连接信息
+// 各网卡的信息以空行分隔, 第一个字段为网卡名称; 字段名随系统语言变化, 按位置识别名称,
+// 托管网络状态等不含 SSID 和 GUID 的段落被忽略
+// SSID 中可能包含冒号，只按第一个冒号拆分; 新版本系统中 BSSID 显示为 "AP BSSID"
+func parseNetshWlans(output string) []NetshWlan {
+	var wlans []NetshWlan
+	var current *NetshWlan
+	isInterface := false
+	flush := func() {
+		if current != nil && isInterface {
+			wlans = append(wlans, *current)
+		}
+		current, isInterface = nil, false
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+		if current == nil {
+			if value == "" {
+				// "There are 2 interfaces on the system:" 之类的标题行
+				continue
+			}
+			current = &NetshWlan{Name: value}
+			continue
+		}
+		switch {
+		case key == "GUID":
+			isInterface = true
+		case key == "SSID":
+			current.SSID = value
+		case strings.HasSuffix(key, "BSSID") && current.BSSID == "":
+			current.BSSID = value
+		}
+	}
+	flush()
+	return wlans
+}
+
+// parseNetshWlan 解析 `netsh wlan show interfaces` 的输出，返回网卡 iface 的SSID和BSSID
+func parseNetshWlan(output, iface string) (ssid, bssid string) {
+	for _, wlan := range parseNetshWlans(output) {
+		if strings.EqualFold(wlan.Name, iface) {
+			return wlan.SSID, wlan.BSSID
+		}
+	}
+	return "", ""
+}
+
+// parseArpMAC 解析 `arp -a <ip>` 的输出，返回该地址对应的MAC地址
+func parseArpMAC(output, ip string) string {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == ip {
+			return normalizeMAC(fields[1])
+		}
+	}
+	return ""
+}
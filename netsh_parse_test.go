@@ -0,0 +1,153 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// showConfigWant testdata/netsh/show_config_*.txt 中各接口的解析结果, ethernet 为第一个接口的名称
+func showConfigWant(ethernet string) []*InterfaceConfig {
+	return []*InterfaceConfig{
+		{
+			Name:            ethernet,
+			DHCP:            true,
+			Addresses:       []IPv4Address{{IP: "192.168.31.100", PrefixLen: 24, Mask: "255.255.255.0"}},
+			Gateways:        []GatewayInfo{{Address: "192.168.31.1", Metric: 0}},
+			InterfaceMetric: 25,
+			DNSServers:      []string{"192.168.31.1"},
+			DNSSource:       "dhcp",
+			WINSSource:      "dhcp",
+		},
+		{
+			Name: "WLAN",
+			Addresses: []IPv4Address{
+				{IP: "192.168.1.50", PrefixLen: 24, Mask: "255.255.255.0"},
+				{IP: "10.0.0.5", PrefixLen: 8, Mask: "255.0.0.0"},
+			},
+			Gateways: []GatewayInfo{
+				{Address: "192.168.1.2", Metric: 0},
+				{Address: "192.168.1.1", Metric: 256},
+			},
+			InterfaceMetric: 35,
+			DNSServers:      []string{"192.168.1.2", "223.5.5.5"},
+			DNSSource:       "static",
+			WINSServers:     []string{"192.168.1.5"},
+			WINSSource:      "static",
+		},
+		{
+			Name:            "Loopback Pseudo-Interface 1",
+			Addresses:       []IPv4Address{{IP: "127.0.0.1", PrefixLen: 8, Mask: "255.0.0.0"}},
+			InterfaceMetric: 75,
+			DNSSource:       "static",
+			WINSSource:      "static",
+		},
+	}
+}
+
+func TestParseNetshIPConfig(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		locale   string
+		ethernet string
+	}{
+		{"netsh/show_config_en.txt", "en", "Ethernet 2"},
+		{"netsh/show_config_en.txt", "auto", "Ethernet 2"},
+		{"netsh/show_config_zh-CN.txt", "zh-CN", "以太网 2"},
+		{"netsh/show_config_zh-CN.txt", "auto", "以太网 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture+"/"+tt.locale, func(t *testing.T) {
+			catalog, err := NewKeywordCatalog(tt.locale)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ParseNetshIPConfig(readFixture(t, tt.fixture), catalog)
+			want := showConfigWant(tt.ethernet)
+			if len(got) != len(want) {
+				t.Fatalf("解析出 %d 个接口, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if !reflect.DeepEqual(got[i], want[i]) {
+					t.Errorf("接口 %d = %+v, want %+v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestInterfaceConfigHasAddress(t *testing.T) {
+	catalog, _ := NewKeywordCatalog("en")
+	config := ParseNetshIPConfig(readFixture(t, "netsh/show_config_en.txt"), catalog)[0]
+
+	tests := []struct {
+		ip       string
+		mask     string
+		wantIP   bool
+		wantMask bool
+	}{
+		{"192.168.31.100", "255.255.255.0", true, true},
+		{"192.168.31.10", "255.255.255.0", false, false}, // 192.168.31.100 的前缀, 不能误判为已配置
+		{"192.168.31.1", "255.255.255.0", false, false},
+		{"192.168.31.100", "255.255.0.0", true, false},
+	}
+	for _, tt := range tests {
+		if got := config.HasAddress(tt.ip); got != tt.wantIP {
+			t.Errorf("HasAddress(%s) = %v, want %v", tt.ip, got, tt.wantIP)
+		}
+		if got := config.HasAddressWithMask(tt.ip, tt.mask); got != tt.wantMask {
+			t.Errorf("HasAddressWithMask(%s, %s) = %v, want %v", tt.ip, tt.mask, got, tt.wantMask)
+		}
+	}
+	if !config.HasGateway("192.168.31.1") || config.HasGateway("192.168.31.10") {
+		t.Error("HasGateway 应精确匹配网关地址")
+	}
+	if !config.HasDNS("192.168.31.1") || config.HasDNS("192.168.31.") {
+		t.Error("HasDNS 应精确匹配DNS地址")
+	}
+}
+
+func TestParseNetshWlans(t *testing.T) {
+	output := readFixture(t, "netsh/wlan_show_interfaces_en.txt")
+	want := []NetshWlan{
+		{Name: "Wi-Fi", SSID: "Cafe: 2F", BSSID: "10:20:30:40:50:60"},
+		{Name: "Wi-Fi 2", SSID: "HomeWiFi", BSSID: "AA-BB-CC-DD-EE-FF"},
+		{Name: "Wi-Fi 3"},
+	}
+	if got := parseNetshWlans(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNetshWlans() = %+v, want %+v", got, want)
+	}
+
+	tests := []struct {
+		iface string
+		ssid  string
+		bssid string
+	}{
+		{"Wi-Fi", "Cafe: 2F", "10:20:30:40:50:60"},
+		{"Wi-Fi 2", "HomeWiFi", "AA-BB-CC-DD-EE-FF"},
+		{"Wi-Fi 3", "", ""},
+		{"Ethernet", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.iface, func(t *testing.T) {
+			ssid, bssid := parseNetshWlan(output, tt.iface)
+			if ssid != tt.ssid || bssid != tt.bssid {
+				t.Errorf("parseNetshWlan(%q) = %q, %q, want %q, %q", tt.iface, ssid, bssid, tt.ssid, tt.bssid)
+			}
+		})
+	}
+}
+
+func TestNetshGetCurrentWiFiNamePerInterface(t *testing.T) {
+	runner := &stubRunner{outputs: map[string]string{
+		"netsh wlan show interfaces": readFixture(t, "netsh/wlan_show_interfaces_en.txt"),
+	}}
+	catalog, _ := NewKeywordCatalog("en")
+	b := NewNetshBackend(runner, catalog)
+
+	if ssid, err := b.GetCurrentWiFiName("Wi-Fi 2"); err != nil || ssid != "HomeWiFi" {
+		t.Errorf("GetCurrentWiFiName(Wi-Fi 2) = %q, %v, want HomeWiFi", ssid, err)
+	}
+	if ssid, err := b.GetCurrentWiFiName("Wi-Fi 3"); err == nil {
+		t.Errorf("未连接的网卡应返回错误, got %q", ssid)
+	}
+}
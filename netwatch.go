@@ -0,0 +1,105 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// 网络变化通知的合并时间
+const (
+	DefaultWatchDebounce = 2 * time.Second  // 最后一次通知后等待2秒没有新的通知才触发检查, 连接WiFi时会在短时间内产生多次变化
+	DefaultWatchMaxDelay = 10 * time.Second // 通知持续不断时最多等待10秒也触发一次检查
+)
+
+// NetworkWatcher 监听系统的网络变化通知(网卡连接断开、IPv4地址变化),
+// 合并短时间内的多次通知后通过 Changes 发出一个信号
+type NetworkWatcher struct {
+	debounce time.Duration
+	maxDelay time.Duration
+	events   chan struct{} // 系统通知, 缓冲为1, 多余的通知直接丢弃
+	changes  chan struct{} // 合并后的信号
+	done     chan struct{}
+	stop     func() // 停止系统通知
+	once     sync.Once
+}
+
+// NewNetworkWatcher 创建网络变化监听器
+func NewNetworkWatcher(debounce, maxDelay time.Duration) *NetworkWatcher {
+	return &NetworkWatcher{
+		debounce: debounce,
+		maxDelay: maxDelay,
+		events:   make(chan struct{}, 1),
+		changes:  make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start 开始监听, 系统不支持或订阅失败时返回错误, 此时只能定时检查
+func (w *NetworkWatcher) Start() error {
+	stop, err := watchNetworkChanges(w.notify)
+	if err != nil {
+		return err
+	}
+	w.stop = stop
+	go w.loop()
+	return nil
+}
+
+// Changes 网络发生变化时收到信号
+func (w *NetworkWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Close 停止监听
+func (w *NetworkWatcher) Close() {
+	w.once.Do(func() {
+		close(w.done)
+		if w.stop != nil {
+			w.stop()
+		}
+	})
+}
+
+// notify 收到一次系统通知, 不会阻塞系统回调
+func (w *NetworkWatcher) notify() {
+	select {
+	case w.events <- struct{}{}:
+	default:
+	}
+}
+
+// loop 合并通知: 等到 debounce 时间内没有新的通知, 或距第一次通知已过 maxDelay 时发出信号
+func (w *NetworkWatcher) loop() {
+	for {
+		select {
+		case <-w.events:
+		case <-w.done:
+			return
+		}
+
+		deadline := time.NewTimer(w.maxDelay)
+		quiet := time.NewTimer(w.debounce)
+	wait:
+		for {
+			select {
+			case <-w.events:
+				quiet.Reset(w.debounce)
+			case <-quiet.C:
+				break wait
+			case <-deadline.C:
+				break wait
+			case <-w.done:
+				quiet.Stop()
+				deadline.Stop()
+				return
+			}
+		}
+		quiet.Stop()
+		deadline.Stop()
+
+		select {
+		case w.changes <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,89 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// watchNetworkChanges 订阅 netlink 的网卡(RTNLGRP_LINK)和IPv4地址(RTNLGRP_IPV4_IFADDR)变化
+// 不订阅路由变化: 健康检查添加的临时路由会触发通知, 导致反复检查
+func watchNetworkChanges(notify func()) (stop func(), err error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_ROUTE)
+	if err != nil {
+		return nil, fmt.Errorf("创建netlink套接字失败: %v", err)
+	}
+	addr := &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: unix.RTMGRP_LINK | unix.RTMGRP_IPV4_IFADDR}
+	if err := unix.Bind(fd, addr); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("订阅netlink通知失败: %v", err)
+	}
+	// 定时从阻塞的读取中返回, 以便检查是否已停止
+	tv := unix.Timeval{Sec: 1}
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("设置netlink套接字失败: %v", err)
+	}
+
+	var stopped atomic.Bool
+	go func() {
+		defer unix.Close(fd)
+		// 无线网卡会频繁发送不改变状态的 RTM_NEWLINK(如扫描结果), 只在连接状态变化时通知
+		linkFlags := make(map[int32]uint32)
+		const stateFlags = unix.IFF_UP | unix.IFF_RUNNING | unix.IFF_LOWER_UP
+
+		buf := make([]byte, 1<<16)
+		for !stopped.Load() {
+			n, _, err := unix.Recvfrom(fd, buf, 0)
+			if err != nil {
+				if err == unix.EAGAIN || err == unix.EINTR {
+					continue
+				}
+				// 缓冲区溢出时丢失了部分通知, 直接触发一次检查
+				if err == unix.ENOBUFS {
+					notify()
+					continue
+				}
+				log.Printf("读取netlink通知失败: %v", err)
+				return
+			}
+
+			msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+			if err != nil {
+				continue
+			}
+			changed := false
+			for _, msg := range msgs {
+				switch msg.Header.Type {
+				case unix.RTM_NEWADDR, unix.RTM_DELADDR:
+					changed = true
+				case unix.RTM_NEWLINK, unix.RTM_DELLINK:
+					if len(msg.Data) < unix.SizeofIfInfomsg {
+						continue
+					}
+					info := (*unix.IfInfomsg)(unsafe.Pointer(&msg.Data[0]))
+					flags := info.Flags & stateFlags
+					old, known := linkFlags[info.Index]
+					if msg.Header.Type == unix.RTM_DELLINK {
+						delete(linkFlags, info.Index)
+						changed = true
+					} else if !known || old != flags {
+						linkFlags[info.Index] = flags
+						changed = true
+					}
+				}
+			}
+			if changed {
+				notify()
+			}
+		}
+	}()
+
+	return func() { stopped.Store(true) }, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+
+package main
+
+import "fmt"
+
+// watchNetworkChanges 其他系统不支持网络变化通知, 只能定时检查
+func watchNetworkChanges(notify func()) (stop func(), err error) {
+	return nil, fmt.Errorf("当前系统不支持网络变化通知")
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// startTestWatcher 不订阅系统通知, 直接运行合并循环, 返回的通道在循环退出时关闭
+func startTestWatcher(t *testing.T, debounce, maxDelay time.Duration) (*NetworkWatcher, <-chan struct{}) {
+	t.Helper()
+	w := NewNetworkWatcher(debounce, maxDelay)
+	exited := make(chan struct{})
+	go func() {
+		w.loop()
+		close(exited)
+	}()
+	t.Cleanup(w.Close)
+	return w, exited
+}
+
+func TestNetworkWatcherDebouncesBurst(t *testing.T) {
+	w, _ := startTestWatcher(t, 50*time.Millisecond, time.Second)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		w.notify()
+		time.Sleep(10 * time.Millisecond)
+	}
+	last := time.Now()
+
+	select {
+	case <-w.Changes():
+	case <-time.After(time.Second):
+		t.Fatal("一组通知之后没有发出信号")
+	}
+	if waited := time.Since(last); waited < 40*time.Millisecond {
+		t.Errorf("最后一次通知后 %v 就发出信号, 应等待 debounce", waited)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("通知停止后 %v 才发出信号, 应在 debounce 后发出", elapsed)
+	}
+
+	// 一组通知只发出一个信号
+	select {
+	case <-w.Changes():
+		t.Error("一组通知发出了多个信号")
+	case <-time.After(150 * time.Millisecond):
+	}
+}
+
+func TestNetworkWatcherMaxDelay(t *testing.T) {
+	w, _ := startTestWatcher(t, 100*time.Millisecond, 300*time.Millisecond)
+
+	// 通知间隔小于 debounce, 持续不断
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			w.notify()
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	start := time.Now()
+	select {
+	case <-w.Changes():
+	case <-time.After(2 * time.Second):
+		t.Fatal("通知持续不断时没有在 maxDelay 后发出信号")
+	}
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("通知持续不断时 %v 就发出信号, 应等到 maxDelay", elapsed)
+	}
+}
+
+func TestNetworkWatcherClose(t *testing.T) {
+	w, exited := startTestWatcher(t, time.Hour, time.Hour)
+
+	// 等待合并期间关闭也能结束
+	w.notify()
+	time.Sleep(10 * time.Millisecond)
+	w.Close()
+	w.Close()
+
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("Close 之后合并循环没有结束")
+	}
+	select {
+	case <-w.Changes():
+		t.Error("关闭时不应发出信号")
+	default:
+	}
+}
@@ -0,0 +1,49 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/sys/windows"
+)
+
+// 系统回调数量有限, 回调函数只创建一次, 通过 watchNotify 转发给当前的监听器
+var (
+	watchMu       sync.Mutex
+	watchNotify   func()
+	watchCallback = windows.NewCallback(func(callerContext, row, notificationType uintptr) uintptr {
+		watchMu.Lock()
+		notify := watchNotify
+		watchMu.Unlock()
+		if notify != nil {
+			notify()
+		}
+		return 0
+	})
+)
+
+// watchNetworkChanges 订阅网卡状态(NotifyIpInterfaceChange)和IPv4地址(NotifyUnicastIpAddressChange)变化
+func watchNetworkChanges(notify func()) (stop func(), err error) {
+	watchMu.Lock()
+	watchNotify = notify
+	watchMu.Unlock()
+
+	var interfaceHandle, addressHandle windows.Handle
+	if err := windows.NotifyIpInterfaceChange(windows.AF_INET, watchCallback, nil, false, &interfaceHandle); err != nil {
+		return nil, fmt.Errorf("订阅网卡变化通知失败: %v", err)
+	}
+	if err := windows.NotifyUnicastIpAddressChange(windows.AF_INET, watchCallback, nil, false, &addressHandle); err != nil {
+		windows.CancelMibChangeNotify2(interfaceHandle)
+		return nil, fmt.Errorf("订阅地址变化通知失败: %v", err)
+	}
+
+	return func() {
+		windows.CancelMibChangeNotify2(interfaceHandle)
+		windows.CancelMibChangeNotify2(addressHandle)
+		watchMu.Lock()
+		watchNotify = nil
+		watchMu.Unlock()
+	}, nil
+}
@@ -1,300 +1,385 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"os"
 	"os/exec"
+	"runtime"
+	"strconv"
 	"strings"
 )
 
-// GetActiveInterface 获取活动网络接口名称
-func GetActiveInterface() (string, error) {
-	// 使用netsh命令获取网络接口信息
-	cmd := exec.Command("netsh", "interface", "show", "interface")
-	hideCmdWindow(cmd)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
+// ErrLocationPermission 位置服务被禁用，无法读取WiFi信息
+var ErrLocationPermission = errors.New("需要开启位置服务才能获取WiFi信息")
+
+// 网卡类型
+const (
+	InterfaceWiFi     = "wifi"     // 无线网卡
+	InterfaceEthernet = "ethernet" // 有线网卡
+	InterfaceBoth     = "both"     // 无线和有线网卡
+)
+
+// 静态IP时的IPv6处理方式
+const (
+	IPv6Keep    = ""        // 不处理IPv6, 保持系统的自动配置
+	IPv6Static  = "static"  // 设置静态IPv6地址、网关和DNS
+	IPv6Disable = "disable" // 停止接受路由通告和IPv6 DNS, 流量和DNS查询都经过IPv4旁路由
+)
+
+// 网络配置切换到旁路由的方式
+const (
+	StrategyAddress = "address" // 修改IP地址为静态IP, 网关和DNS指向旁路由(默认)
+	StrategyMetric  = "metric"  // 保留DHCP地址, 添加一条经过旁路由、跃点数更小的默认路由并设置DNS
+)
+
+// Route 网卡上经过网关的一条IPv4路由
+type Route struct {
+	Destination string // 目标 CIDR, 默认路由为 0.0.0.0/0
+	Gateway     string // 网关
+	Metric      int    // 跃点数, 越小越优先
+}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		// 查找已连接的网络接口 (支持中英文环境)
-		if (strings.Contains(line, "Connected") || strings.Contains(line, "已连接")) &&
-			(strings.Contains(line, "Dedicated") || strings.Contains(line, "专用")) {
-			fields := strings.Fields(line)
-			if len(fields) >= 4 {
-				return fields[len(fields)-1], nil
+// changedDefaultRoutes 返回 routes 中跃点数与 defaults 记录的不同的默认路由, 跃点数为 defaults 中记录的值
+func changedDefaultRoutes(routes, defaults []Route) []Route {
+	var changed []Route
+	for _, route := range routes {
+		if route.Destination != "0.0.0.0/0" {
+			continue
+		}
+		for _, original := range defaults {
+			if original.Destination == route.Destination && original.Gateway == route.Gateway && original.Metric != route.Metric {
+				changed = append(changed, original)
 			}
 		}
 	}
-
-	return "", fmt.Errorf("未找到活动网络接口")
+	return changed
 }
 
-// GetCurrentIPConfig 检查当前网络接口是否为DHCP模式
-func GetCurrentIPConfig(iface string) (isDHCP bool, err error) {
-	// 使用netsh命令获取接口IP配置
-	cmd := exec.Command("netsh", "interface", "ip", "show", "config", iface)
-	hideCmdWindow(cmd)
-	output, err := cmd.Output()
-	if err != nil {
-		return false, err
+// hasRoute 路由列表中是否有经过指定网关到达目标的路由
+func hasRoute(routes []Route, destination, gateway string) bool {
+	for _, route := range routes {
+		if route.Destination == destination && route.Gateway == gateway {
+			return true
+		}
 	}
+	return false
+}
 
-	// 检查输出中是否包含DHCP相关信息
-	outputStr := string(output)
-	if strings.Contains(outputStr, "DHCP enabled") || strings.Contains(outputStr, "DHCP 已启用") {
-		lines := strings.Split(outputStr, "\n")
-		for _, line := range lines {
-			if strings.Contains(line, "DHCP enabled") || strings.Contains(line, "DHCP 已启用") {
-				if strings.Contains(line, "Yes") || strings.Contains(line, "是") {
-					return true, nil
-				}
-				break
-			}
+// preferredGateway 网卡上有多条默认路由且跃点数最小的只有一条时, 返回该路由的网关
+func preferredGateway(routes []Route) string {
+	var best *Route
+	count, tie := 0, false
+	for i, route := range routes {
+		if route.Destination != "0.0.0.0/0" {
+			continue
+		}
+		count++
+		switch {
+		case best == nil || route.Metric < best.Metric:
+			best, tie = &routes[i], false
+		case route.Metric == best.Metric:
+			tie = true
 		}
 	}
-
-	return false, nil
+	if count < 2 || tie {
+		return ""
+	}
+	return best.Gateway
 }
 
-// GetCurrentStaticIPConfig 检查当前网络接口是否已经是目标静态IP配置
-func GetCurrentStaticIPConfig(iface, staticIP, gateway, dns string) (isStatic bool, err error) {
-	// 使用netsh命令获取接口IP配置
-	cmd := exec.Command("netsh", "interface", "ip", "show", "config", iface)
-	hideCmdWindow(cmd)
-	output, err := cmd.Output()
-	if err != nil {
-		return false, err
-	}
+// overrideRoutes 覆盖默认路由使用的两条路由
+// 它们合起来覆盖全部IPv4地址, 又比DHCP下发的 0.0.0.0/0 更具体, 不需要修改DHCP默认路由的跃点数就能优先使用
+var overrideRoutes = []string{"0.0.0.0/1", "128.0.0.0/1"}
+
+// IPv4Config 网络接口当前的IPv4配置, 切换前保存, 切换失败时用于恢复
+type IPv4Config struct {
+	DHCP       bool     // 地址是否由DHCP分配
+	IP         string   // IPv4地址
+	SubnetMask string   // 子网掩码
+	Gateway    string   // 默认网关
+	DNS        []string // DNS服务器, 按优先顺序
+	StaticDNS  bool     // DNS是否为手动设置
+}
 
-	// 将输出转换为字符串
-	outputStr := string(output)
+// IPv6Address 接口上的一个IPv6地址
+type IPv6Address struct {
+	IP        string // IPv6地址
+	PrefixLen int    // 前缀长度
+	Manual    bool   // 是否为手动设置的地址
+}
 
-	// 检查是否为静态IP配置
-	isDHCP, _ := GetCurrentIPConfig(iface)
-	if isDHCP {
-		// 如果当前是DHCP模式，则肯定不是目标静态IP配置
-		return false, nil
-	}
+// IPv6Config 网络接口当前的IPv6配置, 不包含链路本地地址
+type IPv6Config struct {
+	Addresses  []IPv6Address // IPv6地址
+	Gateways   []string      // IPv6默认网关
+	DNSServers []string      // IPv6 DNS服务器
+}
 
-	// 检查IP地址、网关和DNS是否匹配目标配置
-	if strings.Contains(outputStr, staticIP) &&
-		strings.Contains(outputStr, gateway) &&
-		strings.Contains(outputStr, dns) {
-		return true, nil
-	}
+// NetworkInterface 网卡信息
+type NetworkInterface struct {
+	Name      string // 网卡名称, 如 "WLAN"、"以太网 2"、wlan0
+	Index     int    // 接口索引
+	Type      string // 网卡类型: wifi 或 ethernet
+	MAC       string // 网卡MAC地址
+	Connected bool   // 是否已连接
+}
 
-	return false, nil
+// NetworkBackend 网络操作后端
+// 切换逻辑只依赖此接口，具体实现可以是 netsh、nmcli，或者测试用的内存实现
+type NetworkBackend interface {
+	// ListInterfaces 列出所有无线和有线网卡
+	ListInterfaces() ([]NetworkInterface, error)
+	// GetActiveInterface 获取活动网络接口名称, types 为管理的网卡类型: wifi, ethernet, both
+	GetActiveInterface(types string) (string, error)
+	// GetCurrentIPConfig 检查当前网络接口是否为DHCP模式
+	GetCurrentIPConfig(iface string) (isDHCP bool, err error)
+	// GetCurrentStaticIPConfig 检查当前网络接口是否已经是目标静态IP配置
+	// 子网掩码不同时视为不是目标配置, 以便修改掩码后重新设置
+	GetCurrentStaticIPConfig(iface, staticIP, subnetMask, gateway string, dns []string) (isStatic bool, err error)
+	// SetDHCP 设置网络接口为DHCP模式
+	SetDHCP(iface string) error
+	// SetStaticIP 设置网络接口为静态IP模式
+	// dns 按优先顺序排列, 第一个为首选DNS
+	SetStaticIP(iface, ip, subnetMask, gateway string, dns []string) error
+	// SetDNS 设置网络接口的静态DNS, 不改动地址; dns 为空时不使用任何DNS服务器
+	SetDNS(iface string, dns []string) error
+	// GetIPv4Config 获取网络接口当前的IPv4地址、子网掩码、网关和DNS
+	GetIPv4Config(iface string) (*IPv4Config, error)
+	// GetIPv6Config 获取网络接口当前的IPv6地址、网关和DNS
+	GetIPv6Config(iface string) (*IPv6Config, error)
+	// SetStaticIPv6 设置静态IPv6地址(带前缀长度)、网关和DNS, 同时停止接受路由通告
+	// dns 为空时不使用IPv6 DNS
+	SetStaticIPv6(iface, address, gateway string, dns []string) error
+	// DisableIPv6Auto 停止接受路由通告和DHCPv6, 清除IPv6默认网关和DNS
+	DisableIPv6Auto(iface string) error
+	// ResetIPv6 恢复自动获取IPv6配置, 删除手动设置的IPv6地址
+	ResetIPv6(iface string) error
+	// AddRoute 添加一条经过指定网关的临时路由(重启后失效), destination 为 CIDR, 如 1.2.3.4/32
+	AddRoute(iface, destination, gateway string) error
+	// DeleteRoute 删除 AddRoute 添加的路由
+	DeleteRoute(iface, destination, gateway string) error
+	// ListRoutes 列出网卡上经过网关的IPv4路由
+	ListRoutes(iface string) ([]Route, error)
+	// SetPreferredGateway 保留DHCP分配的地址, 添加一条经过 gateway 的默认路由并设置静态DNS
+	// 该路由的跃点数小于网卡上其他默认路由, 流量优先经过 gateway; 切换时只改路由, 不重新分配地址
+	SetPreferredGateway(iface, gateway string, dns []string) error
+	// ClearPreferredGateway 删除 SetPreferredGateway 添加的默认路由, 恢复DHCP下发的DNS
+	// defaults 为添加前网卡上的其他默认路由, 被调整过跃点数的默认路由按它恢复原来的跃点数
+	ClearPreferredGateway(iface, gateway string, defaults []Route) error
+	// GetRouteOverride 获取 SetRouteOverride 设置的网关, 没有覆盖默认路由时返回空字符串
+	GetRouteOverride(iface string) (gateway string, err error)
+	// SetRouteOverride 保留DHCP分配的地址, 添加经过 gateway 的 overrideRoutes 并设置静态DNS
+	// 流量和DNS查询都经过 gateway, 主路由的DHCP租约和地址保留仍然有效
+	SetRouteOverride(iface, gateway string, dns []string) error
+	// ClearRouteOverride 删除 SetRouteOverride 添加的路由, 恢复DHCP下发的DNS
+	ClearRouteOverride(iface string) error
+	// GetCurrentWiFiName 获取无线网卡 iface 当前连接的WiFi名称, 位置服务被禁用时返回 ErrLocationPermission
+	GetCurrentWiFiName(iface string) (string, error)
+	// GetNetworkFacts 获取判断当前所在网络所需的信息(SSID、BSSID、网关MAC等)
+	// 获取不到的信息留空; 位置服务被禁用时返回已获取的部分信息和 ErrLocationPermission
+	GetNetworkFacts(iface string) (*NetworkFacts, error)
+	// GetCurrentNetworkStatus 获取网络接口的详细状态
+	GetCurrentNetworkStatus(iface string) (*NetworkStatus, error)
+	// Ping 测试网络连通性, 发送多个ICMP探测包, 收到任一回复即为可达
+	Ping(host string) bool
 }
 
-// SetDHCP 设置网络接口为DHCP模式
-func SetDHCP(iface string) error {
-	// 设置为DHCP自动获取IP
-	cmd := exec.Command("netsh", "interface", "ip", "set", "address", iface, "dhcp")
-	hideCmdWindow(cmd)
-	err := cmd.Run()
-	if err != nil {
-		return fmt.Errorf("设置DHCP IP失败: %v", err)
+// NewBackend 根据配置中的 Backend 创建网络后端
+// Backend 为空或 auto 时按当前操作系统选择: Windows 使用 netsh，
+// Linux 优先使用 NetworkManager，没有 nmcli 时使用 iproute2/networkd
+func NewBackend(config *Config, runner CommandRunner) (NetworkBackend, error) {
+	switch config.Backend {
+	case "", "auto":
+		if runtime.GOOS != "linux" {
+			return newNetshBackendFromConfig(config, runner)
+		}
+		if _, err := exec.LookPath("nmcli"); err == nil {
+			return NewNmcliBackend(runner), nil
+		}
+		return NewNetworkdBackend(runner), nil
+	case "netsh":
+		return newNetshBackendFromConfig(config, runner)
+	case "nmcli":
+		return NewNmcliBackend(runner), nil
+	case "networkd":
+		return NewNetworkdBackend(runner), nil
+	default:
+		return nil, fmt.Errorf("未知的网络后端: %s", config.Backend)
 	}
+}
 
-	// 设置DNS为自动获取
-	cmd = exec.Command("netsh", "interface", "ip", "set", "dns", iface, "dhcp")
-	hideCmdWindow(cmd)
-	err = cmd.Run()
+// newNetshBackendFromConfig 按配置中的语言创建 netsh 网络后端
+func newNetshBackendFromConfig(config *Config, runner CommandRunner) (NetworkBackend, error) {
+	catalog, err := NewKeywordCatalog(config.Locale)
 	if err != nil {
-		return fmt.Errorf("设置DHCP DNS失败: %v", err)
+		return nil, err
 	}
-
-	return nil
+	return NewNetshBackend(runner, catalog), nil
 }
 
-// SetStaticIP 设置网络接口为静态IP模式
-func SetStaticIP(iface, ip, subnetMask, gateway, dns string) error {
-	// 设置静态IP地址、子网掩码和网关
-	cmd := exec.Command("netsh", "interface", "ip", "set", "address", iface, "static", ip, subnetMask, gateway)
-	hideCmdWindow(cmd)
-	err := cmd.Run()
-	if err != nil {
-		return fmt.Errorf("设置静态IP失败: %v", err)
+// matchesInterfaceTypes 网卡类型是否属于配置中管理的类型
+// 为 both 时接受所有无线和有线网卡
+func matchesInterfaceTypes(ifType, types string) bool {
+	switch types {
+	case InterfaceWiFi, InterfaceEthernet:
+		return ifType == types
+	default:
+		return ifType == InterfaceWiFi || ifType == InterfaceEthernet
 	}
+}
 
-	// 设置静态DNS服务器
-	cmd = exec.Command("netsh", "interface", "ip", "set", "dns", iface, "static", dns)
-	hideCmdWindow(cmd)
-	err = cmd.Run()
-	if err != nil {
-		return fmt.Errorf("设置静态DNS失败: %v", err)
+// findInterface 按网卡设置查找网卡, 设置了MAC地址时按MAC地址查找, 否则按名称查找
+func findInterface(ifaces []NetworkInterface, setting InterfaceSetting) *NetworkInterface {
+	for i := range ifaces {
+		if setting.MAC != "" {
+			if ifaces[i].MAC == normalizeMAC(setting.MAC) {
+				return &ifaces[i]
+			}
+		} else if ifaces[i].Name == setting.Name {
+			return &ifaces[i]
+		}
 	}
-
 	return nil
 }
 
-// Ping 测试网络连通性
-func Ping(host string) bool {
-	cmd := exec.Command("ping", "-n", "1", "-w", "3000", host)
-	hideCmdWindow(cmd)
-	err := cmd.Run()
-	return err == nil
+// linkType 根据 /sys/class/net 判断网卡类型, 无线网卡有 wireless 目录, 有线网卡的链路类型为 1 (ARPHRD_ETHER)
+func linkType(iface string) string {
+	base := "/sys/class/net/" + iface
+	if _, err := os.Stat(base + "/wireless"); err == nil {
+		return InterfaceWiFi
+	}
+	if data, err := os.ReadFile(base + "/type"); err == nil && strings.TrimSpace(string(data)) == "1" {
+		return InterfaceEthernet
+	}
+	return ""
 }
 
-// GetCurrentWiFiName 获取当前连接的WiFi名称
-func GetCurrentWiFiName() (string, error) {
-	cmd := exec.Command("netsh", "wlan", "show", "interfaces")
-	hideCmdWindow(cmd)
-	output, err := cmd.Output()
+// listSysfsInterfaces 从 /sys/class/net 列出 Linux 上的无线和有线网卡
+func listSysfsInterfaces() ([]NetworkInterface, error) {
+	entries, err := os.ReadDir("/sys/class/net")
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("读取网卡列表失败: %v", err)
 	}
 
-	outputStr := string(output)
-	lines := strings.Split(outputStr, "\n")
-
-	// 查找包含SSID的行
-	for _, line := range lines {
-		if strings.Contains(line, "SSID") && !strings.Contains(line, "BSSID") {
-			parts := strings.Split(line, ":")
-			if len(parts) >= 2 {
-				wifiName := strings.TrimSpace(parts[1])
-				return wifiName, nil
-			}
+	var ifaces []NetworkInterface
+	for _, entry := range entries {
+		iface := NetworkInterface{Name: entry.Name(), Type: linkType(entry.Name())}
+		if iface.Type == "" {
+			continue
+		}
+		base := "/sys/class/net/" + iface.Name
+		if data, err := os.ReadFile(base + "/ifindex"); err == nil {
+			iface.Index, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+		}
+		if data, err := os.ReadFile(base + "/address"); err == nil {
+			iface.MAC = normalizeMAC(string(data))
+		}
+		if data, err := os.ReadFile(base + "/operstate"); err == nil {
+			iface.Connected = strings.TrimSpace(string(data)) == "up"
 		}
+		ifaces = append(ifaces, iface)
 	}
-
-	return "", fmt.Errorf("未找到WiFi信息")
+	return ifaces, nil
 }
 
-// GetCurrentNetworkStatus 获取当前网络详细状态
-func GetCurrentNetworkStatus() (*NetworkStatus, error) {
-	status := &NetworkStatus{}
-
-	// 获取活动网络接口
-	iface, err := GetActiveInterface()
-	if err != nil {
-		return status, fmt.Errorf("获取网络接口失败: %v", err)
+// probe 同时检测网关和全部DNS服务器的连通性, 首选DNS同时写入 DNS 和 DNSReachable
+func (s *NetworkStatus) probe(dnsServers []string, ping func(hosts ...string) []*PingResult) {
+	hosts := dnsServers
+	if s.Gateway != "" {
+		hosts = append([]string{s.Gateway}, dnsServers...)
 	}
+	results := ping(hosts...)
 
-	// 获取WiFi名称
-	wifiName, err := GetCurrentWiFiName()
-	if err == nil {
-		status.WiFiName = wifiName
-		status.WiFiConnected = true
-	} else {
-		status.WiFiName = "未连接"
-		status.WiFiConnected = false
+	if s.Gateway != "" {
+		s.GatewayReachable = results[0].Reachable()
+		s.GatewayRTT = results[0].RTTMillis()
+		results = results[1:]
 	}
 
-	// 获取网络接口配置
-	cmd := exec.Command("netsh", "interface", "ip", "show", "config", iface)
-	hideCmdWindow(cmd)
-	output, err := cmd.Output()
-	if err != nil {
-		return status, fmt.Errorf("获取网络配置失败: %v", err)
+	s.DNSServers = make([]DNSServerStatus, len(results))
+	for i, result := range results {
+		s.DNSServers[i] = DNSServerStatus{Address: result.Host, Reachable: result.Reachable(), RTT: result.RTTMillis()}
 	}
+	if len(s.DNSServers) > 0 {
+		s.DNS = s.DNSServers[0].Address
+		s.DNSReachable = s.DNSServers[0].Reachable
+	}
+}
 
-	outputStr := string(output)
-	lines := strings.Split(outputStr, "\n")
-
-	// 解析IP配置信息
-	isIPDHCP := false
-	isDNSDHCP := false
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// 检查IP DHCP状态
-		if strings.Contains(line, "DHCP enabled") || strings.Contains(line, "DHCP 已启用") {
-			if strings.Contains(line, "Yes") || strings.Contains(line, "是") {
-				isIPDHCP = true
-			}
-		}
+// setIPv6 记录IPv6地址、网关和DNS
+func (s *NetworkStatus) setIPv6(config *IPv6Config) {
+	s.IPv6Addresses = nil
+	for _, addr := range config.Addresses {
+		s.IPv6Addresses = append(s.IPv6Addresses, fmt.Sprintf("%s/%d", addr.IP, addr.PrefixLen))
+	}
+	s.IPv6Gateways = config.Gateways
+	s.IPv6DNS = config.DNSServers
+}
 
-		// 提取IP地址
-		if strings.Contains(line, "IP 地址") || strings.Contains(line, "IP Address") {
-			parts := strings.Split(line, ":")
-			if len(parts) >= 2 {
-				ip := strings.TrimSpace(parts[1])
-				// 移除子网前缀信息（如果有）
-				if idx := strings.Index(ip, "/"); idx != -1 {
-					ip = ip[:idx]
-				}
-				if idx := strings.Index(ip, " "); idx != -1 {
-					ip = ip[:idx]
-				}
-				if ip != "" {
-					status.IPAddress = ip
-				}
-			}
+// ipv6Applied 当前IPv6配置是否已经符合网络配置的要求
+func ipv6Applied(config *IPv6Config, profile *Profile) bool {
+	switch profile.IPv6Mode {
+	case IPv6Static:
+		ip, _, err := net.ParseCIDR(profile.staticIPv6())
+		if err != nil {
+			return false
 		}
-
-		// 提取网关
-		if strings.Contains(line, "默认网关") || strings.Contains(line, "Default Gateway") {
-			parts := strings.Split(line, ":")
-			if len(parts) >= 2 {
-				gateway := strings.TrimSpace(parts[1])
-				// 移除跃点数信息（如果有）
-				if idx := strings.Index(gateway, " "); idx != -1 {
-					gateway = gateway[:idx]
-				}
-				if gateway != "" {
-					status.Gateway = gateway
-				}
+		hasAddress := false
+		for _, addr := range config.Addresses {
+			if ip.Equal(net.ParseIP(addr.IP)) {
+				hasAddress = true
 			}
 		}
+		return hasAddress &&
+			len(config.Gateways) == 1 && net.ParseIP(profile.GatewayIPv6).Equal(net.ParseIP(config.Gateways[0])) &&
+			equalIPs(config.DNSServers, profile.DNSv6)
+	case IPv6Disable:
+		return len(config.Gateways) == 0 && len(config.DNSServers) == 0
+	default:
+		return true
+	}
+}
 
-		// 检查DNS DHCP状态并提取DNS地址
-		if strings.Contains(line, "通过 DHCP 配置的 DNS") || strings.Contains(line, "DNS servers configured through DHCP") {
-			isDNSDHCP = true
-			// 提取DHCP配置的DNS地址
-			parts := strings.Split(line, ":")
-			if len(parts) >= 2 {
-				dns := strings.TrimSpace(parts[1])
-				// 移除其他信息（如果有）
-				if idx := strings.Index(dns, " "); idx != -1 {
-					dns = dns[:idx]
-				}
-				if dns != "" && dns != "无" && dns != "None" {
-					status.DNS = dns
-				}
-			}
-		} else if strings.Contains(line, "DNS 服务器") || strings.Contains(line, "DNS Servers") {
-			// 提取静态DNS配置
-			parts := strings.Split(line, ":")
-			if len(parts) >= 2 {
-				dns := strings.TrimSpace(parts[1])
-				// 移除其他信息（如果有）
-				if idx := strings.Index(dns, " "); idx != -1 {
-					dns = dns[:idx]
-				}
-				if dns != "" && dns != "无" && dns != "None" {
-					status.DNS = dns
-					// 如果找到静态DNS，则不是DHCP
-					isDNSDHCP = false
-				}
-			}
+// hasManualIPv6 IPv6配置中是否有手动设置的地址
+func hasManualIPv6(config *IPv6Config) bool {
+	for _, addr := range config.Addresses {
+		if addr.Manual {
+			return true
 		}
 	}
+	return false
+}
 
-	// 设置分配方式
-	if isIPDHCP {
-		status.IPAssignment = "自动(DHCP)"
-	} else {
-		status.IPAssignment = "手动"
+// equalIPs 两个地址列表是否相同, 忽略IPv6地址的书写差异(如前导零)
+func equalIPs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
 	}
-
-	if isDNSDHCP {
-		status.DNSAssignment = "自动(DHCP)"
-	} else {
-		status.DNSAssignment = "手动"
+	for i := range a {
+		if !net.ParseIP(a[i]).Equal(net.ParseIP(b[i])) {
+			return false
+		}
 	}
+	return true
+}
 
-	// 测试网关和DNS连通性
-	if status.Gateway != "" {
-		status.GatewayReachable = Ping(status.Gateway)
+// isIPv6 是否为IPv6地址
+func isIPv6(address string) bool {
+	ip := net.ParseIP(address)
+	return ip != nil && ip.To4() == nil
+}
+
+// equalStrings 两个列表的内容和顺序是否相同
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
 	}
-	if status.DNS != "" {
-		status.DNSReachable = Ping(status.DNS)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
 	}
-
-	return status, nil
+	return true
 }
@@ -0,0 +1,937 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// resolvConfPath 系统DNS配置文件
+	resolvConfPath = "/etc/resolv.conf"
+	// resolvConfBackupSuffix 接管 resolv.conf 前的备份文件后缀
+	resolvConfBackupSuffix = ".routerswitcher.bak"
+	// resolvConfMarker 由本程序写入的 resolv.conf 标记
+	resolvConfMarker = "# Managed by RouterSwitcher"
+	// networkdRuntimeDir networkd 的运行时配置目录, 重启后自动清空
+	networkdRuntimeDir = "/run/systemd/network"
+	// staticNetworkHeader 本程序写入的静态IP .network 配置的文件头
+	staticNetworkHeader = "# Generated by RouterSwitcher for the static IP profile, removed when switching back to DHCP"
+)
+
+// NetworkdBackend 基于 iproute2 + systemd-networkd 的网络后端，适用于没有 NetworkManager 的服务器
+// 静态模式在运行时目录写入关闭DHCP的 .network 配置交给 networkd 设置地址和默认路由，
+// 避免 networkd 的DHCP客户端续租时重新添加动态地址；networkd 没有运行时直接通过 ip addr / ip route 设置。
+// DNS 交给 systemd-resolved，没有 resolvectl 时接管 /etc/resolv.conf；
+// 切回DHCP时删除运行时配置，把链路交还给 networkd 重新配置
+type NetworkdBackend struct {
+	runner     CommandRunner
+	pinger     *Pinger
+	networkDir string // 写入静态IP配置的 networkd 运行时目录
+	resolvConf string // 没有 systemd-resolved 时接管的DNS配置文件
+	resolved   bool   // 是否可以通过 systemd-resolved 设置DNS
+}
+
+// NewNetworkdBackend 创建 iproute2/networkd 网络后端
+func NewNetworkdBackend(runner CommandRunner) *NetworkdBackend {
+	pinger := NewPinger(DefaultPingCount, DefaultPingTimeout)
+	pinger.Runner = runner
+	return &NetworkdBackend{
+		runner:     runner,
+		pinger:     pinger,
+		networkDir: networkdRuntimeDir,
+		resolvConf: resolvConfPath,
+		resolved:   hasResolvectl(),
+	}
+}
+
+// ipAddrEntry `ip -o addr show` 中的一条地址
+type ipAddrEntry struct {
+	Address string // IP地址
+	Prefix  string // 前缀长度
+	Dynamic bool   // 是否为DHCP分配的动态地址
+}
+
+// run 执行命令并返回标准输出
+func (b *NetworkdBackend) run(name string, args ...string) (string, error) {
+	result, err := runCmd(b.runner, name, args...)
+	if err != nil {
+		return "", fmt.Errorf("执行%s命令失败: %v. %s", name, err, strings.TrimSpace(result.Output()))
+	}
+	return string(result.Stdout), nil
+}
+
+// ipRoute `ip route show default` 中的一条默认路由
+type ipRoute struct {
+	Gateway string // 网关
+	Dev     string // 网络设备
+}
+
+// parseIPRouteDefaults 解析 `ip -o -4 route show default` 或 `ip -o -6 route show default` 的输出，返回全部默认路由
+func parseIPRouteDefaults(output string) []ipRoute {
+	var routes []ipRoute
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != "default" {
+			continue
+		}
+		var route ipRoute
+		for i := 0; i+1 < len(fields); i++ {
+			switch fields[i] {
+			case "via":
+				route.Gateway = fields[i+1]
+			case "dev":
+				route.Dev = fields[i+1]
+			}
+		}
+		if route.Dev != "" {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// parseIPRouteGateway 解析 `ip -o -4 route show <目标> dev <设备>` 的输出, 返回路由的网关, 没有路由时返回空字符串
+func parseIPRouteGateway(output string) string {
+	fields := strings.Fields(output)
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "via" {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
+
+// parseIPRoutes 解析 `ip -o -4 route show dev <设备>` 的输出, 返回经过网关的路由
+// 主机路由不带前缀长度, 补全为 /32; default 记为 0.0.0.0/0; 没有 metric 时跃点数为0
+func parseIPRoutes(output string) []Route {
+	var routes []Route
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		route := Route{Destination: fields[0], Gateway: parseIPRouteGateway(line)}
+		if route.Gateway == "" {
+			continue
+		}
+		if route.Destination == "default" {
+			route.Destination = "0.0.0.0/0"
+		} else if !strings.Contains(route.Destination, "/") {
+			route.Destination += "/32"
+		}
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] == "metric" {
+				route.Metric, _ = strconv.Atoi(fields[i+1])
+			}
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// listIPRoutes 通过 ip 命令列出网卡上经过网关的IPv4路由
+func listIPRoutes(runner CommandRunner, iface string) ([]Route, error) {
+	result, err := runCmd(runner, "ip", "-o", "-4", "route", "show", "dev", iface)
+	if err != nil {
+		return nil, fmt.Errorf("获取路由失败: %v. %s", err, strings.TrimSpace(result.Output()))
+	}
+	return parseIPRoutes(string(result.Stdout)), nil
+}
+
+// setIPPreferredGateway 通过 ip 命令添加一条经过 gateway 的默认路由, 跃点数比网卡上其他默认路由小
+// 其他默认路由的跃点数为0时先改为1, clearIPPreferredGateway 时改回
+func setIPPreferredGateway(runner CommandRunner, iface, gateway string) error {
+	routes, err := listIPRoutes(runner, iface)
+	if err != nil {
+		return err
+	}
+
+	metric := -1
+	for _, route := range routes {
+		if route.Destination != "0.0.0.0/0" {
+			continue
+		}
+		if route.Gateway == gateway {
+			// 删除之前添加的路由, 按其他默认路由重新计算跃点数
+			runCmd(runner, "ip", "route", "del", "default", "via", gateway, "dev", iface, "metric", strconv.Itoa(route.Metric))
+			continue
+		}
+		if route.Metric == 0 {
+			if result, err := runCmd(runner, "ip", "route", "append", "default", "via", route.Gateway, "dev", iface, "metric", "1"); err != nil {
+				return fmt.Errorf("调整默认路由跃点数失败: %v. %s", err, strings.TrimSpace(result.Output()))
+			}
+			runCmd(runner, "ip", "route", "del", "default", "via", route.Gateway, "dev", iface, "metric", "0")
+			route.Metric = 1
+		}
+		if metric < 0 || route.Metric-1 < metric {
+			metric = route.Metric - 1
+		}
+	}
+	if metric < 0 {
+		metric = 0
+	}
+
+	result, err := runCmd(runner, "ip", "route", "append", "default", "via", gateway, "dev", iface, "metric", strconv.Itoa(metric))
+	if err != nil {
+		return fmt.Errorf("添加默认路由失败: %v. %s", err, strings.TrimSpace(result.Output()))
+	}
+	return nil
+}
+
+// clearIPPreferredGateway 通过 ip 命令删除网卡上经过 gateway 的默认路由
+// 被调整过跃点数的默认路由按 defaults 改回原来的跃点数: 先添加原跃点数的路由, 再删除调整后的路由
+func clearIPPreferredGateway(runner CommandRunner, iface, gateway string, defaults []Route) error {
+	result, err := runCmd(runner, "ip", "route", "del", "default", "via", gateway, "dev", iface)
+	if err != nil {
+		return fmt.Errorf("删除默认路由失败: %v. %s", err, strings.TrimSpace(result.Output()))
+	}
+
+	routes, err := listIPRoutes(runner, iface)
+	if err != nil {
+		return err
+	}
+	for _, original := range changedDefaultRoutes(routes, defaults) {
+		if result, err := runCmd(runner, "ip", "route", "append", "default", "via", original.Gateway, "dev", iface, "metric", strconv.Itoa(original.Metric)); err != nil {
+			return fmt.Errorf("恢复默认路由跃点数失败: %v. %s", err, strings.TrimSpace(result.Output()))
+		}
+		for _, route := range routes {
+			if route.Destination == "0.0.0.0/0" && route.Gateway == original.Gateway && route.Metric != original.Metric {
+				runCmd(runner, "ip", "route", "del", "default", "via", route.Gateway, "dev", iface, "metric", strconv.Itoa(route.Metric))
+			}
+		}
+	}
+	return nil
+}
+
+// getIPRouteOverride 通过 ip 命令读取网卡上的 overrideRoutes, 各条路由经过同一网关时返回该网关
+func getIPRouteOverride(runner CommandRunner, iface string) (string, error) {
+	gateway := ""
+	for i, destination := range overrideRoutes {
+		result, err := runCmd(runner, "ip", "-o", "-4", "route", "show", destination, "dev", iface)
+		if err != nil {
+			return "", fmt.Errorf("获取路由失败: %v. %s", err, strings.TrimSpace(result.Output()))
+		}
+		via := parseIPRouteGateway(string(result.Stdout))
+		if via == "" || (i > 0 && via != gateway) {
+			return "", nil
+		}
+		gateway = via
+	}
+	return gateway, nil
+}
+
+// parseIPAddr 解析 `ip -o -4 addr show dev <iface>` 或 `ip -o -6 addr show ...` 的输出
+func parseIPAddr(output string) []ipAddrEntry {
+	var entries []ipAddrEntry
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] != "inet" && fields[i] != "inet6" {
+				continue
+			}
+			entry := ipAddrEntry{Address: fields[i+1]}
+			if idx := strings.Index(entry.Address, "/"); idx != -1 {
+				entry.Prefix = entry.Address[idx+1:]
+				entry.Address = entry.Address[:idx]
+			}
+			for _, flag := range fields[i+2:] {
+				if flag == "dynamic" {
+					entry.Dynamic = true
+				}
+			}
+			entries = append(entries, entry)
+			break
+		}
+	}
+	return entries
+}
+
+// parseResolvectlDNS 解析 `resolvectl dns <iface>` 的输出，如 "Link 2 (eth0): 192.168.31.2 fe80::1"
+func parseResolvectlDNS(output string) []string {
+	var servers []string
+	for _, line := range strings.Split(output, "\n") {
+		idx := strings.Index(line, "):")
+		if idx == -1 {
+			continue
+		}
+		servers = append(servers, strings.Fields(line[idx+2:])...)
+	}
+	return servers
+}
+
+// parseResolvConf 解析 resolv.conf 中的 nameserver
+func parseResolvConf(content string) []string {
+	var servers []string
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = append(servers, fields[1])
+		}
+	}
+	return servers
+}
+
+// parseIwSSID 解析 `iw dev <iface> link` 的输出，返回SSID
+func parseIwSSID(output string) (string, bool) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "SSID:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "SSID:")), true
+		}
+	}
+	return "", false
+}
+
+// parseIwBSSID 解析 `iw dev <iface> link` 的输出，返回接入点MAC地址, 如 "Connected to aa:bb:cc:dd:ee:ff (on wlan0)"
+func parseIwBSSID(output string) (string, bool) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == "Connected" && fields[1] == "to" {
+			return fields[2], true
+		}
+	}
+	return "", false
+}
+
+// parseIPNeigh 解析 `ip neigh show <ip>` 的输出，返回该地址对应的MAC地址
+func parseIPNeigh(output, ip string) string {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != ip {
+			continue
+		}
+		for i := 1; i+1 < len(fields); i++ {
+			if fields[i] == "lladdr" {
+				return normalizeMAC(fields[i+1])
+			}
+		}
+	}
+	return ""
+}
+
+// parseNetworkdLease 解析 /run/systemd/netif/leases/<ifindex> 中的字段, 如 SERVER_ADDRESS、DOMAINNAME
+func parseNetworkdLease(content, key string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(line), "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+// hasResolvectl 是否可以通过 systemd-resolved 设置DNS
+func hasResolvectl() bool {
+	_, err := exec.LookPath("resolvectl")
+	return err == nil
+}
+
+// addresses 获取接口上的IPv4地址
+func (b *NetworkdBackend) addresses(iface string) ([]ipAddrEntry, error) {
+	output, err := b.run("ip", "-o", "-4", "addr", "show", "dev", iface)
+	if err != nil {
+		return nil, err
+	}
+	return parseIPAddr(output), nil
+}
+
+// defaultGateway 获取接口上默认路由的网关
+func (b *NetworkdBackend) defaultGateway(iface string) (string, error) {
+	output, err := b.run("ip", "-o", "-4", "route", "show", "default")
+	if err != nil {
+		return "", err
+	}
+	for _, route := range parseIPRouteDefaults(output) {
+		if route.Dev == iface {
+			return route.Gateway, nil
+		}
+	}
+	return "", nil
+}
+
+// dnsServers 获取接口当前使用的DNS服务器
+func (b *NetworkdBackend) dnsServers(iface string) ([]string, error) {
+	if b.resolved {
+		output, err := b.run("resolvectl", "dns", iface)
+		if err != nil {
+			return nil, err
+		}
+		return parseResolvectlDNS(output), nil
+	}
+
+	content, err := os.ReadFile(b.resolvConf)
+	if err != nil {
+		return nil, err
+	}
+	return parseResolvConf(string(content)), nil
+}
+
+// splitDNSFamilies 将DNS服务器按IPv4和IPv6分开, 保持原有顺序
+func splitDNSFamilies(servers []string) (ipv4, ipv6 []string) {
+	for _, server := range servers {
+		if isIPv6(server) {
+			ipv6 = append(ipv6, server)
+		} else {
+			ipv4 = append(ipv4, server)
+		}
+	}
+	return ipv4, ipv6
+}
+
+// setDNS 按优先顺序设置静态DNS
+func (b *NetworkdBackend) setDNS(iface string, dns []string) error {
+	if b.resolved {
+		_, err := b.run("resolvectl", append([]string{"dns", iface}, dns...)...)
+		return err
+	}
+
+	// 没有 systemd-resolved 时接管 resolv.conf，首次接管前先备份
+	content, err := os.ReadFile(b.resolvConf)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if !strings.HasPrefix(string(content), resolvConfMarker) {
+		if err := os.WriteFile(b.resolvConf+resolvConfBackupSuffix, content, 0644); err != nil {
+			return fmt.Errorf("备份resolv.conf失败: %v", err)
+		}
+	}
+	var conf strings.Builder
+	conf.WriteString(resolvConfMarker + "\n")
+	for _, server := range dns {
+		conf.WriteString("nameserver " + server + "\n")
+	}
+	return os.WriteFile(b.resolvConf, []byte(conf.String()), 0644)
+}
+
+// revertDNS 恢复DHCP下发的DNS
+func (b *NetworkdBackend) revertDNS(iface string) error {
+	if b.resolved {
+		_, err := b.run("resolvectl", "revert", iface)
+		return err
+	}
+
+	// 还原被接管前的 resolv.conf
+	backupPath := b.resolvConf + resolvConfBackupSuffix
+	backup, err := os.ReadFile(backupPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(b.resolvConf, backup, 0644); err != nil {
+		return err
+	}
+	return os.Remove(backupPath)
+}
+
+// ListInterfaces 列出所有无线和有线网卡
+func (b *NetworkdBackend) ListInterfaces() ([]NetworkInterface, error) {
+	return listSysfsInterfaces()
+}
+
+// GetActiveInterface 获取活动网络接口名称（默认路由所在的接口）
+func (b *NetworkdBackend) GetActiveInterface(types string) (string, error) {
+	output, err := b.run("ip", "-o", "-4", "route", "show", "default")
+	if err != nil {
+		return "", err
+	}
+	for _, route := range parseIPRouteDefaults(output) {
+		if matchesInterfaceTypes(linkType(route.Dev), types) {
+			return route.Dev, nil
+		}
+	}
+	return "", fmt.Errorf("未找到活动网络接口")
+}
+
+// GetCurrentIPConfig 检查当前网络接口是否为DHCP模式
+func (b *NetworkdBackend) GetCurrentIPConfig(iface string) (isDHCP bool, err error) {
+	entries, err := b.addresses(iface)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if entry.Dynamic {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetCurrentStaticIPConfig 检查当前网络接口是否已经是目标静态IP配置
+func (b *NetworkdBackend) GetCurrentStaticIPConfig(iface, staticIP, subnetMask, gateway string, dns []string) (isStatic bool, err error) {
+	prefix, err := maskToPrefix(subnetMask)
+	if err != nil {
+		return false, err
+	}
+
+	entries, err := b.addresses(iface)
+	if err != nil {
+		return false, err
+	}
+	hasAddress := false
+	for _, entry := range entries {
+		if entry.Dynamic {
+			// 仍然存在DHCP地址，说明链路还在 networkd 手中
+			return false, nil
+		}
+		if entry.Address == staticIP && entry.Prefix == strconv.Itoa(prefix) {
+			hasAddress = true
+		}
+	}
+	if !hasAddress {
+		return false, nil
+	}
+
+	currentGateway, err := b.defaultGateway(iface)
+	if err != nil {
+		return false, err
+	}
+	if currentGateway != gateway {
+		return false, nil
+	}
+
+	servers, err := b.dnsServers(iface)
+	if err != nil {
+		return false, err
+	}
+	ipv4Servers, _ := splitDNSFamilies(servers)
+	return equalStrings(ipv4Servers, dns), nil
+}
+
+// staticNetworkPath 网卡静态IP运行时配置的路径
+// 文件名以 00- 开头, 排在发行版和用户的 .network 配置之前, 优先匹配该网卡
+func (b *NetworkdBackend) staticNetworkPath(iface string) string {
+	return filepath.Join(b.networkDir, "00-routerswitcher-"+iface+".network")
+}
+
+// staticNetworkConfig 生成关闭DHCP、使用静态地址、网关和DNS的 .network 配置
+func staticNetworkConfig(iface, address, gateway string, dns []string) string {
+	var conf strings.Builder
+	conf.WriteString(staticNetworkHeader + "\n")
+	conf.WriteString("[Match]\nName=" + iface + "\n\n")
+	conf.WriteString("[Network]\nDHCP=no\n")
+	conf.WriteString("Address=" + address + "\n")
+	conf.WriteString("Gateway=" + gateway + "\n")
+	for _, server := range dns {
+		conf.WriteString("DNS=" + server + "\n")
+	}
+	return conf.String()
+}
+
+// reloadNetworkd 重新加载 .network 配置并重新配置网卡
+func (b *NetworkdBackend) reloadNetworkd(iface string) error {
+	if _, err := b.run("networkctl", "reload"); err != nil {
+		return err
+	}
+	_, err := b.run("networkctl", "reconfigure", iface)
+	return err
+}
+
+// SetDHCP 设置网络接口为DHCP模式，删除静态IP运行时配置后交还给 networkd
+func (b *NetworkdBackend) SetDHCP(iface string) error {
+	if err := os.Remove(b.staticNetworkPath(iface)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除静态IP配置失败: %v", err)
+	}
+
+	// 重新应用 .network 配置，由 networkd 删除静态地址并发起DHCP
+	if err := b.reloadNetworkd(iface); err != nil {
+		return fmt.Errorf("设置DHCP IP失败: %v", err)
+	}
+
+	if err := b.revertDNS(iface); err != nil {
+		return fmt.Errorf("设置DHCP DNS失败: %v", err)
+	}
+
+	return nil
+}
+
+// SetStaticIP 设置网络接口为静态IP模式
+func (b *NetworkdBackend) SetStaticIP(iface, ip, subnetMask, gateway string, dns []string) error {
+	prefix, err := maskToPrefix(subnetMask)
+	if err != nil {
+		return fmt.Errorf("设置静态IP失败: %v", err)
+	}
+
+	address := fmt.Sprintf("%s/%d", ip, prefix)
+
+	// 写入关闭DHCP的运行时配置, 由 networkd 停止DHCP客户端并设置地址和默认路由
+	path := b.staticNetworkPath(iface)
+	if err := os.MkdirAll(b.networkDir, 0755); err != nil {
+		return fmt.Errorf("创建networkd配置目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(staticNetworkConfig(iface, address, gateway, dns)), 0644); err != nil {
+		return fmt.Errorf("写入静态IP配置失败: %v", err)
+	}
+	if err := b.reloadNetworkd(iface); err != nil {
+		// networkd 没有运行, 网卡不由它管理, 直接设置地址和默认路由
+		log.Printf("networkd 重新配置网卡失败, 直接设置静态IP: %v", err)
+		os.Remove(path)
+		if err := b.setAddress(iface, address, gateway); err != nil {
+			return err
+		}
+	}
+
+	if err := b.setDNS(iface, dns); err != nil {
+		return fmt.Errorf("设置静态DNS失败: %v", err)
+	}
+
+	return nil
+}
+
+// setAddress 通过 ip 命令设置静态地址和默认路由, 用于不由 networkd 管理的网卡
+func (b *NetworkdBackend) setAddress(iface, address, gateway string) error {
+	// 清除DHCP地址（同时会删除经由它的路由）后添加静态地址
+	if _, err := b.run("ip", "-4", "addr", "flush", "dev", iface); err != nil {
+		return fmt.Errorf("清除DHCP地址失败: %v", err)
+	}
+	if _, err := b.run("ip", "addr", "add", address, "dev", iface); err != nil {
+		return fmt.Errorf("设置静态IP失败: %v", err)
+	}
+	if _, err := b.run("ip", "route", "replace", "default", "via", gateway, "dev", iface); err != nil {
+		return fmt.Errorf("设置默认网关失败: %v", err)
+	}
+	return nil
+}
+
+// GetIPv4Config 获取网络接口当前的IPv4地址、子网掩码、网关和DNS
+// 无法区分DHCP地址上手动设置的DNS, 只有静态地址时视为手动DNS
+func (b *NetworkdBackend) GetIPv4Config(iface string) (*IPv4Config, error) {
+	entries, err := b.addresses(iface)
+	if err != nil {
+		return nil, fmt.Errorf("获取IPv4配置失败: %v", err)
+	}
+	config := &IPv4Config{}
+	if len(entries) > 0 {
+		config.DHCP = entries[0].Dynamic
+		config.IP, config.SubnetMask = splitAddressPrefix(entries[0].Address + "/" + entries[0].Prefix)
+	}
+	if config.Gateway, err = b.defaultGateway(iface); err != nil {
+		return nil, fmt.Errorf("获取默认网关失败: %v", err)
+	}
+	servers, err := b.dnsServers(iface)
+	if err != nil {
+		return nil, fmt.Errorf("获取DNS失败: %v", err)
+	}
+	config.DNS, _ = splitDNSFamilies(servers)
+	config.StaticDNS = !config.DHCP
+	return config, nil
+}
+
+// GetIPv6Config 获取网络接口当前的IPv6地址、网关和DNS
+func (b *NetworkdBackend) GetIPv6Config(iface string) (*IPv6Config, error) {
+	output, err := b.run("ip", "-o", "-6", "addr", "show", "dev", iface, "scope", "global")
+	if err != nil {
+		return nil, fmt.Errorf("获取IPv6配置失败: %v", err)
+	}
+	config := &IPv6Config{}
+	for _, entry := range parseIPAddr(output) {
+		prefix, _ := strconv.Atoi(entry.Prefix)
+		// 路由通告和DHCPv6分配的地址带有 dynamic 标记
+		config.Addresses = append(config.Addresses, IPv6Address{IP: entry.Address, PrefixLen: prefix, Manual: !entry.Dynamic})
+	}
+
+	output, err = b.run("ip", "-o", "-6", "route", "show", "default")
+	if err != nil {
+		return nil, fmt.Errorf("获取IPv6网关失败: %v", err)
+	}
+	for _, route := range parseIPRouteDefaults(output) {
+		if route.Dev == iface && route.Gateway != "" {
+			config.Gateways = append(config.Gateways, route.Gateway)
+		}
+	}
+
+	if servers, err := b.dnsServers(iface); err == nil {
+		_, config.DNSServers = splitDNSFamilies(servers)
+	}
+	return config, nil
+}
+
+// setAcceptRA 设置内核是否接受路由通告
+// 由 networkd 自己处理路由通告的链路上此设置不生效, 重新收到路由通告后下次检查时会再次清除
+func (b *NetworkdBackend) setAcceptRA(iface string, accept bool) error {
+	value := "0"
+	if accept {
+		value = "1"
+	}
+	// sysctl 键中的 '.' 是分隔符, 接口名称中的 '.' (如 VLAN eth0.10) 需要写成 '/'
+	key := fmt.Sprintf("net.ipv6.conf.%s.accept_ra", strings.ReplaceAll(iface, ".", "/"))
+	_, err := b.run("sysctl", "-w", key+"="+value)
+	return err
+}
+
+// setIPv6DNS 设置IPv6 DNS, 保留当前的IPv4 DNS
+func (b *NetworkdBackend) setIPv6DNS(iface string, dns []string) error {
+	servers, err := b.dnsServers(iface)
+	if err != nil {
+		return err
+	}
+	ipv4Servers, _ := splitDNSFamilies(servers)
+	return b.setDNS(iface, append(ipv4Servers, dns...))
+}
+
+// SetStaticIPv6 设置静态IPv6地址、网关和DNS
+func (b *NetworkdBackend) SetStaticIPv6(iface, address, gateway string, dns []string) error {
+	if err := b.setAcceptRA(iface, false); err != nil {
+		return fmt.Errorf("停止接受路由通告失败: %v", err)
+	}
+	if _, err := b.run("ip", "-6", "addr", "flush", "dev", iface, "scope", "global"); err != nil {
+		return fmt.Errorf("清除IPv6地址失败: %v", err)
+	}
+	if _, err := b.run("ip", "-6", "addr", "add", address, "dev", iface); err != nil {
+		return fmt.Errorf("设置静态IPv6失败: %v", err)
+	}
+	if _, err := b.run("ip", "-6", "route", "replace", "default", "via", gateway, "dev", iface); err != nil {
+		return fmt.Errorf("设置IPv6网关失败: %v", err)
+	}
+	if err := b.setIPv6DNS(iface, dns); err != nil {
+		return fmt.Errorf("设置IPv6 DNS失败: %v", err)
+	}
+	return nil
+}
+
+// DisableIPv6Auto 停止接受路由通告, 清除自动获取的IPv6地址、默认网关和DNS
+func (b *NetworkdBackend) DisableIPv6Auto(iface string) error {
+	if err := b.setAcceptRA(iface, false); err != nil {
+		return fmt.Errorf("停止接受路由通告失败: %v", err)
+	}
+	if _, err := b.run("ip", "-6", "addr", "flush", "dev", iface, "scope", "global", "dynamic"); err != nil {
+		return fmt.Errorf("清除IPv6地址失败: %v", err)
+	}
+	// 没有IPv6默认路由时删除会失败, 忽略错误
+	b.run("ip", "-6", "route", "del", "default", "dev", iface)
+	if err := b.setIPv6DNS(iface, nil); err != nil {
+		return fmt.Errorf("清除IPv6 DNS失败: %v", err)
+	}
+	return nil
+}
+
+// ResetIPv6 删除手动设置的IPv6地址, 交还给 networkd 重新配置
+func (b *NetworkdBackend) ResetIPv6(iface string) error {
+	if _, err := b.run("ip", "-6", "addr", "flush", "dev", iface, "scope", "global", "permanent"); err != nil {
+		return fmt.Errorf("清除静态IPv6失败: %v", err)
+	}
+	b.run("ip", "-6", "route", "del", "default", "dev", iface)
+	if err := b.setAcceptRA(iface, true); err != nil {
+		return fmt.Errorf("恢复接受路由通告失败: %v", err)
+	}
+	if _, err := b.run("networkctl", "reconfigure", iface); err != nil {
+		return fmt.Errorf("恢复IPv6自动配置失败: %v", err)
+	}
+	return nil
+}
+
+// AddRoute 添加一条经过指定网关的临时路由
+func (b *NetworkdBackend) AddRoute(iface, destination, gateway string) error {
+	if _, err := b.run("ip", "route", "add", destination, "via", gateway, "dev", iface); err != nil {
+		return fmt.Errorf("添加路由失败: %v", err)
+	}
+	return nil
+}
+
+// DeleteRoute 删除 AddRoute 添加的路由
+func (b *NetworkdBackend) DeleteRoute(iface, destination, gateway string) error {
+	if _, err := b.run("ip", "route", "del", destination, "via", gateway, "dev", iface); err != nil {
+		return fmt.Errorf("删除路由失败: %v", err)
+	}
+	return nil
+}
+
+// ListRoutes 列出网卡上经过网关的IPv4路由
+func (b *NetworkdBackend) ListRoutes(iface string) ([]Route, error) {
+	return listIPRoutes(b.runner, iface)
+}
+
+// SetDNS 设置网络接口的静态DNS, 不改动地址
+func (b *NetworkdBackend) SetDNS(iface string, dns []string) error {
+	if err := b.setDNS(iface, dns); err != nil {
+		return fmt.Errorf("设置静态DNS失败: %v", err)
+	}
+	return nil
+}
+
+// SetPreferredGateway 保留DHCP地址, 添加经过 gateway、跃点数更小的默认路由并设置静态DNS
+// 路由不写入 .network 配置, networkd 重新配置网卡后失效, 由自适应检查重新添加
+func (b *NetworkdBackend) SetPreferredGateway(iface, gateway string, dns []string) error {
+	isDHCP, err := b.GetCurrentIPConfig(iface)
+	if err != nil {
+		return fmt.Errorf("获取IP配置失败: %v", err)
+	}
+	if !isDHCP {
+		if err := b.SetDHCP(iface); err != nil {
+			return err
+		}
+	}
+
+	if err := setIPPreferredGateway(b.runner, iface, gateway); err != nil {
+		return err
+	}
+	if err := b.setDNS(iface, dns); err != nil {
+		return fmt.Errorf("设置静态DNS失败: %v", err)
+	}
+	return nil
+}
+
+// ClearPreferredGateway 删除经过 gateway 的默认路由, 恢复其他默认路由原来的跃点数和DHCP下发的DNS
+func (b *NetworkdBackend) ClearPreferredGateway(iface, gateway string, defaults []Route) error {
+	if err := clearIPPreferredGateway(b.runner, iface, gateway, defaults); err != nil {
+		return err
+	}
+	if err := b.revertDNS(iface); err != nil {
+		return fmt.Errorf("恢复DHCP DNS失败: %v", err)
+	}
+	return nil
+}
+
+// GetRouteOverride 获取覆盖默认路由的网关
+func (b *NetworkdBackend) GetRouteOverride(iface string) (string, error) {
+	return getIPRouteOverride(b.runner, iface)
+}
+
+// SetRouteOverride 保留DHCP地址, 添加经过 gateway 的 overrideRoutes 并设置静态DNS
+// 路由不写入 .network 配置, networkd 重新配置网卡后失效, 由自适应检查重新添加
+func (b *NetworkdBackend) SetRouteOverride(iface, gateway string, dns []string) error {
+	// 当前是静态IP时先交还给 networkd 获取DHCP地址
+	isDHCP, err := b.GetCurrentIPConfig(iface)
+	if err != nil {
+		return fmt.Errorf("获取IP配置失败: %v", err)
+	}
+	if !isDHCP {
+		if err := b.SetDHCP(iface); err != nil {
+			return err
+		}
+	}
+
+	for _, destination := range overrideRoutes {
+		if _, err := b.run("ip", "route", "replace", destination, "via", gateway, "dev", iface); err != nil {
+			return fmt.Errorf("覆盖默认路由失败: %v", err)
+		}
+	}
+	if err := b.setDNS(iface, dns); err != nil {
+		return fmt.Errorf("设置静态DNS失败: %v", err)
+	}
+	return nil
+}
+
+// ClearRouteOverride 删除覆盖默认路由的路由, 恢复DHCP下发的DNS
+func (b *NetworkdBackend) ClearRouteOverride(iface string) error {
+	gateway, err := b.GetRouteOverride(iface)
+	if err != nil {
+		return err
+	}
+	if gateway != "" {
+		for _, destination := range overrideRoutes {
+			if _, err := b.run("ip", "route", "del", destination, "via", gateway, "dev", iface); err != nil {
+				return fmt.Errorf("删除路由失败: %v", err)
+			}
+		}
+	}
+	if err := b.revertDNS(iface); err != nil {
+		return fmt.Errorf("恢复DHCP DNS失败: %v", err)
+	}
+	return nil
+}
+
+// GetCurrentWiFiName 获取无线网卡 iface 当前连接的WiFi名称
+func (b *NetworkdBackend) GetCurrentWiFiName(iface string) (string, error) {
+	output, err := b.run("iw", "dev", iface, "link")
+	if err != nil {
+		return "", err
+	}
+	if ssid, ok := parseIwSSID(output); ok {
+		return ssid, nil
+	}
+	return "", fmt.Errorf("未找到WiFi信息")
+}
+
+// GetNetworkFacts 获取判断当前所在网络所需的信息
+func (b *NetworkdBackend) GetNetworkFacts(iface string) (*NetworkFacts, error) {
+	facts := &NetworkFacts{Interface: iface, InterfaceType: linkType(iface)}
+
+	entries, err := b.addresses(iface)
+	if err != nil {
+		return facts, fmt.Errorf("获取网络配置失败: %v", err)
+	}
+	if len(entries) > 0 {
+		facts.IPAddress = entries[0].Address
+		facts.DHCP = entries[0].Dynamic
+	}
+
+	facts.Gateway, _ = b.defaultGateway(iface)
+	if facts.Gateway != "" {
+		// 网关的MAC地址从邻居表中读取, 获取不到时留空
+		if output, err := b.run("ip", "neigh", "show", facts.Gateway); err == nil {
+			facts.GatewayMAC = parseIPNeigh(output, facts.Gateway)
+		}
+	}
+
+	// DHCP服务器和DNS后缀记录在 networkd 的租约文件中
+	if index, err := os.ReadFile("/sys/class/net/" + iface + "/ifindex"); err == nil {
+		if lease, err := os.ReadFile("/run/systemd/netif/leases/" + strings.TrimSpace(string(index))); err == nil {
+			facts.DHCPServer = parseNetworkdLease(string(lease), "SERVER_ADDRESS")
+			facts.DNSSuffix = parseNetworkdLease(string(lease), "DOMAINNAME")
+		}
+	}
+
+	// 有线网络没有WiFi信息
+	if facts.InterfaceType == InterfaceWiFi {
+		if output, err := b.run("iw", "dev", iface, "link"); err == nil {
+			facts.SSID, _ = parseIwSSID(output)
+			bssid, _ := parseIwBSSID(output)
+			facts.BSSID = normalizeMAC(bssid)
+		}
+	}
+
+	return facts, nil
+}
+
+// GetCurrentNetworkStatus 获取网络接口的详细状态
+func (b *NetworkdBackend) GetCurrentNetworkStatus(iface string) (*NetworkStatus, error) {
+	status := &NetworkStatus{Interface: iface, InterfaceType: linkType(iface)}
+
+	// 获取WiFi名称, 有线网卡不显示WiFi
+	status.WiFiName = "未连接"
+	if status.InterfaceType == InterfaceWiFi {
+		if ssid, err := b.GetCurrentWiFiName(iface); err == nil {
+			status.WiFiName = ssid
+			status.WiFiConnected = true
+		}
+	}
+
+	entries, err := b.addresses(iface)
+	if err != nil {
+		return status, fmt.Errorf("获取网络配置失败: %v", err)
+	}
+	isDHCP := false
+	if len(entries) > 0 {
+		status.IPAddress = entries[0].Address
+		isDHCP = entries[0].Dynamic
+	}
+
+	status.Gateway, _ = b.defaultGateway(iface)
+	servers, _ := b.dnsServers(iface)
+	servers, _ = splitDNSFamilies(servers)
+
+	// 设置分配方式
+	if isDHCP {
+		status.IPAssignment = "自动(DHCP)"
+		status.DNSAssignment = "自动(DHCP)"
+	} else {
+		status.IPAssignment = "手动"
+		status.DNSAssignment = "手动"
+	}
+
+	// 同时测试网关和DNS连通性
+	status.probe(servers, b.pinger.PingAll)
+
+	return status, nil
+}
+
+// Ping 测试网络连通性
+func (b *NetworkdBackend) Ping(host string) bool {
+	return b.pinger.Ping(host).Reachable()
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestNetworkdBackend 创建配置目录和 resolv.conf 都在临时目录中的 networkd 后端
+func newTestNetworkdBackend(t *testing.T, runner *stubRunner) *NetworkdBackend {
+	t.Helper()
+	dir := t.TempDir()
+	resolvConf := filepath.Join(dir, "resolv.conf")
+	if err := os.WriteFile(resolvConf, []byte("nameserver 192.168.1.1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return &NetworkdBackend{
+		runner:     runner,
+		networkDir: filepath.Join(dir, "network"),
+		resolvConf: resolvConf,
+	}
+}
+
+func TestNetworkdSetStaticIPDisablesDHCP(t *testing.T) {
+	runner := &stubRunner{}
+	b := newTestNetworkdBackend(t, runner)
+
+	if err := b.SetStaticIP("eth0", "192.168.1.50", "255.255.255.0", "192.168.1.2", []string{"192.168.1.2", "223.5.5.5"}); err != nil {
+		t.Fatalf("SetStaticIP: %v", err)
+	}
+
+	// 静态IP交给 networkd 设置, networkd 停止DHCP客户端后续租不会再添加动态地址
+	network, err := os.ReadFile(b.staticNetworkPath("eth0"))
+	if err != nil {
+		t.Fatalf("静态IP配置未写入: %v", err)
+	}
+	for _, line := range []string{staticNetworkHeader, "Name=eth0", "DHCP=no", "Address=192.168.1.50/24", "Gateway=192.168.1.2", "DNS=192.168.1.2", "DNS=223.5.5.5"} {
+		if !strings.Contains(string(network), line+"\n") {
+			t.Errorf("静态IP配置缺少 %q:\n%s", line, network)
+		}
+	}
+	want := []string{"networkctl reload", "networkctl reconfigure eth0"}
+	if !reflect.DeepEqual(runner.commands, want) {
+		t.Errorf("commands = %q, want %q", runner.commands, want)
+	}
+
+	resolv, _ := os.ReadFile(b.resolvConf)
+	if !strings.Contains(string(resolv), "nameserver 192.168.1.2\nnameserver 223.5.5.5\n") {
+		t.Errorf("resolv.conf = %q", resolv)
+	}
+
+	// 切回DHCP时删除运行时配置并恢复 resolv.conf
+	runner.commands = nil
+	if err := b.SetDHCP("eth0"); err != nil {
+		t.Fatalf("SetDHCP: %v", err)
+	}
+	if _, err := os.Stat(b.staticNetworkPath("eth0")); !os.IsNotExist(err) {
+		t.Errorf("静态IP配置未删除: %v", err)
+	}
+	want = []string{"networkctl reload", "networkctl reconfigure eth0"}
+	if !reflect.DeepEqual(runner.commands, want) {
+		t.Errorf("commands = %q, want %q", runner.commands, want)
+	}
+	if resolv, _ := os.ReadFile(b.resolvConf); string(resolv) != "nameserver 192.168.1.1\n" {
+		t.Errorf("resolv.conf not restored: %q", resolv)
+	}
+}
+
+func TestNetworkdSetStaticIPWithoutNetworkd(t *testing.T) {
+	runner := &stubRunner{failures: map[string]bool{"networkctl reload": true}}
+	b := newTestNetworkdBackend(t, runner)
+
+	if err := b.SetStaticIP("eth0", "10.0.0.50", "255.255.0.0", "10.0.0.2", []string{"10.0.0.2"}); err != nil {
+		t.Fatalf("SetStaticIP: %v", err)
+	}
+	if _, err := os.Stat(b.staticNetworkPath("eth0")); !os.IsNotExist(err) {
+		t.Errorf("networkd 没有运行时不应保留静态IP配置: %v", err)
+	}
+	want := []string{
+		"networkctl reload",
+		"ip -4 addr flush dev eth0",
+		"ip addr add 10.0.0.50/16 dev eth0",
+		"ip route replace default via 10.0.0.2 dev eth0",
+	}
+	if !reflect.DeepEqual(runner.commands, want) {
+		t.Errorf("commands = %q, want %q", runner.commands, want)
+	}
+}
+
+func TestClearIPPreferredGatewayRestoresMetric(t *testing.T) {
+	runner := &stubRunner{outputs: map[string]string{
+		"ip -o -4 route show dev eth0": "default via 192.168.1.1 dev eth0 proto dhcp metric 1 \n",
+	}}
+	defaults := []Route{{Destination: "0.0.0.0/0", Gateway: "192.168.1.1"}}
+
+	if err := clearIPPreferredGateway(runner, "eth0", "192.168.1.2", defaults); err != nil {
+		t.Fatalf("clearIPPreferredGateway: %v", err)
+	}
+	want := []string{
+		"ip route del default via 192.168.1.2 dev eth0",
+		"ip -o -4 route show dev eth0",
+		"ip route append default via 192.168.1.1 dev eth0 metric 0",
+		"ip route del default via 192.168.1.1 dev eth0 metric 1",
+	}
+	if !reflect.DeepEqual(runner.commands, want) {
+		t.Errorf("commands = %q, want %q", runner.commands, want)
+	}
+
+	// 没有记录原来的跃点数时只删除路由
+	runner.commands = nil
+	if err := clearIPPreferredGateway(runner, "eth0", "192.168.1.2", nil); err != nil {
+		t.Fatalf("clearIPPreferredGateway: %v", err)
+	}
+	if len(runner.commands) != 2 {
+		t.Errorf("commands = %q, want only del and show", runner.commands)
+	}
+}
+
+func TestNetworkdGetCurrentWiFiNamePerInterface(t *testing.T) {
+	runner := &stubRunner{outputs: map[string]string{
+		"iw dev wlan0 link": "Not connected.\n",
+		"iw dev wlan1 link": "Connected to aa:bb:cc:dd:ee:ff (on wlan1)\n\tSSID: HomeWiFi\n\tfreq: 5180\n",
+	}}
+	b := newTestNetworkdBackend(t, runner)
+
+	if ssid, err := b.GetCurrentWiFiName("wlan1"); err != nil || ssid != "HomeWiFi" {
+		t.Errorf("GetCurrentWiFiName(wlan1) = %q, %v, want HomeWiFi", ssid, err)
+	}
+	if ssid, err := b.GetCurrentWiFiName("wlan0"); err == nil {
+		t.Errorf("未连接的网卡应返回错误, got %q", ssid)
+	}
+}
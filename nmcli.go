@@ -0,0 +1,672 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// NmcliBackend 基于 Linux NetworkManager (nmcli) 的网络后端
+// 切换时修改活动连接配置的 ipv4.method (auto / manual) 并重新激活连接
+type NmcliBackend struct {
+	runner CommandRunner
+	pinger *Pinger
+}
+
+// NewNmcliBackend 创建 nmcli 网络后端
+func NewNmcliBackend(runner CommandRunner) *NmcliBackend {
+	pinger := NewPinger(DefaultPingCount, DefaultPingTimeout)
+	pinger.Runner = runner
+	return &NmcliBackend{runner: runner, pinger: pinger}
+}
+
+// nmcliConnection nmcli 活动连接信息
+type nmcliConnection struct {
+	Name   string // 连接名称
+	UUID   string // 连接UUID
+	Type   string // 连接类型, 如 802-11-wireless / 802-3-ethernet
+	Device string // 绑定的网络设备
+}
+
+// runNmcli 执行 nmcli 命令并返回标准输出
+func (b *NmcliBackend) runNmcli(args ...string) (string, error) {
+	result, err := b.runner.Run(context.Background(), Command{
+		Name: "nmcli",
+		Args: args,
+		// 固定为英文输出，避免 yes/no 等字段被本地化
+		Env: []string{"LC_ALL=C"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("执行nmcli命令失败: %v. %s", err, strings.TrimSpace(result.Output()))
+	}
+	return string(result.Stdout), nil
+}
+
+// splitNmcliFields 拆分 nmcli -t 输出的一行
+// nmcli 使用 ':' 分隔字段，字段值中的 ':' 和 '\' 会被转义为 '\:' 和 '\\'
+func splitNmcliFields(line string) []string {
+	var fields []string
+	var field strings.Builder
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			field.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteRune(r)
+		}
+	}
+	return append(fields, field.String())
+}
+
+// parseNmcliActiveConnections 解析 `nmcli -t -f NAME,UUID,TYPE,DEVICE connection show --active` 的输出
+func parseNmcliActiveConnections(output string) []nmcliConnection {
+	var conns []nmcliConnection
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		fields := splitNmcliFields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		conns = append(conns, nmcliConnection{
+			Name:   fields[0],
+			UUID:   fields[1],
+			Type:   fields[2],
+			Device: fields[3],
+		})
+	}
+	return conns
+}
+
+// parseNmcliProperties 解析 `nmcli -t -f ... connection/device show` 的 "键:值" 输出
+// 像 IP4.DNS[1]、IP4.DNS[2] 这样带序号的键会合并为同一个键，值按出现顺序保存
+func parseNmcliProperties(output string) map[string][]string {
+	props := make(map[string][]string)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		fields := splitNmcliFields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		key := fields[0]
+		if idx := strings.Index(key, "["); idx != -1 {
+			key = key[:idx]
+		}
+		value := strings.Join(fields[1:], ":")
+		if value == "" || value == "--" {
+			continue
+		}
+		// 连接属性中的多个值使用逗号分隔
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				props[key] = append(props[key], v)
+			}
+		}
+	}
+	return props
+}
+
+// parseNmcliActiveWiFi 解析 `nmcli -t -f ACTIVE,SSID,BSSID device wifi list` 的输出，返回当前连接的WiFi的SSID和BSSID
+func parseNmcliActiveWiFi(output string) (ssid, bssid string, ok bool) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		fields := splitNmcliFields(line)
+		if len(fields) >= 3 && fields[0] == "yes" {
+			return fields[1], fields[2], true
+		}
+	}
+	return "", "", false
+}
+
+// nmcliInterfaceType 将连接类型转换为网卡类型, 不需要管理的连接类型返回空
+func nmcliInterfaceType(connType string) string {
+	switch connType {
+	case "802-11-wireless", "wifi":
+		return InterfaceWiFi
+	case "802-3-ethernet", "ethernet":
+		return InterfaceEthernet
+	}
+	return ""
+}
+
+// firstProp 返回属性的第一个值
+func firstProp(props map[string][]string, key string) string {
+	if values := props[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// splitAddressPrefix 将带前缀长度的地址(如 192.168.31.100/24)拆分为地址和点分十进制子网掩码
+func splitAddressPrefix(address string) (ip, mask string) {
+	parsed, network, err := net.ParseCIDR(address)
+	if err != nil {
+		return address, ""
+	}
+	return parsed.String(), net.IP(network.Mask).String()
+}
+
+// maskToPrefix 将点分十进制子网掩码转换为前缀长度
+func maskToPrefix(subnetMask string) (int, error) {
+	ip := net.ParseIP(subnetMask).To4()
+	if ip == nil {
+		return 0, fmt.Errorf("无效的子网掩码: %s", subnetMask)
+	}
+	ones, bits := net.IPMask(ip).Size()
+	if bits == 0 {
+		return 0, fmt.Errorf("无效的子网掩码: %s", subnetMask)
+	}
+	return ones, nil
+}
+
+// activeConnection 获取绑定在指定设备上的活动连接
+func (b *NmcliBackend) activeConnection(iface string) (*nmcliConnection, error) {
+	output, err := b.runNmcli("-t", "-f", "NAME,UUID,TYPE,DEVICE", "connection", "show", "--active")
+	if err != nil {
+		return nil, err
+	}
+	for _, conn := range parseNmcliActiveConnections(output) {
+		if conn.Device == iface {
+			return &conn, nil
+		}
+	}
+	return nil, fmt.Errorf("网络接口 %s 上没有活动连接", iface)
+}
+
+// connectionProperties 读取连接配置中的 IPv4 属性和 IPv6 地址设置
+func (b *NmcliBackend) connectionProperties(iface string) (map[string][]string, error) {
+	conn, err := b.activeConnection(iface)
+	if err != nil {
+		return nil, err
+	}
+	output, err := b.runNmcli("-t", "-f", "ipv4.method,ipv4.addresses,ipv4.gateway,ipv4.dns,ipv4.ignore-auto-dns,ipv6.method,ipv6.addresses", "connection", "show", conn.UUID)
+	if err != nil {
+		return nil, err
+	}
+	return parseNmcliProperties(output), nil
+}
+
+// ListInterfaces 列出所有无线和有线网卡
+func (b *NmcliBackend) ListInterfaces() ([]NetworkInterface, error) {
+	return listSysfsInterfaces()
+}
+
+// GetActiveInterface 获取活动网络接口名称
+func (b *NmcliBackend) GetActiveInterface(types string) (string, error) {
+	output, err := b.runNmcli("-t", "-f", "NAME,UUID,TYPE,DEVICE", "connection", "show", "--active")
+	if err != nil {
+		return "", err
+	}
+	for _, conn := range parseNmcliActiveConnections(output) {
+		if conn.Device != "" && matchesInterfaceTypes(nmcliInterfaceType(conn.Type), types) {
+			return conn.Device, nil
+		}
+	}
+	return "", fmt.Errorf("未找到活动网络接口")
+}
+
+// GetCurrentIPConfig 检查当前网络接口是否为DHCP模式
+func (b *NmcliBackend) GetCurrentIPConfig(iface string) (isDHCP bool, err error) {
+	props, err := b.connectionProperties(iface)
+	if err != nil {
+		return false, err
+	}
+	return firstProp(props, "ipv4.method") == "auto", nil
+}
+
+// GetCurrentStaticIPConfig 检查当前网络接口是否已经是目标静态IP配置
+func (b *NmcliBackend) GetCurrentStaticIPConfig(iface, staticIP, subnetMask, gateway string, dns []string) (isStatic bool, err error) {
+	props, err := b.connectionProperties(iface)
+	if err != nil {
+		return false, err
+	}
+	if firstProp(props, "ipv4.method") != "manual" {
+		return false, nil
+	}
+
+	prefix, err := maskToPrefix(subnetMask)
+	if err != nil {
+		return false, err
+	}
+
+	// ipv4.addresses 形如 192.168.31.100/24
+	address := firstProp(props, "ipv4.addresses")
+	return address == fmt.Sprintf("%s/%d", staticIP, prefix) &&
+		firstProp(props, "ipv4.gateway") == gateway &&
+		equalStrings(props["ipv4.dns"], dns), nil
+}
+
+// SetDHCP 设置网络接口为DHCP模式
+func (b *NmcliBackend) SetDHCP(iface string) error {
+	conn, err := b.activeConnection(iface)
+	if err != nil {
+		return fmt.Errorf("设置DHCP IP失败: %v", err)
+	}
+
+	_, err = b.runNmcli("connection", "modify", conn.UUID,
+		"ipv4.method", "auto",
+		"ipv4.addresses", "",
+		"ipv4.gateway", "",
+		"ipv4.dns", "",
+	)
+	if err != nil {
+		return fmt.Errorf("设置DHCP IP失败: %v", err)
+	}
+
+	// 重新激活连接使配置生效
+	_, err = b.runNmcli("connection", "up", conn.UUID)
+	if err != nil {
+		return fmt.Errorf("激活连接失败: %v", err)
+	}
+
+	return nil
+}
+
+// SetStaticIP 设置网络接口为静态IP模式
+func (b *NmcliBackend) SetStaticIP(iface, ip, subnetMask, gateway string, dns []string) error {
+	conn, err := b.activeConnection(iface)
+	if err != nil {
+		return fmt.Errorf("设置静态IP失败: %v", err)
+	}
+
+	prefix, err := maskToPrefix(subnetMask)
+	if err != nil {
+		return fmt.Errorf("设置静态IP失败: %v", err)
+	}
+
+	_, err = b.runNmcli("connection", "modify", conn.UUID,
+		"ipv4.method", "manual",
+		"ipv4.addresses", fmt.Sprintf("%s/%d", ip, prefix),
+		"ipv4.gateway", gateway,
+		"ipv4.dns", strings.Join(dns, ","),
+	)
+	if err != nil {
+		return fmt.Errorf("设置静态IP失败: %v", err)
+	}
+
+	// 重新激活连接使配置生效
+	_, err = b.runNmcli("connection", "up", conn.UUID)
+	if err != nil {
+		return fmt.Errorf("激活连接失败: %v", err)
+	}
+
+	return nil
+}
+
+// GetIPv4Config 获取网络接口当前的IPv4地址、子网掩码、网关和DNS
+// 地址、网关和DNS读取设备当前的值, 是否为DHCP和手动DNS读取连接配置
+func (b *NmcliBackend) GetIPv4Config(iface string) (*IPv4Config, error) {
+	props, err := b.connectionProperties(iface)
+	if err != nil {
+		return nil, fmt.Errorf("获取IPv4配置失败: %v", err)
+	}
+	output, err := b.runNmcli("-t", "-f", "IP4.ADDRESS,IP4.GATEWAY,IP4.DNS", "device", "show", iface)
+	if err != nil {
+		return nil, fmt.Errorf("获取IPv4配置失败: %v", err)
+	}
+	device := parseNmcliProperties(output)
+
+	config := &IPv4Config{
+		DHCP:      firstProp(props, "ipv4.method") == "auto",
+		Gateway:   firstProp(device, "IP4.GATEWAY"),
+		DNS:       device["IP4.DNS"],
+		StaticDNS: firstProp(props, "ipv4.method") == "manual" || firstProp(props, "ipv4.ignore-auto-dns") == "yes",
+	}
+	config.IP, config.SubnetMask = splitAddressPrefix(firstProp(device, "IP4.ADDRESS"))
+	return config, nil
+}
+
+// GetIPv6Config 获取网络接口当前的IPv6地址、网关和DNS
+func (b *NmcliBackend) GetIPv6Config(iface string) (*IPv6Config, error) {
+	output, err := b.runNmcli("-t", "-f", "IP6.ADDRESS,IP6.GATEWAY,IP6.DNS", "device", "show", iface)
+	if err != nil {
+		return nil, fmt.Errorf("获取IPv6配置失败: %v", err)
+	}
+	props := parseNmcliProperties(output)
+
+	// 手动模式下所有地址都是手动设置的, 自动模式下 ipv6.addresses 为额外手动添加的地址
+	manualMethod := false
+	manual := make(map[string]bool)
+	if connProps, err := b.connectionProperties(iface); err == nil {
+		manualMethod = firstProp(connProps, "ipv6.method") == "manual"
+		for _, address := range connProps["ipv6.addresses"] {
+			if ip, _, err := net.ParseCIDR(address); err == nil {
+				manual[ip.String()] = true
+			}
+		}
+	}
+
+	config := &IPv6Config{DNSServers: props["IP6.DNS"]}
+	for _, address := range props["IP6.ADDRESS"] {
+		ip, subnet, err := net.ParseCIDR(address)
+		if err != nil || ip.IsLinkLocalUnicast() {
+			continue
+		}
+		prefix, _ := subnet.Mask.Size()
+		config.Addresses = append(config.Addresses, IPv6Address{IP: ip.String(), PrefixLen: prefix, Manual: manualMethod || manual[ip.String()]})
+	}
+	config.Gateways = props["IP6.GATEWAY"]
+	return config, nil
+}
+
+// modifyIPv6 修改活动连接的IPv6设置并重新激活连接
+func (b *NmcliBackend) modifyIPv6(iface string, settings ...string) error {
+	conn, err := b.activeConnection(iface)
+	if err != nil {
+		return fmt.Errorf("设置IPv6失败: %v", err)
+	}
+
+	_, err = b.runNmcli(append([]string{"connection", "modify", conn.UUID}, settings...)...)
+	if err != nil {
+		return fmt.Errorf("设置IPv6失败: %v", err)
+	}
+
+	// 重新激活连接使配置生效
+	_, err = b.runNmcli("connection", "up", conn.UUID)
+	if err != nil {
+		return fmt.Errorf("激活连接失败: %v", err)
+	}
+
+	return nil
+}
+
+// SetStaticIPv6 设置静态IPv6地址、网关和DNS
+func (b *NmcliBackend) SetStaticIPv6(iface, address, gateway string, dns []string) error {
+	return b.modifyIPv6(iface,
+		"ipv6.method", "manual",
+		"ipv6.addresses", address,
+		"ipv6.gateway", gateway,
+		"ipv6.dns", strings.Join(dns, ","),
+		"ipv6.ignore-auto-dns", "yes",
+	)
+}
+
+// DisableIPv6Auto 只保留链路本地地址, 不再接受路由通告和IPv6 DNS
+func (b *NmcliBackend) DisableIPv6Auto(iface string) error {
+	return b.modifyIPv6(iface,
+		"ipv6.method", "link-local",
+		"ipv6.addresses", "",
+		"ipv6.gateway", "",
+		"ipv6.dns", "",
+		"ipv6.ignore-auto-dns", "yes",
+	)
+}
+
+// ResetIPv6 恢复自动获取IPv6配置
+func (b *NmcliBackend) ResetIPv6(iface string) error {
+	return b.modifyIPv6(iface,
+		"ipv6.method", "auto",
+		"ipv6.addresses", "",
+		"ipv6.gateway", "",
+		"ipv6.dns", "",
+		"ipv6.ignore-auto-dns", "no",
+	)
+}
+
+// AddRoute 添加一条经过指定网关的临时路由
+// 使用 ip 命令直接修改路由表, 不写入 NetworkManager 连接, 连接重新激活后失效
+func (b *NmcliBackend) AddRoute(iface, destination, gateway string) error {
+	result, err := runCmd(b.runner, "ip", "route", "add", destination, "via", gateway, "dev", iface)
+	if err != nil {
+		return fmt.Errorf("添加路由失败: %v. %s", err, strings.TrimSpace(result.Output()))
+	}
+	return nil
+}
+
+// DeleteRoute 删除 AddRoute 添加的路由
+func (b *NmcliBackend) DeleteRoute(iface, destination, gateway string) error {
+	result, err := runCmd(b.runner, "ip", "route", "del", destination, "via", gateway, "dev", iface)
+	if err != nil {
+		return fmt.Errorf("删除路由失败: %v. %s", err, strings.TrimSpace(result.Output()))
+	}
+	return nil
+}
+
+// ListRoutes 列出网卡上经过网关的IPv4路由
+func (b *NmcliBackend) ListRoutes(iface string) ([]Route, error) {
+	return listIPRoutes(b.runner, iface)
+}
+
+// SetDNS 设置网络接口的静态DNS, 不改动地址
+// `nmcli device modify` 只修改设备当前的DNS, 不写入连接配置
+func (b *NmcliBackend) SetDNS(iface string, dns []string) error {
+	_, err := b.runNmcli("device", "modify", iface,
+		"ipv4.ignore-auto-dns", "yes",
+		"ipv4.dns", strings.Join(dns, ","),
+	)
+	if err != nil {
+		return fmt.Errorf("设置静态DNS失败: %v", err)
+	}
+	return nil
+}
+
+// SetPreferredGateway 保留DHCP地址, 添加经过 gateway、跃点数更小的默认路由并设置静态DNS
+// 使用 ip 命令添加路由, `nmcli device modify` 只修改设备当前的DNS, 都不写入连接配置, 连接重新激活后失效
+func (b *NmcliBackend) SetPreferredGateway(iface, gateway string, dns []string) error {
+	isDHCP, err := b.GetCurrentIPConfig(iface)
+	if err != nil {
+		return fmt.Errorf("获取IP配置失败: %v", err)
+	}
+	if !isDHCP {
+		if err := b.SetDHCP(iface); err != nil {
+			return err
+		}
+	}
+
+	if err := setIPPreferredGateway(b.runner, iface, gateway); err != nil {
+		return err
+	}
+	return b.SetDNS(iface, dns)
+}
+
+// ClearPreferredGateway 删除经过 gateway 的默认路由并恢复其他默认路由原来的跃点数, 重新应用连接配置以恢复DHCP下发的DNS
+func (b *NmcliBackend) ClearPreferredGateway(iface, gateway string, defaults []Route) error {
+	if err := clearIPPreferredGateway(b.runner, iface, gateway, defaults); err != nil {
+		return err
+	}
+	if _, err := b.runNmcli("device", "reapply", iface); err != nil {
+		return fmt.Errorf("恢复DHCP DNS失败: %v", err)
+	}
+	return nil
+}
+
+// GetRouteOverride 获取覆盖默认路由的网关
+func (b *NmcliBackend) GetRouteOverride(iface string) (string, error) {
+	return getIPRouteOverride(b.runner, iface)
+}
+
+// overrideRouteSpec overrideRoutes 在 ipv4.routes 中的写法, 如 "0.0.0.0/1 192.168.31.2"
+func overrideRouteSpec(gateway string) string {
+	routes := make([]string, len(overrideRoutes))
+	for i, destination := range overrideRoutes {
+		routes[i] = destination + " " + gateway
+	}
+	return strings.Join(routes, ",")
+}
+
+// SetRouteOverride 保留DHCP地址, 在连接中添加经过 gateway 的 overrideRoutes 并忽略DHCP下发的DNS
+// 写入连接配置, 重新连接后仍然有效
+func (b *NmcliBackend) SetRouteOverride(iface, gateway string, dns []string) error {
+	conn, err := b.activeConnection(iface)
+	if err != nil {
+		return fmt.Errorf("覆盖默认路由失败: %v", err)
+	}
+
+	settings := []string{"connection", "modify", conn.UUID,
+		"ipv4.method", "auto",
+		"ipv4.addresses", "",
+		"ipv4.gateway", "",
+		"ipv4.ignore-auto-dns", "yes",
+		"ipv4.dns", strings.Join(dns, ","),
+	}
+	// 先删除已有的覆盖路由, 避免重复添加或残留经过其他网关的路由
+	if current, err := b.GetRouteOverride(iface); err == nil && current != "" {
+		settings = append(settings, "-ipv4.routes", overrideRouteSpec(current))
+	}
+	settings = append(settings, "+ipv4.routes", overrideRouteSpec(gateway))
+	if _, err := b.runNmcli(settings...); err != nil {
+		return fmt.Errorf("覆盖默认路由失败: %v", err)
+	}
+
+	// 重新激活连接使配置生效
+	_, err = b.runNmcli("connection", "up", conn.UUID)
+	if err != nil {
+		return fmt.Errorf("激活连接失败: %v", err)
+	}
+	return nil
+}
+
+// ClearRouteOverride 从连接中删除覆盖默认路由的路由, 恢复DHCP下发的DNS
+func (b *NmcliBackend) ClearRouteOverride(iface string) error {
+	conn, err := b.activeConnection(iface)
+	if err != nil {
+		return fmt.Errorf("恢复默认路由失败: %v", err)
+	}
+
+	settings := []string{"connection", "modify", conn.UUID,
+		"ipv4.ignore-auto-dns", "no",
+		"ipv4.dns", "",
+	}
+	if gateway, err := b.GetRouteOverride(iface); err == nil && gateway != "" {
+		settings = append(settings, "-ipv4.routes", overrideRouteSpec(gateway))
+	}
+	if _, err := b.runNmcli(settings...); err != nil {
+		return fmt.Errorf("恢复默认路由失败: %v", err)
+	}
+
+	// 重新激活连接使配置生效
+	_, err = b.runNmcli("connection", "up", conn.UUID)
+	if err != nil {
+		return fmt.Errorf("激活连接失败: %v", err)
+	}
+	return nil
+}
+
+// activeWiFi 获取无线网卡 iface 当前连接的WiFi名称和接入点MAC地址
+// 只读取 NetworkManager 缓存的扫描结果, 不触发重新扫描, 避免每次检查都让网卡扫描而影响连接
+func (b *NmcliBackend) activeWiFi(iface string) (ssid, bssid string, err error) {
+	output, err := b.runNmcli("-t", "-f", "ACTIVE,SSID,BSSID", "device", "wifi", "list", "ifname", iface, "--rescan", "no")
+	if err != nil {
+		return "", "", err
+	}
+	ssid, bssid, ok := parseNmcliActiveWiFi(output)
+	if !ok {
+		return "", "", fmt.Errorf("未找到WiFi信息")
+	}
+	return ssid, normalizeMAC(bssid), nil
+}
+
+// GetCurrentWiFiName 获取无线网卡 iface 当前连接的WiFi名称
+func (b *NmcliBackend) GetCurrentWiFiName(iface string) (string, error) {
+	ssid, _, err := b.activeWiFi(iface)
+	return ssid, err
+}
+
+// GetNetworkFacts 获取判断当前所在网络所需的信息
+func (b *NmcliBackend) GetNetworkFacts(iface string) (*NetworkFacts, error) {
+	facts := &NetworkFacts{Interface: iface}
+	if conn, err := b.activeConnection(iface); err == nil {
+		facts.InterfaceType = nmcliInterfaceType(conn.Type)
+	}
+
+	output, err := b.runNmcli("-t", "-f", "IP4.ADDRESS,IP4.GATEWAY,IP4.DOMAIN,DHCP4.OPTION", "device", "show", iface)
+	if err != nil {
+		return facts, fmt.Errorf("获取网络配置失败: %v", err)
+	}
+	props := parseNmcliProperties(output)
+
+	facts.IPAddress = firstProp(props, "IP4.ADDRESS")
+	if idx := strings.Index(facts.IPAddress, "/"); idx != -1 {
+		facts.IPAddress = facts.IPAddress[:idx]
+	}
+	facts.Gateway = firstProp(props, "IP4.GATEWAY")
+	facts.DNSSuffix = firstProp(props, "IP4.DOMAIN")
+	// DHCP4.OPTION 的每一项形如 "dhcp_server_identifier = 192.168.31.1"
+	for _, option := range props["DHCP4.OPTION"] {
+		if key, value, ok := strings.Cut(option, "="); ok && strings.TrimSpace(key) == "dhcp_server_identifier" {
+			facts.DHCPServer = strings.TrimSpace(value)
+		}
+	}
+
+	facts.DHCP, err = b.GetCurrentIPConfig(iface)
+	if err != nil {
+		return facts, err
+	}
+
+	// 网关的MAC地址从邻居表中读取, 获取不到时留空
+	if facts.Gateway != "" {
+		if result, err := runCmd(b.runner, "ip", "neigh", "show", facts.Gateway); err == nil {
+			facts.GatewayMAC = parseIPNeigh(string(result.Stdout), facts.Gateway)
+		}
+	}
+
+	// 有线网络没有WiFi信息
+	if facts.InterfaceType == InterfaceWiFi {
+		facts.SSID, facts.BSSID, _ = b.activeWiFi(iface)
+	}
+
+	return facts, nil
+}
+
+// GetCurrentNetworkStatus 获取网络接口的详细状态
+func (b *NmcliBackend) GetCurrentNetworkStatus(iface string) (*NetworkStatus, error) {
+	status := &NetworkStatus{Interface: iface}
+	if conn, err := b.activeConnection(iface); err == nil {
+		status.InterfaceType = nmcliInterfaceType(conn.Type)
+	}
+
+	// 获取WiFi名称, 有线网卡不显示WiFi
+	status.WiFiName = "未连接"
+	if status.InterfaceType == InterfaceWiFi {
+		if ssid, err := b.GetCurrentWiFiName(iface); err == nil {
+			status.WiFiName = ssid
+			status.WiFiConnected = true
+		}
+	}
+
+	// 获取设备当前生效的地址、网关和DNS
+	output, err := b.runNmcli("-t", "-f", "IP4.ADDRESS,IP4.GATEWAY,IP4.DNS", "device", "show", iface)
+	if err != nil {
+		return status, fmt.Errorf("获取网络配置失败: %v", err)
+	}
+	props := parseNmcliProperties(output)
+
+	status.IPAddress = firstProp(props, "IP4.ADDRESS")
+	if idx := strings.Index(status.IPAddress, "/"); idx != -1 {
+		status.IPAddress = status.IPAddress[:idx]
+	}
+	status.Gateway = firstProp(props, "IP4.GATEWAY")
+
+	// 设置分配方式, NetworkManager 中地址和DNS的分配方式由同一个 ipv4.method 决定
+	isDHCP, err := b.GetCurrentIPConfig(iface)
+	if err == nil && isDHCP {
+		status.IPAssignment = "自动(DHCP)"
+		status.DNSAssignment = "自动(DHCP)"
+	} else {
+		status.IPAssignment = "手动"
+		status.DNSAssignment = "手动"
+	}
+
+	// 同时测试网关和DNS连通性
+	status.probe(props["IP4.DNS"], b.pinger.PingAll)
+
+	return status, nil
+}
+
+// Ping 测试网络连通性
+func (b *NmcliBackend) Ping(host string) bool {
+	return b.pinger.Ping(host).Reachable()
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// readFixture 读取 testdata 下记录的命令输出
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("读取测试数据失败: %v", err)
+	}
+	return string(data)
+}
+
+func TestSplitNmcliFields(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"yes:HomeWiFi", []string{"yes", "HomeWiFi"}},
+		{`yes:Home\:5G`, []string{"yes", "Home:5G"}},
+		{`yes:AA\:BB\:CC\:DD\:EE\:FF`, []string{"yes", "AA:BB:CC:DD:EE:FF"}},
+		{`name:back\\slash`, []string{"name", `back\slash`}},
+		{"no:", []string{"no", ""}},
+	}
+	for _, tt := range tests {
+		if got := splitNmcliFields(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitNmcliFields(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseNmcliActiveConnections(t *testing.T) {
+	conns := parseNmcliActiveConnections(readFixture(t, "nmcli/connection_show_active.txt"))
+	want := []nmcliConnection{
+		{Name: "Home:5G", UUID: "3f1e6c2a-8b7d-4e21-9a3c-5d0f1b2c3d4e", Type: "802-11-wireless", Device: "wlp2s0"},
+		{Name: "Wired connection 1", UUID: "8a2b4c6d-1e3f-4a5b-8c7d-9e0f1a2b3c4d", Type: "802-3-ethernet", Device: "enp3s0"},
+		{Name: "docker0", UUID: "0c9d8e7f-6a5b-4c3d-2e1f-0a9b8c7d6e5f", Type: "bridge", Device: "docker0"},
+	}
+	if !reflect.DeepEqual(conns, want) {
+		t.Errorf("connections = %+v, want %+v", conns, want)
+	}
+}
+
+func TestParseNmcliProperties(t *testing.T) {
+	manual := parseNmcliProperties(readFixture(t, "nmcli/connection_show_manual.txt"))
+	want := map[string][]string{
+		"ipv4.method":          {"manual"},
+		"ipv4.addresses":       {"192.168.31.50/24"},
+		"ipv4.gateway":         {"192.168.31.2"},
+		"ipv4.dns":             {"192.168.31.2", "223.5.5.5"},
+		"ipv4.ignore-auto-dns": {"yes"},
+		"ipv6.method":          {"manual"},
+		"ipv6.addresses":       {"2001:db8::50/64"},
+	}
+	if !reflect.DeepEqual(manual, want) {
+		t.Errorf("manual properties = %v, want %v", manual, want)
+	}
+
+	// 空值和 "--" 视为未设置
+	auto := parseNmcliProperties(readFixture(t, "nmcli/connection_show_auto.txt"))
+	if len(auto) != 3 || firstProp(auto, "ipv4.method") != "auto" || firstProp(auto, "ipv4.gateway") != "" {
+		t.Errorf("auto properties = %v", auto)
+	}
+
+	// 带序号的键合并为同一个键
+	device := parseNmcliProperties(readFixture(t, "nmcli/device_show.txt"))
+	if got := device["IP4.DNS"]; !reflect.DeepEqual(got, []string{"192.168.31.1", "114.114.114.114"}) {
+		t.Errorf("IP4.DNS = %q", got)
+	}
+	if got := firstProp(device, "IP4.ADDRESS"); got != "192.168.31.100/24" {
+		t.Errorf("IP4.ADDRESS = %q", got)
+	}
+}
+
+func TestParseNmcliActiveWiFi(t *testing.T) {
+	ssid, bssid, ok := parseNmcliActiveWiFi(readFixture(t, "nmcli/device_wifi_list.txt"))
+	if !ok || ssid != "Home:5G" || bssid != "AA:BB:CC:DD:EE:FF" {
+		t.Errorf("ssid, bssid = %q, %q, %v, want Home:5G, AA:BB:CC:DD:EE:FF", ssid, bssid, ok)
+	}
+	if _, _, ok := parseNmcliActiveWiFi("no:CMCC-8F2A:11\\:22\\:33\\:44\\:55\\:66\n"); ok {
+		t.Error("expected no active WiFi")
+	}
+}
+
+func TestNmcliActiveWiFiWithoutRescan(t *testing.T) {
+	const list = "nmcli -t -f ACTIVE,SSID,BSSID device wifi list ifname wlp2s0 --rescan no"
+	runner := &stubRunner{outputs: map[string]string{list: readFixture(t, "nmcli/device_wifi_list.txt")}}
+	backend := NewNmcliBackend(runner)
+
+	ssid, bssid, err := backend.activeWiFi("wlp2s0")
+	if err != nil || ssid != "Home:5G" || bssid != "aa:bb:cc:dd:ee:ff" {
+		t.Errorf("activeWiFi = %q, %q, %v, want Home:5G, aa:bb:cc:dd:ee:ff", ssid, bssid, err)
+	}
+	// SSID和BSSID一次读取, 不触发重新扫描
+	if want := []string{list}; !reflect.DeepEqual(runner.commands, want) {
+		t.Errorf("commands = %q, want %q", runner.commands, want)
+	}
+}
+
+func TestNmcliGetIPv6ConfigManual(t *testing.T) {
+	const props = "nmcli -t -f ipv4.method,ipv4.addresses,ipv4.gateway,ipv4.dns,ipv4.ignore-auto-dns,ipv6.method,ipv6.addresses connection show 3f1e6c2a-8b7d-4e21-9a3c-5d0f1b2c3d4e"
+	device := "IP6.ADDRESS[1]:2001\\:db8\\:\\:50/64\nIP6.ADDRESS[2]:fe80\\:\\:1/64\nIP6.GATEWAY:2001\\:db8\\:\\:1\nIP6.DNS[1]:2001\\:db8\\:\\:1\n"
+	runner := &stubRunner{outputs: map[string]string{
+		"nmcli -t -f NAME,UUID,TYPE,DEVICE connection show --active":     readFixture(t, "nmcli/connection_show_active.txt"),
+		"nmcli -t -f IP6.ADDRESS,IP6.GATEWAY,IP6.DNS device show wlp2s0": device,
+	}}
+	backend := NewNmcliBackend(runner)
+
+	tests := []struct {
+		fixture string
+		manual  bool
+	}{
+		{"nmcli/connection_show_manual.txt", true},
+		{"nmcli/connection_show_auto.txt", false},
+	}
+	for _, tt := range tests {
+		runner.outputs[props] = readFixture(t, tt.fixture)
+		config, err := backend.GetIPv6Config("wlp2s0")
+		if err != nil {
+			t.Fatalf("GetIPv6Config: %v", err)
+		}
+		want := []IPv6Address{{IP: "2001:db8::50", PrefixLen: 64, Manual: tt.manual}}
+		if !reflect.DeepEqual(config.Addresses, want) {
+			t.Errorf("%s: Addresses = %+v, want %+v", tt.fixture, config.Addresses, want)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// 默认Ping参数
+const (
+	DefaultPingCount    = 3                      // 每个目标发送的探测包数量
+	DefaultPingTimeout  = time.Second            // 每个探测包等待回复的时间
+	DefaultPingInterval = 200 * time.Millisecond // 探测包之间的间隔
+)
+
+// PingResult 对一个目标的Ping结果
+type PingResult struct {
+	Host     string        // 目标地址
+	Sent     int           // 发送的探测包数量
+	Received int           // 收到的回复数量
+	RTT      time.Duration // 平均往返时间, 没有回复时为0
+	MinRTT   time.Duration // 最短往返时间
+	MaxRTT   time.Duration // 最长往返时间
+	TTL      int           // 最后一次回复的TTL(IPv6为跳数限制), 未知时为 -1
+	Err      error         `json:"-"` // 无法创建套接字或发送探测包时的错误, 命令记录中保存为该条记录的错误
+}
+
+// Reachable 是否收到过回复
+func (r *PingResult) Reachable() bool {
+	return r.Received > 0
+}
+
+// Loss 丢包率, 0 到 1
+func (r *PingResult) Loss() float64 {
+	if r.Sent == 0 {
+		return 1
+	}
+	return float64(r.Sent-r.Received) / float64(r.Sent)
+}
+
+// RTTMillis 平均往返时间(毫秒)
+func (r *PingResult) RTTMillis() float64 {
+	return float64(r.RTT) / float64(time.Millisecond)
+}
+
+// Pinger 纯Go实现的ICMP Ping, 不依赖系统 ping 命令
+// Windows 使用系统的 IcmpSendEcho 接口, 不需要管理员权限;
+// 其他系统优先使用无需特权的ICMP数据报套接字(Linux 需要 net.ipv4.ping_group_range 包含当前用户组),
+// 失败时使用原始套接字(需要管理员权限或 CAP_NET_RAW)
+type Pinger struct {
+	Count    int           // 每个目标发送的探测包数量
+	Timeout  time.Duration // 每个探测包等待回复的时间
+	Interval time.Duration // 探测包之间的间隔
+	Runner   CommandRunner // 不为空时按它的记录模式记录或回放Ping结果
+}
+
+// NewPinger 创建Pinger
+func NewPinger(count int, timeout time.Duration) *Pinger {
+	return &Pinger{Count: count, Timeout: timeout, Interval: DefaultPingInterval}
+}
+
+// pingSeq 原始套接字会收到本机所有的ICMP回复, 每次Ping使用不同的标识区分
+var pingSeq uint32
+
+// Ping 向目标发送 Count 个探测包, 统计往返时间、丢包率和TTL
+// 设置了 Runner 时结果记录为 IcmpEcho 调用, 回放时不发送探测包
+func (p *Pinger) Ping(host string) *PingResult {
+	if p.Runner == nil {
+		return p.ping(host)
+	}
+	result, err := recordCall(p.Runner, Command{Name: "IcmpEcho", Args: []string{host}}, func() (*PingResult, error) {
+		result := p.ping(host)
+		return result, result.Err
+	})
+	if result == nil {
+		result = &PingResult{Host: host, TTL: -1}
+	}
+	result.Err = err
+	return result
+}
+
+// ping 向目标发送探测包
+func (p *Pinger) ping(host string) *PingResult {
+	result := &PingResult{Host: host, TTL: -1}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		addrs, err := net.LookupIP(host)
+		if err != nil || len(addrs) == 0 {
+			result.Err = fmt.Errorf("解析地址失败: %s", host)
+			return result
+		}
+		ip = addrs[0]
+	}
+
+	conn, err := openEchoer(ip.To4() == nil)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer conn.Close()
+
+	id := int(uint32(os.Getpid())+atomic.AddUint32(&pingSeq, 1)) & 0xffff
+	var total time.Duration
+	for seq := 1; seq <= p.Count; seq++ {
+		if seq > 1 {
+			time.Sleep(p.Interval)
+		}
+		result.Sent++
+		rtt, ttl, err := conn.echo(ip, id, seq, p.Timeout)
+		if err != nil {
+			if !isTimeout(err) {
+				result.Err = err
+			}
+			continue
+		}
+
+		result.Received++
+		result.TTL = ttl
+		total += rtt
+		if result.MinRTT == 0 || rtt < result.MinRTT {
+			result.MinRTT = rtt
+		}
+		if rtt > result.MaxRTT {
+			result.MaxRTT = rtt
+		}
+	}
+	if result.Received > 0 {
+		result.RTT = total / time.Duration(result.Received)
+	}
+	return result
+}
+
+// PingAll 同时Ping多个目标, 结果与 hosts 的顺序一致
+func (p *Pinger) PingAll(hosts ...string) []*PingResult {
+	results := make([]*PingResult, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			results[i] = p.Ping(host)
+		}(i, host)
+	}
+	wg.Wait()
+	return results
+}
+
+// echoer 发送ICMP回显请求并等待回复
+type echoer interface {
+	// echo 发送一个探测包, 超时未收到回复时返回超时错误
+	echo(dst net.IP, id, seq int, timeout time.Duration) (rtt time.Duration, ttl int, err error)
+	Close() error
+}
+
+// icmpConn 发送和接收ICMP回显的套接字
+type icmpConn struct {
+	conn     *icmp.PacketConn
+	ipv6     bool // 是否为ICMPv6
+	datagram bool // 是否为数据报套接字, 否则为原始套接字
+}
+
+// listenICMP 打开ICMP套接字, 数据报套接字不可用时使用原始套接字
+func listenICMP(useIPv6 bool) (*icmpConn, error) {
+	datagramNetwork, rawNetwork, address := "udp4", "ip4:icmp", "0.0.0.0"
+	if useIPv6 {
+		datagramNetwork, rawNetwork, address = "udp6", "ip6:ipv6-icmp", "::"
+	}
+
+	c := &icmpConn{ipv6: useIPv6, datagram: true}
+	conn, err := icmp.ListenPacket(datagramNetwork, address)
+	if err != nil {
+		c.datagram = false
+		conn, err = icmp.ListenPacket(rawNetwork, address)
+		if err != nil {
+			return nil, fmt.Errorf("创建ICMP套接字失败: %v", err)
+		}
+	}
+	c.conn = conn
+
+	// 接收TTL, 部分系统(如Windows)不支持, 此时TTL从IP头部读取或留空
+	if useIPv6 {
+		conn.IPv6PacketConn().SetControlMessage(ipv6.FlagHopLimit, true)
+	} else {
+		conn.IPv4PacketConn().SetControlMessage(ipv4.FlagTTL, true)
+	}
+	return c, nil
+}
+
+// Close 关闭套接字
+func (c *icmpConn) Close() error {
+	return c.conn.Close()
+}
+
+// addr 目标地址, 数据报套接字使用UDP地址, 原始套接字使用IP地址
+func (c *icmpConn) addr(ip net.IP) net.Addr {
+	if c.datagram {
+		return &net.UDPAddr{IP: ip}
+	}
+	return &net.IPAddr{IP: ip}
+}
+
+// read 读取一个ICMP报文, 返回报文长度、TTL和来源地址
+func (c *icmpConn) read(buf []byte) (n, ttl int, peer net.IP, err error) {
+	ttl = -1
+	var src net.Addr
+	if c.ipv6 {
+		var cm *ipv6.ControlMessage
+		n, cm, src, err = c.conn.IPv6PacketConn().ReadFrom(buf)
+		if cm != nil {
+			ttl = cm.HopLimit
+		}
+	} else {
+		var cm *ipv4.ControlMessage
+		n, cm, src, err = c.conn.IPv4PacketConn().ReadFrom(buf)
+		if cm != nil {
+			ttl = cm.TTL
+		}
+		// Windows 的原始套接字收到的数据包含IPv4头部
+		if err == nil && runtime.GOOS == "windows" && !c.datagram && n > 0 && buf[0]>>4 == 4 {
+			if hdrLen := int(buf[0]&0x0f) << 2; n >= hdrLen && hdrLen > 8 {
+				ttl = int(buf[8])
+				n = copy(buf, buf[hdrLen:n])
+			}
+		}
+	}
+	if err != nil {
+		return 0, -1, nil, err
+	}
+
+	switch addr := src.(type) {
+	case *net.UDPAddr:
+		peer = addr.IP
+	case *net.IPAddr:
+		peer = addr.IP
+	}
+	return n, ttl, peer, nil
+}
+
+// echo 发送一个探测包并等待对应的回复
+func (c *icmpConn) echo(dst net.IP, id, seq int, timeout time.Duration) (rtt time.Duration, ttl int, err error) {
+	var requestType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	protocol := 1 // ICMP
+	if c.ipv6 {
+		requestType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+		protocol = 58 // ICMPv6
+	}
+
+	msg := icmp.Message{
+		Type: requestType,
+		Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("RouterSwitcher")},
+	}
+	data, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, -1, err
+	}
+
+	start := time.Now()
+	if _, err := c.conn.WriteTo(data, c.addr(dst)); err != nil {
+		return 0, -1, fmt.Errorf("发送ICMP请求失败: %v", err)
+	}
+	if err := c.conn.SetReadDeadline(start.Add(timeout)); err != nil {
+		return 0, -1, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, ttl, peer, err := c.read(buf)
+		if err != nil {
+			return 0, -1, err
+		}
+		reply, err := icmp.ParseMessage(protocol, buf[:n])
+		if err != nil || reply.Type != replyType {
+			continue
+		}
+		echo, ok := reply.Body.(*icmp.Echo)
+		// 数据报套接字的标识会被内核改写为端口号, 只比较序号
+		if !ok || echo.Seq != seq || (!c.datagram && echo.ID != id) || !peer.Equal(dst) {
+			continue
+		}
+		return time.Since(start), ttl, nil
+	}
+}
+
+// isTimeout 是否为等待回复超时
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
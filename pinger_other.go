@@ -0,0 +1,12 @@
+//go:build !windows
+
+package main
+
+// openEchoer 打开ICMP套接字
+func openEchoer(useIPv6 bool) (echoer, error) {
+	conn, err := listenICMP(useIPv6)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
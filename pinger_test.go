@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// pingOrSkip Ping 环回地址, 当前环境无法创建ICMP套接字时跳过测试
+func pingOrSkip(t *testing.T, pinger *Pinger, host string) *PingResult {
+	t.Helper()
+	result := pinger.Ping(host)
+	if result.Err != nil && result.Sent == 0 {
+		t.Skipf("无法创建ICMP套接字: %v", result.Err)
+	}
+	return result
+}
+
+func TestPingLoopback(t *testing.T) {
+	pinger := &Pinger{Count: 3, Timeout: time.Second, Interval: 10 * time.Millisecond}
+	result := pingOrSkip(t, pinger, "127.0.0.1")
+
+	if result.Err != nil {
+		t.Fatalf("Ping 127.0.0.1: %v", result.Err)
+	}
+	if !result.Reachable() || result.Sent != 3 || result.Received != 3 || result.Loss() != 0 {
+		t.Fatalf("Ping 127.0.0.1 = %+v", result)
+	}
+	if result.MinRTT <= 0 || result.MinRTT > result.RTT || result.RTT > result.MaxRTT {
+		t.Errorf("往返时间 min=%v avg=%v max=%v", result.MinRTT, result.RTT, result.MaxRTT)
+	}
+	if result.TTL == 0 {
+		t.Errorf("TTL = 0")
+	}
+}
+
+func TestPingLoopbackIPv6(t *testing.T) {
+	pinger := &Pinger{Count: 1, Timeout: time.Second, Interval: DefaultPingInterval}
+	result := pingOrSkip(t, pinger, "::1")
+	if result.Err != nil || !result.Reachable() {
+		t.Skipf("环回地址 ::1 不可用: %+v", result)
+	}
+	if result.Loss() != 0 || result.RTT <= 0 {
+		t.Errorf("Ping ::1 = %+v", result)
+	}
+}
+
+func TestPingAllKeepsOrder(t *testing.T) {
+	pinger := &Pinger{Count: 1, Timeout: 200 * time.Millisecond, Interval: DefaultPingInterval}
+	pingOrSkip(t, pinger, "127.0.0.1")
+
+	hosts := []string{"127.0.0.1", "host.invalid", "127.0.0.2"}
+	results := pinger.PingAll(hosts...)
+	for i, result := range results {
+		if result.Host != hosts[i] {
+			t.Errorf("结果 %d 的目标 = %s, want %s", i, result.Host, hosts[i])
+		}
+	}
+	if !results[0].Reachable() {
+		t.Errorf("127.0.0.1 应可达: %+v", results[0])
+	}
+	if results[1].Err == nil || results[1].Sent != 0 || results[1].Loss() != 1 {
+		t.Errorf("无法解析的地址应返回错误: %+v", results[1])
+	}
+}
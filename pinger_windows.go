@@ -0,0 +1,148 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	iphlpapi            = windows.NewLazySystemDLL("iphlpapi.dll")
+	procIcmpCreateFile  = iphlpapi.NewProc("IcmpCreateFile")
+	procIcmp6CreateFile = iphlpapi.NewProc("Icmp6CreateFile")
+	procIcmpCloseHandle = iphlpapi.NewProc("IcmpCloseHandle")
+	procIcmpSendEcho    = iphlpapi.NewProc("IcmpSendEcho")
+	procIcmp6SendEcho2  = iphlpapi.NewProc("Icmp6SendEcho2")
+)
+
+// IcmpSendEcho 的状态码
+const (
+	ipSuccess     = 0
+	ipReqTimedOut = 11010
+)
+
+// ipOptionInformation IP_OPTION_INFORMATION
+type ipOptionInformation struct {
+	TTL         uint8
+	Tos         uint8
+	Flags       uint8
+	OptionsSize uint8
+	OptionsData uintptr
+}
+
+// icmpEchoReply ICMP_ECHO_REPLY
+type icmpEchoReply struct {
+	Address       uint32
+	Status        uint32
+	RoundTripTime uint32
+	DataSize      uint16
+	Reserved      uint16
+	Data          uintptr
+	Options       ipOptionInformation
+}
+
+// icmpv6EchoReply ICMPV6_ECHO_REPLY, 地址部分(IPV6_ADDRESS_EX)按1字节对齐, 共26字节
+type icmpv6EchoReply struct {
+	Address       [26]byte
+	_             [2]byte
+	Status        uint32
+	RoundTripTime uint32
+}
+
+// icmpHandle 使用 IcmpSendEcho 发送ICMP回显, 不需要管理员权限
+type icmpHandle struct {
+	handle uintptr
+	ipv6   bool
+}
+
+// openEchoer 打开ICMP句柄, 失败时使用套接字
+// 原始套接字需要管理员权限, 两者都失败时返回包含原因的错误
+func openEchoer(useIPv6 bool) (echoer, error) {
+	handle, err := openIcmpHandle(useIPv6)
+	if err == nil {
+		return handle, nil
+	}
+	conn, sockErr := listenICMP(useIPv6)
+	if sockErr != nil {
+		return nil, fmt.Errorf("创建ICMP句柄失败: %v; 使用原始套接字需要以管理员身份运行: %v", err, sockErr)
+	}
+	return conn, nil
+}
+
+// openIcmpHandle 调用 IcmpCreateFile 或 Icmp6CreateFile
+func openIcmpHandle(useIPv6 bool) (*icmpHandle, error) {
+	proc := procIcmpCreateFile
+	if useIPv6 {
+		proc = procIcmp6CreateFile
+	}
+	if err := proc.Find(); err != nil {
+		return nil, err
+	}
+	handle, _, err := proc.Call()
+	if windows.Handle(handle) == windows.InvalidHandle {
+		return nil, err
+	}
+	return &icmpHandle{handle: handle, ipv6: useIPv6}, nil
+}
+
+// Close 关闭ICMP句柄
+func (h *icmpHandle) Close() error {
+	procIcmpCloseHandle.Call(h.handle)
+	return nil
+}
+
+// echo 发送一个探测包并等待回复, 标识和序号由系统管理
+func (h *icmpHandle) echo(dst net.IP, id, seq int, timeout time.Duration) (rtt time.Duration, ttl int, err error) {
+	data := []byte("RouterSwitcher")
+	// 回复缓冲区需要容纳回复结构、回显数据、8字节ICMP错误信息和 IO_STATUS_BLOCK
+	reply := make([]byte, unsafe.Sizeof(icmpEchoReply{})+uintptr(len(data))+8+16)
+	timeoutMillis := uintptr(timeout / time.Millisecond)
+
+	var n uintptr
+	start := time.Now()
+	if h.ipv6 {
+		var src, dest windows.RawSockaddrInet6
+		src.Family, dest.Family = windows.AF_INET6, windows.AF_INET6
+		copy(dest.Addr[:], dst.To16())
+		n, _, err = procIcmp6SendEcho2.Call(h.handle, 0, 0, 0,
+			uintptr(unsafe.Pointer(&src)), uintptr(unsafe.Pointer(&dest)),
+			uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)), 0,
+			uintptr(unsafe.Pointer(&reply[0])), uintptr(len(reply)), timeoutMillis)
+	} else {
+		ip4 := dst.To4()
+		addr := uint32(ip4[0]) | uint32(ip4[1])<<8 | uint32(ip4[2])<<16 | uint32(ip4[3])<<24
+		n, _, err = procIcmpSendEcho.Call(h.handle, uintptr(addr),
+			uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)), 0,
+			uintptr(unsafe.Pointer(&reply[0])), uintptr(len(reply)), timeoutMillis)
+	}
+	if n == 0 {
+		var errno windows.Errno
+		if errors.As(err, &errno) && errno == ipReqTimedOut {
+			return 0, -1, os.ErrDeadlineExceeded
+		}
+		return 0, -1, fmt.Errorf("发送ICMP请求失败: %v", err)
+	}
+
+	// 回复中的往返时间只精确到毫秒, 使用本地计时
+	rtt = time.Since(start)
+	var status uint32
+	ttl = -1
+	if h.ipv6 {
+		status = (*icmpv6EchoReply)(unsafe.Pointer(&reply[0])).Status
+	} else {
+		r := (*icmpEchoReply)(unsafe.Pointer(&reply[0]))
+		status, ttl = r.Status, int(r.Options.TTL)
+	}
+	// 目标不可达、TTL超时等状态与套接字方式一致, 视为没有收到回复
+	if status != ipSuccess {
+		return 0, -1, os.ErrDeadlineExceeded
+	}
+	return rtt, ttl, nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// 匹配规则类型
+const (
+	RuleSSID       = "ssid"        // WiFi名称
+	RuleBSSID      = "bssid"       // 接入点MAC地址
+	RuleSubnet     = "subnet"      // 当前地址所在子网, 如 192.168.31.0/24
+	RuleGatewayMAC = "gateway_mac" // 默认网关的MAC地址
+	RuleDHCPServer = "dhcp_server" // DHCP服务器地址
+	RuleDNSSuffix  = "dns_suffix"  // 连接的DNS后缀
+)
+
+// 规则组合方式
+const (
+	MatchAll = "all" // 全部规则都满足
+	MatchAny = "any" // 任一规则满足
+)
+
+// MatchRule 网络配置的一条匹配规则
+type MatchRule struct {
+	Type   string   // 规则类型: ssid, bssid, subnet, gateway_mac, dhcp_server, dns_suffix
+	Values []string // 可选值, 当前网络与其中任一值相同即满足
+}
+
+// NetworkFacts 判断当前所在网络时使用的信息
+type NetworkFacts struct {
+	Interface     string // 网络接口名称
+	InterfaceType string // 网卡类型: wifi 或 ethernet
+	SSID          string // WiFi名称, 有线网络为空
+	BSSID         string // 接入点MAC地址
+	IPAddress     string // 当前IPv4地址
+	DHCP          bool   // 当前地址是否由DHCP分配
+	Gateway       string // 默认网关
+	GatewayMAC    string // 默认网关的MAC地址
+	DHCPServer    string // DHCP服务器地址
+	DNSSuffix     string // 连接的DNS后缀
+}
+
+// RuleResult 一条规则的判断结果
+type RuleResult struct {
+	Rule    MatchRule // 规则
+	Actual  string    // 当前网络中的实际值
+	Matched bool      // 是否满足
+	Note    string    // 补充说明
+}
+
+// ProfileTrace 一个网络配置的匹配过程
+type ProfileTrace struct {
+	Profile   string       // 网络配置名称
+	MatchMode string       // 规则组合方式
+	Results   []RuleResult // 各条规则的结果
+	Matched   bool         // 是否匹配
+}
+
+// MatchDecision 自适应模式的一次判断，返回给前端用于解释为什么切换或不切换
+type MatchDecision struct {
+	Time              string         // 判断时间
+	Interface         string         // 网卡名称
+	Facts             *NetworkFacts  // 当前网络信息
+	Traces            []ProfileTrace // 各网络配置的匹配过程
+	Profile           string         // 匹配到的网络配置, 没有匹配时为空
+	SideRouterChecked bool           // 是否检测了旁路由
+	SideRouterOK      bool           // 旁路由是否健康
+	Health            *HealthResult  // 旁路由健康检查结果
+	Action            string         // 最终动作: static(旁路由), fallback(保留静态IP改用主路由网关) 或 dhcp, 获取网络信息失败时为空
+	Paused            bool           // 是否因切换过于频繁暂停了自动切换
+	Summary           []string       // 便于阅读的判断过程
+}
+
+// normalizeMAC 统一MAC地址格式为小写冒号分隔, 兼容 aa-bb-cc-dd-ee-ff 写法
+func normalizeMAC(mac string) string {
+	mac = strings.ToLower(strings.TrimSpace(mac))
+	return strings.ReplaceAll(mac, "-", ":")
+}
+
+// rulesOf 返回网络配置的全部规则, SSIDs 视为一条 ssid 规则
+func rulesOf(profile *Profile) []MatchRule {
+	var rules []MatchRule
+	if len(profile.SSIDs) > 0 {
+		rules = append(rules, MatchRule{Type: RuleSSID, Values: profile.SSIDs})
+	}
+	return append(rules, profile.Rules...)
+}
+
+// evaluateRule 判断一条规则
+// dhcpFacts 为最近一次DHCP状态下记录的网络信息; 切换到静态IP后没有DHCP服务器和DNS后缀,
+// 这两类规则改用该记录判断, 避免刚切换过去就因规则不满足而切回
+func evaluateRule(rule MatchRule, facts, dhcpFacts *NetworkFacts) RuleResult {
+	result := RuleResult{Rule: rule}
+
+	source := facts
+	if !facts.DHCP && dhcpFacts != nil && (rule.Type == RuleDHCPServer || rule.Type == RuleDNSSuffix) {
+		source = dhcpFacts
+		result.Note = "静态IP下使用最近一次DHCP时的记录"
+	}
+
+	switch rule.Type {
+	case RuleSSID:
+		result.Actual = source.SSID
+		result.Matched = source.SSID != "" && containsString(rule.Values, source.SSID)
+	case RuleBSSID:
+		result.Actual = normalizeMAC(source.BSSID)
+		result.Matched = result.Actual != "" && containsMAC(rule.Values, result.Actual)
+	case RuleGatewayMAC:
+		result.Actual = normalizeMAC(source.GatewayMAC)
+		result.Matched = result.Actual != "" && containsMAC(rule.Values, result.Actual)
+	case RuleSubnet:
+		result.Actual = source.IPAddress
+		ip := net.ParseIP(source.IPAddress)
+		for _, value := range rule.Values {
+			_, subnet, err := net.ParseCIDR(strings.TrimSpace(value))
+			if err != nil {
+				result.Note = fmt.Sprintf("无效的子网: %s", value)
+				continue
+			}
+			if ip != nil && subnet.Contains(ip) {
+				result.Matched = true
+				break
+			}
+		}
+	case RuleDHCPServer:
+		result.Actual = source.DHCPServer
+		result.Matched = source.DHCPServer != "" && containsString(rule.Values, source.DHCPServer)
+	case RuleDNSSuffix:
+		result.Actual = source.DNSSuffix
+		for _, value := range rule.Values {
+			if source.DNSSuffix != "" && strings.EqualFold(strings.TrimSuffix(value, "."), strings.TrimSuffix(source.DNSSuffix, ".")) {
+				result.Matched = true
+				break
+			}
+		}
+	default:
+		result.Note = fmt.Sprintf("未知的规则类型: %s", rule.Type)
+	}
+
+	return result
+}
+
+// MatchProfile 按规则判断网络配置是否与当前网络匹配
+// 没有任何规则的配置不会匹配任何网络
+func MatchProfile(profile *Profile, facts, dhcpFacts *NetworkFacts) ProfileTrace {
+	trace := ProfileTrace{Profile: profile.Name, MatchMode: profile.MatchMode}
+	if trace.MatchMode != MatchAny {
+		trace.MatchMode = MatchAll
+	}
+
+	rules := rulesOf(profile)
+	if len(rules) == 0 {
+		return trace
+	}
+
+	trace.Matched = trace.MatchMode == MatchAll
+	for _, rule := range rules {
+		result := evaluateRule(rule, facts, dhcpFacts)
+		trace.Results = append(trace.Results, result)
+		if trace.MatchMode == MatchAll {
+			trace.Matched = trace.Matched && result.Matched
+		} else {
+			trace.Matched = trace.Matched || result.Matched
+		}
+	}
+	return trace
+}
+
+// describe 生成便于阅读的网络信息
+func (f *NetworkFacts) describe() string {
+	assignment := "静态"
+	if f.DHCP {
+		assignment = "DHCP"
+	}
+	return fmt.Sprintf("当前网络: 接口 %s, WiFi %s, BSSID %s, IP %s(%s), 网关 %s(%s), DHCP服务器 %s, DNS后缀 %s",
+		f.Interface, orNone(f.SSID), orNone(f.BSSID), orNone(f.IPAddress), assignment,
+		orNone(f.Gateway), orNone(f.GatewayMAC), orNone(f.DHCPServer), orNone(f.DNSSuffix))
+}
+
+// describe 生成便于阅读的匹配过程
+func (t ProfileTrace) describe() []string {
+	if len(t.Results) == 0 {
+		return []string{fmt.Sprintf("配置「%s」没有匹配规则，跳过", t.Profile)}
+	}
+
+	verdict := "不匹配"
+	if t.Matched {
+		verdict = "匹配"
+	}
+	lines := []string{fmt.Sprintf("配置「%s」(%s): %s", t.Profile, t.MatchMode, verdict)}
+	for _, r := range t.Results {
+		mark := "✗"
+		if r.Matched {
+			mark = "✓"
+		}
+		line := fmt.Sprintf("  %s %s 期望 %s, 实际 %s", mark, r.Rule.Type, strings.Join(r.Rule.Values, "/"), orNone(r.Actual))
+		if r.Note != "" {
+			line += " (" + r.Note + ")"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// orNone 空字符串显示为"无"
+func orNone(value string) string {
+	if value == "" {
+		return "无"
+	}
+	return value
+}
+
+// containsString 列表中是否包含指定字符串
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// containsMAC 列表中是否包含指定MAC地址
+func containsMAC(values []string, mac string) bool {
+	for _, value := range values {
+		if normalizeMAC(value) == mac {
+			return true
+		}
+	}
+	return false
+}
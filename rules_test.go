@@ -0,0 +1,137 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// testFacts 便于测试的网络信息: 家里的WiFi, DHCP分配地址
+var testFacts = NetworkFacts{
+	Interface:     "WLAN",
+	InterfaceType: InterfaceWiFi,
+	SSID:          "HomeWiFi",
+	BSSID:         "aa:bb:cc:dd:ee:ff",
+	IPAddress:     "192.168.1.100",
+	DHCP:          true,
+	Gateway:       "192.168.1.1",
+	GatewayMAC:    "11:22:33:44:55:66",
+	DHCPServer:    "192.168.1.1",
+	DNSSuffix:     "lan",
+}
+
+func TestEvaluateRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    MatchRule
+		matched bool
+		actual  string
+		note    string
+	}{
+		{"SSID相同", MatchRule{Type: RuleSSID, Values: []string{"Office", "HomeWiFi"}}, true, "HomeWiFi", ""},
+		{"SSID区分大小写", MatchRule{Type: RuleSSID, Values: []string{"homewifi"}}, false, "HomeWiFi", ""},
+		{"BSSID短横线写法", MatchRule{Type: RuleBSSID, Values: []string{"AA-BB-CC-DD-EE-FF"}}, true, "aa:bb:cc:dd:ee:ff", ""},
+		{"网关MAC短横线写法", MatchRule{Type: RuleGatewayMAC, Values: []string{" 11-22-33-44-55-66 "}}, true, "11:22:33:44:55:66", ""},
+		{"网关MAC不同", MatchRule{Type: RuleGatewayMAC, Values: []string{"11-22-33-44-55-77"}}, false, "11:22:33:44:55:66", ""},
+		{"地址在子网中", MatchRule{Type: RuleSubnet, Values: []string{"10.0.0.0/8", "192.168.1.0/24"}}, true, "192.168.1.100", ""},
+		{"地址不在子网中", MatchRule{Type: RuleSubnet, Values: []string{"192.168.31.0/24"}}, false, "192.168.1.100", ""},
+		{"无效的子网", MatchRule{Type: RuleSubnet, Values: []string{"192.168.1.0/33"}}, false, "192.168.1.100", "无效的子网: 192.168.1.0/33"},
+		{"无效的子网不影响其他子网", MatchRule{Type: RuleSubnet, Values: []string{"bad", "192.168.1.0/24"}}, true, "192.168.1.100", "无效的子网: bad"},
+		{"DHCP服务器相同", MatchRule{Type: RuleDHCPServer, Values: []string{"192.168.1.1"}}, true, "192.168.1.1", ""},
+		{"DNS后缀忽略大小写和结尾的点", MatchRule{Type: RuleDNSSuffix, Values: []string{"LAN."}}, true, "lan", ""},
+		{"未知的规则类型", MatchRule{Type: "vlan", Values: []string{"10"}}, false, "", "未知的规则类型: vlan"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facts := testFacts
+			got := evaluateRule(tt.rule, &facts, nil)
+			if got.Matched != tt.matched || got.Actual != tt.actual || got.Note != tt.note {
+				t.Errorf("evaluateRule() = %+v, want matched=%v actual=%q note=%q", got, tt.matched, tt.actual, tt.note)
+			}
+		})
+	}
+}
+
+func TestEvaluateRuleDHCPFacts(t *testing.T) {
+	// 切换到静态IP后没有DHCP服务器和DNS后缀
+	static := testFacts
+	static.DHCP, static.IPAddress, static.DHCPServer, static.DNSSuffix = false, "192.168.1.50", "", ""
+	dhcpFacts := testFacts
+
+	tests := []struct {
+		name      string
+		rule      MatchRule
+		dhcpFacts *NetworkFacts
+		matched   bool
+		actual    string
+	}{
+		{"DHCP服务器使用DHCP时的记录", MatchRule{Type: RuleDHCPServer, Values: []string{"192.168.1.1"}}, &dhcpFacts, true, "192.168.1.1"},
+		{"DNS后缀使用DHCP时的记录", MatchRule{Type: RuleDNSSuffix, Values: []string{"lan"}}, &dhcpFacts, true, "lan"},
+		{"没有DHCP时的记录", MatchRule{Type: RuleDHCPServer, Values: []string{"192.168.1.1"}}, nil, false, ""},
+		{"其他规则使用当前信息", MatchRule{Type: RuleSubnet, Values: []string{"192.168.1.0/24"}}, &dhcpFacts, true, "192.168.1.50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateRule(tt.rule, &static, tt.dhcpFacts)
+			if got.Matched != tt.matched || got.Actual != tt.actual {
+				t.Errorf("evaluateRule() = %+v, want matched=%v actual=%q", got, tt.matched, tt.actual)
+			}
+			substituted := got.Note != ""
+			if want := tt.dhcpFacts != nil && (tt.rule.Type == RuleDHCPServer || tt.rule.Type == RuleDNSSuffix); substituted != want {
+				t.Errorf("note = %q, 是否使用DHCP时的记录 want %v", got.Note, want)
+			}
+		})
+	}
+
+	// DHCP状态下始终使用当前信息
+	current := testFacts
+	current.DHCPServer = "192.168.31.1"
+	if got := evaluateRule(MatchRule{Type: RuleDHCPServer, Values: []string{"192.168.1.1"}}, &current, &dhcpFacts); got.Matched || got.Note != "" {
+		t.Errorf("DHCP状态下 evaluateRule() = %+v, want current facts", got)
+	}
+}
+
+func TestMatchProfile(t *testing.T) {
+	ssid := MatchRule{Type: RuleSSID, Values: []string{"HomeWiFi"}}
+	otherSSID := MatchRule{Type: RuleSSID, Values: []string{"Office"}}
+	subnet := MatchRule{Type: RuleSubnet, Values: []string{"192.168.1.0/24"}}
+
+	tests := []struct {
+		name      string
+		profile   Profile
+		matched   bool
+		matchMode string
+		results   int
+	}{
+		{"没有规则", Profile{Name: "家"}, false, MatchAll, 0},
+		{"any没有规则", Profile{Name: "家", MatchMode: MatchAny}, false, MatchAny, 0},
+		{"SSIDs视为ssid规则", Profile{Name: "家", SSIDs: []string{"HomeWiFi"}}, true, MatchAll, 1},
+		{"all全部满足", Profile{Name: "家", Rules: []MatchRule{ssid, subnet}}, true, MatchAll, 2},
+		{"all一条不满足", Profile{Name: "家", Rules: []MatchRule{ssid, otherSSID}}, false, MatchAll, 2},
+		{"未知组合方式按all", Profile{Name: "家", MatchMode: "either", Rules: []MatchRule{ssid, otherSSID}}, false, MatchAll, 2},
+		{"any一条满足", Profile{Name: "家", MatchMode: MatchAny, Rules: []MatchRule{otherSSID, subnet}}, true, MatchAny, 2},
+		{"any全部不满足", Profile{Name: "家", MatchMode: MatchAny, SSIDs: []string{"Office"}, Rules: []MatchRule{{Type: RuleSubnet, Values: []string{"10.0.0.0/8"}}}}, false, MatchAny, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facts := testFacts
+			trace := MatchProfile(&tt.profile, &facts, nil)
+			if trace.Matched != tt.matched || trace.MatchMode != tt.matchMode || len(trace.Results) != tt.results {
+				t.Errorf("MatchProfile() = %+v, want matched=%v mode=%s results=%d", trace, tt.matched, tt.matchMode, tt.results)
+			}
+		})
+	}
+}
+
+func TestProfileTraceDescribe(t *testing.T) {
+	facts := testFacts
+	profile := Profile{Name: "家", Rules: []MatchRule{{Type: RuleSubnet, Values: []string{"bad"}}}}
+	lines := MatchProfile(&profile, &facts, nil).describe()
+	if len(lines) != 2 || !strings.Contains(lines[0], "不匹配") || !strings.Contains(lines[1], "(无效的子网: bad)") {
+		t.Errorf("describe() = %q", lines)
+	}
+
+	lines = MatchProfile(&Profile{Name: "家"}, &facts, nil).describe()
+	if len(lines) != 1 || !strings.Contains(lines[0], "没有匹配规则") {
+		t.Errorf("没有规则时 describe() = %q", lines)
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCommandTimeout 外部命令的默认超时时间
+const defaultCommandTimeout = 30 * time.Second
+
+// Command 待执行的外部命令
+type Command struct {
+	Name    string        // 命令名称
+	Args    []string      // 命令参数
+	Env     []string      // 追加的环境变量, 如 LC_ALL=C
+	Timeout time.Duration // 超时时间, 为0时使用默认值
+}
+
+// String 返回命令行文本
+func (c Command) String() string {
+	return strings.TrimSpace(c.Name + " " + strings.Join(c.Args, " "))
+}
+
+// CommandResult 命令执行结果
+type CommandResult struct {
+	Stdout   []byte // 标准输出
+	Stderr   []byte // 标准错误
+	ExitCode int    // 退出码, 命令未能启动时为 -1
+}
+
+// Output 返回标准输出和标准错误合并后的文本
+func (r *CommandResult) Output() string {
+	return string(r.Stdout) + string(r.Stderr)
+}
+
+// CommandRunner 外部命令执行器
+// 所有 netsh / nmcli / ip / schtasks 等命令都通过它执行，便于记录和回放
+type CommandRunner interface {
+	// Run 执行命令并等待结束，退出码非0时返回错误
+	// 返回的结果总是非nil，出错时也包含已捕获的输出
+	Run(ctx context.Context, cmd Command) (*CommandResult, error)
+	// Start 启动命令但不等待结束
+	Start(cmd Command) error
+}
+
+// runCmd 使用默认上下文执行命令
+func runCmd(runner CommandRunner, name string, args ...string) (*CommandResult, error) {
+	return runner.Run(context.Background(), Command{Name: name, Args: args})
+}
+
+// ExecRunner 直接执行系统命令
+type ExecRunner struct{}
+
+// NewExecRunner 创建系统命令执行器
+func NewExecRunner() *ExecRunner {
+	return &ExecRunner{}
+}
+
+// newExecCmd 创建隐藏窗口的 exec.Cmd
+func newExecCmd(ctx context.Context, c Command) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	hideCmdWindow(cmd)
+	return cmd
+}
+
+// Run 执行命令并等待结束
+func (r *ExecRunner) Run(ctx context.Context, c Command) (*CommandResult, error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := newExecCmd(ctx, c)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	result := &CommandResult{
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		ExitCode: cmd.ProcessState.ExitCode(),
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return result, fmt.Errorf("执行%s命令超时(%v)", c.Name, timeout)
+	}
+	return result, err
+}
+
+// Start 启动命令但不等待结束
+func (r *ExecRunner) Start(c Command) error {
+	return newExecCmd(context.Background(), c).Start()
+}
+
+// TranscriptEntry 命令记录中的一条
+// 输出按原始字节保存（JSON 中为 base64），保证回放时与真实命令的编码完全一致
+// 不经过外部命令的系统接口调用(网卡列表、ICMP Ping)同样记录, 名称为接口名称, 输出为结果的 JSON
+type TranscriptEntry struct {
+	Name     string   // 命令名称
+	Args     []string // 命令参数
+	Stdout   []byte   // 标准输出
+	Stderr   []byte   // 标准错误
+	ExitCode int      // 退出码
+	Error    string   // 执行错误, 成功时为空
+}
+
+// LoadTranscript 读取命令记录文件（每行一条 JSON）
+func LoadTranscript(path string) ([]TranscriptEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []TranscriptEntry
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var entry TranscriptEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("解析命令记录第%d行失败: %v", i+1, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// RecordingRunner 执行真实命令并把每次的输入输出追加写入记录文件
+// 用户可以把记录文件附在问题反馈中，开发者用 ReplayRunner 重现
+type RecordingRunner struct {
+	mu     sync.Mutex
+	runner CommandRunner
+	path   string
+}
+
+// NewRecordingRunner 创建记录模式的命令执行器
+func NewRecordingRunner(runner CommandRunner, path string) *RecordingRunner {
+	return &RecordingRunner{runner: runner, path: path}
+}
+
+// Run 执行命令并记录结果
+func (r *RecordingRunner) Run(ctx context.Context, c Command) (*CommandResult, error) {
+	result, err := r.runner.Run(ctx, c)
+
+	entry := TranscriptEntry{
+		Name:     c.Name,
+		Args:     c.Args,
+		Stdout:   result.Stdout,
+		Stderr:   result.Stderr,
+		ExitCode: result.ExitCode,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if werr := r.append(entry); werr != nil {
+		// 记录失败不影响命令本身
+		log.Printf("写入命令记录失败: %v", werr)
+	}
+
+	return result, err
+}
+
+// Start 启动命令，不记录输出
+func (r *RecordingRunner) Start(c Command) error {
+	return r.runner.Start(c)
+}
+
+// append 追加一条记录
+func (r *RecordingRunner) append(entry TranscriptEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// ReplayRunner 按记录文件回放命令输出，不执行任何真实命令
+// 相同命令按记录顺序依次返回，用完后重复返回最后一条
+type ReplayRunner struct {
+	mu      sync.Mutex
+	entries []TranscriptEntry
+	used    []bool
+	Started []Command // Start 调用的命令, 仅记录不执行
+}
+
+// NewReplayRunner 使用命令记录创建回放执行器
+func NewReplayRunner(entries []TranscriptEntry) *ReplayRunner {
+	return &ReplayRunner{entries: entries, used: make([]bool, len(entries))}
+}
+
+// Run 返回记录中与命令匹配的输出
+func (r *ReplayRunner) Run(ctx context.Context, c Command) (*CommandResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, entry := range r.entries {
+		if entry.Name != c.Name || !slices.Equal(entry.Args, c.Args) {
+			continue
+		}
+		last = i
+		if !r.used[i] {
+			r.used[i] = true
+			return r.result(entry)
+		}
+	}
+	if last != -1 {
+		return r.result(r.entries[last])
+	}
+	return &CommandResult{ExitCode: -1}, fmt.Errorf("命令记录中没有找到: %s", c)
+}
+
+// result 将记录转换为执行结果
+func (r *ReplayRunner) result(entry TranscriptEntry) (*CommandResult, error) {
+	result := &CommandResult{Stdout: entry.Stdout, Stderr: entry.Stderr, ExitCode: entry.ExitCode}
+	if entry.Error != "" {
+		return result, errors.New(entry.Error)
+	}
+	return result, nil
+}
+
+// Start 记录启动的命令
+func (r *ReplayRunner) Start(c Command) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Started = append(r.Started, c)
+	return nil
+}
+
+// recordCall 执行不经过外部命令的系统接口调用, 按 runner 的记录模式记录结果
+// 记录模式下把结果的 JSON 写入命令记录, 回放模式下从记录中读取结果而不调用系统接口, 记录中没有时返回错误
+func recordCall[T any](runner CommandRunner, call Command, fn func() (T, error)) (T, error) {
+	switch r := runner.(type) {
+	case *ReplayRunner:
+		var value T
+		result, err := r.Run(context.Background(), call)
+		if len(result.Stdout) > 0 {
+			if jerr := json.Unmarshal(result.Stdout, &value); jerr != nil {
+				return value, fmt.Errorf("解析命令记录中 %s 的结果失败: %v", call, jerr)
+			}
+		}
+		return value, err
+	case *RecordingRunner:
+		value, err := fn()
+		entry := TranscriptEntry{Name: call.Name, Args: call.Args}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		stdout, jerr := json.Marshal(value)
+		if jerr == nil {
+			entry.Stdout = stdout
+			jerr = r.append(entry)
+		}
+		if jerr != nil {
+			log.Printf("写入命令记录失败: %v", jerr)
+		}
+		return value, err
+	default:
+		return fn()
+	}
+}
+
+// NewCommandRunner 根据记录模式创建命令执行器
+// mode 为 record 时记录到 transcriptFile，为 replay 时从 transcriptFile 回放，其他情况直接执行
+func NewCommandRunner(mode, transcriptFile string) (CommandRunner, error) {
+	switch mode {
+	case "", "off":
+		return NewExecRunner(), nil
+	case "record":
+		if transcriptFile == "" {
+			return nil, fmt.Errorf("记录模式需要指定命令记录文件")
+		}
+		return NewRecordingRunner(NewExecRunner(), transcriptFile), nil
+	case "replay":
+		entries, err := LoadTranscript(transcriptFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取命令记录失败: %v", err)
+		}
+		return NewReplayRunner(entries), nil
+	default:
+		return nil, fmt.Errorf("未知的命令记录模式: %s", mode)
+	}
+}
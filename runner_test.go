@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// loadReplayRunner 读取命令记录并创建回放执行器
+func loadReplayRunner(t *testing.T, path string) *ReplayRunner {
+	t.Helper()
+	entries, err := LoadTranscript(path)
+	if err != nil {
+		t.Fatalf("读取命令记录失败: %v", err)
+	}
+	return NewReplayRunner(entries)
+}
+
+func TestReplayNetshSetStaticIP(t *testing.T) {
+	catalog, err := NewKeywordCatalog("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := NewNetshBackend(loadReplayRunner(t, "testdata/transcripts/netsh_static.jsonl"), catalog)
+	dns := []string{"192.168.31.2", "223.5.5.5"}
+
+	isDHCP, err := backend.GetCurrentIPConfig("WLAN")
+	if err != nil {
+		t.Fatalf("GetCurrentIPConfig: %v", err)
+	}
+	if !isDHCP {
+		t.Fatal("切换前应为DHCP")
+	}
+
+	if err := backend.SetStaticIP("WLAN", "192.168.31.100", "255.255.255.0", "192.168.31.2", dns); err != nil {
+		t.Fatalf("SetStaticIP: %v", err)
+	}
+
+	isStatic, err := backend.GetCurrentStaticIPConfig("WLAN", "192.168.31.100", "255.255.255.0", "192.168.31.2", dns)
+	if err != nil {
+		t.Fatalf("GetCurrentStaticIPConfig: %v", err)
+	}
+	if !isStatic {
+		t.Fatal("切换后应为目标静态IP配置")
+	}
+
+	config, err := backend.GetIPv4Config("WLAN")
+	if err != nil {
+		t.Fatalf("GetIPv4Config: %v", err)
+	}
+	want := &IPv4Config{IP: "192.168.31.100", SubnetMask: "255.255.255.0", Gateway: "192.168.31.2", DNS: dns, StaticDNS: true}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("GetIPv4Config = %+v, want %+v", config, want)
+	}
+}
+
+func TestReplayNmcliSetStaticIP(t *testing.T) {
+	backend := NewNmcliBackend(loadReplayRunner(t, "testdata/transcripts/nmcli_static.jsonl"))
+	dns := []string{"192.168.31.2", "223.5.5.5"}
+
+	isDHCP, err := backend.GetCurrentIPConfig("wlp2s0")
+	if err != nil {
+		t.Fatalf("GetCurrentIPConfig: %v", err)
+	}
+	if !isDHCP {
+		t.Fatal("切换前应为DHCP")
+	}
+
+	if err := backend.SetStaticIP("wlp2s0", "192.168.31.100", "255.255.255.0", "192.168.31.2", dns); err != nil {
+		t.Fatalf("SetStaticIP: %v", err)
+	}
+
+	isStatic, err := backend.GetCurrentStaticIPConfig("wlp2s0", "192.168.31.100", "255.255.255.0", "192.168.31.2", dns)
+	if err != nil {
+		t.Fatalf("GetCurrentStaticIPConfig: %v", err)
+	}
+	if !isStatic {
+		t.Fatal("切换后应为目标静态IP配置")
+	}
+}
+
+func TestReplayRunnerUnknownCommand(t *testing.T) {
+	runner := loadReplayRunner(t, "testdata/transcripts/nmcli_static.jsonl")
+	if _, err := runner.Run(context.Background(), Command{Name: "nmcli", Args: []string{"radio", "wifi"}}); err == nil {
+		t.Error("记录中没有的命令应返回错误")
+	}
+}
+
+func TestRecordingRunnerRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	stub := &stubRunner{
+		outputs:  map[string]string{"netsh wlan show interfaces": "Name : WLAN\r\n"},
+		failures: map[string]bool{"netsh interface ip set dns WLAN dhcp": true},
+	}
+	recorder := NewRecordingRunner(stub, path)
+	ctx := context.Background()
+
+	show := Command{Name: "netsh", Args: []string{"wlan", "show", "interfaces"}}
+	setDNS := Command{Name: "netsh", Args: []string{"interface", "ip", "set", "dns", "WLAN", "dhcp"}}
+	if _, err := recorder.Run(ctx, show); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recorder.Run(ctx, setDNS); err == nil {
+		t.Fatal("失败的命令应返回错误")
+	}
+
+	replay := loadReplayRunner(t, path)
+	result, err := replay.Run(ctx, show)
+	if err != nil || result.Output() != "Name : WLAN\r\n" {
+		t.Errorf("回放输出 = %q, %v", result.Output(), err)
+	}
+	result, err = replay.Run(ctx, setDNS)
+	if err == nil || result.ExitCode != 1 {
+		t.Errorf("回放失败命令 = %+v, %v", result, err)
+	}
+}
+
+func TestRecordCallRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	recorder := NewRecordingRunner(&stubRunner{}, path)
+	list := Command{Name: "GetAdaptersAddresses"}
+	adapters := []windowsAdapter{{Name: "WLAN", Index: 12, Type: InterfaceWiFi, DHCPServer: "192.168.31.1",
+		IPv6: IPv6Config{Gateways: []string{"fe80::1"}}}}
+
+	got, err := recordCall(recorder, list, func() ([]windowsAdapter, error) { return adapters, nil })
+	if err != nil || !reflect.DeepEqual(got, adapters) {
+		t.Fatalf("记录模式 recordCall = %+v, %v", got, err)
+	}
+	// 回放时不调用系统接口
+	replay := loadReplayRunner(t, path)
+	got, err = recordCall(replay, list, func() ([]windowsAdapter, error) {
+		t.Error("回放模式不应调用系统接口")
+		return nil, nil
+	})
+	if err != nil || !reflect.DeepEqual(got, adapters) {
+		t.Errorf("回放 recordCall = %+v, %v", got, err)
+	}
+
+	// 记录中没有的Ping结果返回错误, 视为不可达
+	ping := &Pinger{Count: 1, Runner: replay}
+	if result := ping.Ping("192.168.31.2"); result.Reachable() || result.Err == nil || !strings.Contains(result.Err.Error(), "IcmpEcho 192.168.31.2") {
+		t.Errorf("记录中没有的Ping = %+v", result)
+	}
+}
+
+func TestReplayNetshAdapters(t *testing.T) {
+	catalog, err := NewKeywordCatalog("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	adapters, _ := json.Marshal([]windowsAdapter{{Name: "WLAN", Index: 12, Type: InterfaceWiFi,
+		IPv6: IPv6Config{Gateways: []string{"fe80::1"}, DNSServers: []string{"fe80::1"}}}})
+	ping, _ := json.Marshal(&PingResult{Host: "192.168.31.2", Sent: 3, Received: 2, TTL: 64})
+	runner := NewReplayRunner([]TranscriptEntry{
+		{Name: "GetAdaptersAddresses", Stdout: adapters},
+		{Name: "IcmpEcho", Args: []string{"192.168.31.2"}, Stdout: ping},
+		{Name: "IcmpEcho", Args: []string{"192.168.31.3"}, Stdout: []byte(`{"Host":"192.168.31.3","Sent":3,"TTL":-1}`), Error: "sendto: network is unreachable"},
+	})
+	backend := NewNetshBackend(runner, catalog)
+
+	config, err := backend.GetIPv6Config("WLAN")
+	if err != nil || !reflect.DeepEqual(config.Gateways, []string{"fe80::1"}) {
+		t.Errorf("GetIPv6Config = %+v, %v", config, err)
+	}
+	if types, err := backend.interfaceTypes(); err != nil || types["WLAN"] != InterfaceWiFi {
+		t.Errorf("interfaceTypes = %v, %v", types, err)
+	}
+	if !backend.Ping("192.168.31.2") {
+		t.Error("回放的Ping结果应为可达")
+	}
+	results := backend.pinger.PingAll("192.168.31.3")
+	if results[0].Reachable() || results[0].Err == nil || results[0].Err.Error() != "sendto: network is unreachable" {
+		t.Errorf("回放的Ping错误 = %+v", results[0])
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// splitRouteSet 网卡上已添加的分流路由
+type splitRouteSet struct {
+	Gateway      string   // 分流路由经过的网关(旁路由)
+	Destinations []string // 分流目标, CIDR
+}
+
+// splitRouting 网络配置是否设置了分流
+// 设置了分流时不修改IP, 只把分流目标的路由指向旁路由
+func (p *Profile) splitRouting() bool {
+	return len(p.SplitRoutes) > 0 || p.SplitRoutesFile != ""
+}
+
+// keepsDHCPAddress 使用旁路由时是否保留DHCP地址(分流或按跃点数切换)
+// 此时离开旁路由只需删除路由, 不使用 FallbackGateway
+func (p *Profile) keepsDHCPAddress() bool {
+	return p.splitRouting() || p.Strategy == StrategyMetric
+}
+
+// splitRoutes 合并 SplitRoutes 和 SplitRoutesFile 中的分流目标, 统一为网络地址的 CIDR 写法并去重
+// 每次切换时重新读取文件, 修改文件后不需要重新保存配置
+func (p *Profile) splitRoutes() ([]string, error) {
+	entries := append([]string(nil), p.SplitRoutes...)
+	if p.SplitRoutesFile != "" {
+		content, err := os.ReadFile(splitRoutesFilePath(p.SplitRoutesFile))
+		if err != nil {
+			return nil, fmt.Errorf("读取分流目标文件失败: %v", err)
+		}
+		entries = append(entries, strings.Split(string(content), "\n")...)
+	}
+
+	var routes []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if i := strings.Index(entry, "#"); i != -1 {
+			entry = entry[:i]
+		}
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, err := normalizeCIDR(entry)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[route] {
+			seen[route] = true
+			routes = append(routes, route)
+		}
+	}
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("分流目标不能为空")
+	}
+	return routes, nil
+}
+
+// normalizeCIDR 将IPv4地址或 CIDR 统一为网络地址的 CIDR 写法, 如 10.1.2.3/8 为 10.0.0.0/8, 单个地址为 /32
+// 0.0.0.0/0 和 ::/0 等默认路由不能作为分流目标
+func normalizeCIDR(entry string) (string, error) {
+	if !strings.Contains(entry, "/") {
+		entry += "/32"
+	}
+	ip, network, err := net.ParseCIDR(entry)
+	if err != nil || ip.To4() == nil {
+		return "", fmt.Errorf("无效的分流目标: %s", entry)
+	}
+	// 默认路由会替换主路由网关, 应使用切换方式而不是分流
+	if ones, _ := network.Mask.Size(); ones == 0 {
+		return "", fmt.Errorf("分流目标不能是默认路由: %s", entry)
+	}
+	return network.String(), nil
+}
+
+// splitRoutesFilePath 分流目标文件的路径, 相对路径相对于程序所在目录
+func splitRoutesFilePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(filepath.Dir(exePath), path)
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestNormalizeCIDR(t *testing.T) {
+	tests := []struct {
+		entry string
+		want  string
+		ok    bool
+	}{
+		{"10.1.2.3/8", "10.0.0.0/8", true},
+		{"8.8.8.8", "8.8.8.8/32", true},
+		{"0.0.0.0/1", "0.0.0.0/1", true},
+		{"0.0.0.0/0", "", false},
+		{"1.2.3.4/0", "", false},
+		{"::/0", "", false},
+		{"2001:db8::/32", "", false},
+		{"10.0.0.0/33", "", false},
+		{"example.com", "", false},
+	}
+	for _, tt := range tests {
+		got, err := normalizeCIDR(tt.entry)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("normalizeCIDR(%q) = %q, %v", tt.entry, got, err)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// 自适应切换防抖的默认值
+const (
+	DefaultLeaveAfterFailures   = 3   // 连续失败3次后切回动态IP
+	DefaultReturnAfterSuccesses = 2   // 连续成功2次后切回静态IP
+	DefaultMinDwellSeconds      = 120 // 每次切换后至少保持2分钟
+	DefaultMaxSwitchesPerHour   = 6   // 一小时内最多自动切换6次
+	DefaultVerifySeconds        = 15  // 切换后15秒内网关仍不可达时恢复切换前的配置
+)
+
+// SwitchPolicy 自适应模式的防抖和熔断设置, 为0时使用默认值
+type SwitchPolicy struct {
+	LeaveAfterFailures   int // 旁路由连续检查不健康多少次后切回动态IP或主路由网关
+	ReturnAfterSuccesses int // 旁路由连续检查健康多少次后切回静态IP
+	MinDwellSeconds      int // 每次切换后至少保持多少秒才能再次切换
+	MaxSwitchesPerHour   int // 一小时内最多因旁路由健康状况切换的次数, 超过后暂停自动切换并通知用户
+	VerifySeconds        int // 切换后检查新网关连通性的最长时间, 超时仍不可达时恢复切换前的配置
+}
+
+// withDefaults 返回填充了默认值的设置
+func (p SwitchPolicy) withDefaults() SwitchPolicy {
+	if p.LeaveAfterFailures <= 0 {
+		p.LeaveAfterFailures = DefaultLeaveAfterFailures
+	}
+	if p.ReturnAfterSuccesses <= 0 {
+		p.ReturnAfterSuccesses = DefaultReturnAfterSuccesses
+	}
+	if p.MinDwellSeconds <= 0 {
+		p.MinDwellSeconds = DefaultMinDwellSeconds
+	}
+	if p.MaxSwitchesPerHour <= 0 {
+		p.MaxSwitchesPerHour = DefaultMaxSwitchesPerHour
+	}
+	if p.VerifySeconds <= 0 {
+		p.VerifySeconds = DefaultVerifySeconds
+	}
+	return p
+}
+
+// validate 检查防抖设置
+func (p SwitchPolicy) validate() error {
+	if p.LeaveAfterFailures < 0 || p.ReturnAfterSuccesses < 0 || p.MinDwellSeconds < 0 || p.MaxSwitchesPerHour < 0 || p.VerifySeconds < 0 {
+		return fmt.Errorf("切换防抖设置不能为负数")
+	}
+	return nil
+}
+
+// SwitchGuard 一个网卡的自适应切换状态
+// 匹配到的网络配置变化(如离开或回到家)时立即切换;
+// 只是旁路由健康状况变化时, 需要连续多次相同结果、超过最短保持时间才切换,
+// 一小时内切换次数过多时暂停自动切换, 直到用户重新选择自适应模式
+type SwitchGuard struct {
+	Action    string      // 当前动作: static, fallback 或 dhcp, 为空时表示还没有判断过
+	Profile   string      // 上次匹配到的网络配置, 没有匹配时为空
+	Since     time.Time   // 进入当前动作的时间
+	Failures  int         // 使用旁路由时旁路由连续不健康的次数
+	Successes int         // 离开旁路由后旁路由连续健康的次数
+	Switches  []time.Time // 最近一小时内因旁路由健康状况切换的时间
+	Tripped   bool        // 是否因切换过于频繁暂停了自动切换
+}
+
+// Decide 根据本次判断结果决定实际动作
+// want 为按规则和健康检查得出的动作, profile 为匹配到的网络配置;
+// 返回实际动作、便于阅读的说明, 以及本次是否刚暂停自动切换
+func (g *SwitchGuard) Decide(policy SwitchPolicy, want, profile string, now time.Time) (action string, notes []string, tripped bool) {
+	policy = policy.withDefaults()
+	matchChanged := g.Action == "" || profile != g.Profile
+	g.Profile = profile
+
+	// 首次判断或所在网络变化, 立即按结果切换
+	if matchChanged {
+		if want != g.Action {
+			g.enter(want, now)
+		}
+		g.Failures, g.Successes = 0, 0
+		return g.Action, nil, false
+	}
+
+	if want == g.Action {
+		g.Failures, g.Successes = 0, 0
+		return g.Action, nil, false
+	}
+
+	if g.Tripped {
+		return g.Action, []string{fmt.Sprintf("防抖: 切换过于频繁, 已暂停自动切换, 保持%s", actionName(g.Action))}, false
+	}
+
+	count, need := 0, 0
+	if want != "static" {
+		g.Failures++
+		count, need = g.Failures, policy.LeaveAfterFailures
+	} else {
+		g.Successes++
+		count, need = g.Successes, policy.ReturnAfterSuccesses
+	}
+	if count < need {
+		return g.Action, []string{fmt.Sprintf("防抖: 连续 %d/%d 次需要切换到%s, 暂时保持%s", count, need, actionName(want), actionName(g.Action))}, false
+	}
+
+	minDwell := time.Duration(policy.MinDwellSeconds) * time.Second
+	if dwell := now.Sub(g.Since); dwell < minDwell {
+		return g.Action, []string{fmt.Sprintf("防抖: 距上次切换 %s, 未达到最短保持时间 %s, 暂时保持%s",
+			dwell.Round(time.Second), minDwell, actionName(g.Action))}, false
+	}
+
+	g.forgetOldSwitches(now)
+	if len(g.Switches) >= policy.MaxSwitchesPerHour {
+		g.Tripped = true
+		return g.Action, []string{fmt.Sprintf("防抖: 一小时内已切换 %d 次, 暂停自动切换, 保持%s", len(g.Switches), actionName(g.Action))}, true
+	}
+
+	g.Switches = append(g.Switches, now)
+	g.enter(want, now)
+	return want, []string{fmt.Sprintf("防抖: 连续 %d 次需要切换, 切换到%s", count, actionName(want))}, false
+}
+
+// RolledBack 切换到当前动作失败并已恢复切换前的配置, 回到恢复后的动作 action
+// 恢复同样计入切换次数, 之后仍需连续多次判断和超过最短保持时间才会再次尝试;
+// 一小时内切换次数过多时暂停自动切换, 返回本次是否刚暂停
+func (g *SwitchGuard) RolledBack(policy SwitchPolicy, action string, now time.Time) bool {
+	policy = policy.withDefaults()
+	g.forgetOldSwitches(now)
+	g.Switches = append(g.Switches, now)
+	g.enter(action, now)
+	if !g.Tripped && len(g.Switches) >= policy.MaxSwitchesPerHour {
+		g.Tripped = true
+		return true
+	}
+	return false
+}
+
+// forgetOldSwitches 只保留最近一小时内的切换记录
+func (g *SwitchGuard) forgetOldSwitches(now time.Time) {
+	recent := g.Switches[:0]
+	for _, t := range g.Switches {
+		if now.Sub(t) < time.Hour {
+			recent = append(recent, t)
+		}
+	}
+	g.Switches = recent
+}
+
+// enter 进入新的动作并清空计数
+func (g *SwitchGuard) enter(action string, now time.Time) {
+	g.Action = action
+	g.Since = now
+	g.Failures, g.Successes = 0, 0
+}
+
+// actionName 动作的显示名称
+func actionName(action string) string {
+	switch action {
+	case "static":
+		return "旁路由"
+	case "fallback":
+		return "主路由网关"
+	default:
+		return "动态IP"
+	}
+}
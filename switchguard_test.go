@@ -0,0 +1,149 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// testPolicy 便于测试的防抖设置: 连续2次失败离开, 连续2次成功返回, 保持1分钟, 每小时最多切换2次
+var testPolicy = SwitchPolicy{LeaveAfterFailures: 2, ReturnAfterSuccesses: 2, MinDwellSeconds: 60, MaxSwitchesPerHour: 2}
+
+// decideAt 在 start 之后 offset 时判断一次, 返回实际动作
+func decideAt(g *SwitchGuard, want, profile string, start time.Time, offset time.Duration) string {
+	action, _, _ := g.Decide(testPolicy, want, profile, start.Add(offset))
+	return action
+}
+
+func TestSwitchGuardMatchChangeSwitchesImmediately(t *testing.T) {
+	start := time.Now()
+	g := &SwitchGuard{}
+
+	if got := decideAt(g, "static", "家", start, 0); got != "static" {
+		t.Fatalf("首次判断 = %s, want static", got)
+	}
+	// 离开网络配置时不需要连续失败, 也不受最短保持时间限制
+	if got := decideAt(g, "dhcp", "", start, time.Second); got != "dhcp" {
+		t.Fatalf("离开网络配置 = %s, want dhcp", got)
+	}
+	if got := decideAt(g, "static", "家", start, 2*time.Second); got != "static" {
+		t.Fatalf("回到网络配置 = %s, want static", got)
+	}
+	if len(g.Switches) != 0 {
+		t.Errorf("网络配置变化不应计入切换次数: %v", g.Switches)
+	}
+}
+
+func TestSwitchGuardDebounce(t *testing.T) {
+	start := time.Now()
+	g := &SwitchGuard{}
+	decideAt(g, "static", "家", start, 0)
+
+	// 连续失败次数不足时保持
+	if got := decideAt(g, "dhcp", "家", start, 2*time.Minute); got != "static" {
+		t.Fatalf("第1次失败 = %s, want static", got)
+	}
+	// 中间一次成功清空计数
+	decideAt(g, "static", "家", start, 3*time.Minute)
+	if got := decideAt(g, "dhcp", "家", start, 4*time.Minute); got != "static" || g.Failures != 1 {
+		t.Fatalf("成功后重新计数 = %s, failures=%d", got, g.Failures)
+	}
+	if got := decideAt(g, "dhcp", "家", start, 5*time.Minute); got != "dhcp" {
+		t.Fatalf("连续2次失败 = %s, want dhcp", got)
+	}
+
+	// 切换后未达到最短保持时间
+	decideAt(g, "static", "家", start, 5*time.Minute+10*time.Second)
+	if got := decideAt(g, "static", "家", start, 5*time.Minute+20*time.Second); got != "dhcp" {
+		t.Fatalf("未达到最短保持时间 = %s, want dhcp", got)
+	}
+	if got := decideAt(g, "static", "家", start, 7*time.Minute); got != "static" {
+		t.Fatalf("达到最短保持时间 = %s, want static", got)
+	}
+}
+
+func TestSwitchGuardTripsAfterTooManySwitches(t *testing.T) {
+	start := time.Now()
+	g := &SwitchGuard{}
+	decideAt(g, "static", "家", start, 0)
+
+	// 每次连续2次相同结果后切换, 间隔超过最短保持时间
+	offset := time.Duration(0)
+	flap := func(want string) (string, bool) {
+		var action string
+		var tripped bool
+		for range 2 {
+			offset += 2 * time.Minute
+			action, _, tripped = g.Decide(testPolicy, want, "家", start.Add(offset))
+		}
+		return action, tripped
+	}
+
+	if got, _ := flap("fallback"); got != "fallback" {
+		t.Fatalf("第1次切换 = %s", got)
+	}
+	if got, _ := flap("static"); got != "static" {
+		t.Fatalf("第2次切换 = %s", got)
+	}
+	got, tripped := flap("fallback")
+	if got != "static" || !tripped || !g.Tripped {
+		t.Fatalf("第3次切换 = %s, tripped=%v, want 保持 static 并暂停", got, tripped)
+	}
+
+	// 暂停后保持当前动作, 不再重复通知
+	action, notes, tripped := g.Decide(testPolicy, "fallback", "家", start.Add(offset+time.Hour))
+	if action != "static" || tripped || len(notes) != 1 {
+		t.Errorf("暂停后 = %s, %v, %v", action, notes, tripped)
+	}
+}
+
+func TestSwitchGuardForgetsOldSwitches(t *testing.T) {
+	start := time.Now()
+	g := &SwitchGuard{Action: "static", Profile: "家", Since: start, Switches: []time.Time{start.Add(-2 * time.Hour), start.Add(-90 * time.Minute)}}
+
+	decideAt(g, "dhcp", "家", start, 2*time.Minute)
+	if got := decideAt(g, "dhcp", "家", start, 3*time.Minute); got != "dhcp" || g.Tripped {
+		t.Fatalf("一小时前的切换不应计数: %s, tripped=%v", got, g.Tripped)
+	}
+	if len(g.Switches) != 1 {
+		t.Errorf("Switches = %v, want 1", g.Switches)
+	}
+}
+
+func TestSwitchPolicyDefaults(t *testing.T) {
+	policy := SwitchPolicy{}.withDefaults()
+	want := SwitchPolicy{
+		LeaveAfterFailures:   DefaultLeaveAfterFailures,
+		ReturnAfterSuccesses: DefaultReturnAfterSuccesses,
+		MinDwellSeconds:      DefaultMinDwellSeconds,
+		MaxSwitchesPerHour:   DefaultMaxSwitchesPerHour,
+		VerifySeconds:        DefaultVerifySeconds,
+	}
+	if policy != want {
+		t.Errorf("withDefaults = %+v, want %+v", policy, want)
+	}
+	if err := (SwitchPolicy{MinDwellSeconds: -1}).validate(); err == nil {
+		t.Error("负数应返回错误")
+	}
+}
+
+func TestSwitchGuardRolledBack(t *testing.T) {
+	start := time.Now()
+	g := &SwitchGuard{}
+	decideAt(g, "static", "家", start, 0)
+
+	// 切换失败恢复后回到动态IP, 需要重新连续成功并超过最短保持时间才再次尝试
+	if g.RolledBack(testPolicy, "dhcp", start.Add(time.Second)) {
+		t.Fatal("第1次恢复不应暂停")
+	}
+	if got := decideAt(g, "static", "家", start, 2*time.Second); got != "dhcp" {
+		t.Fatalf("恢复后 = %s, want dhcp", got)
+	}
+	if got := decideAt(g, "static", "家", start, 2*time.Minute); got != "static" {
+		t.Fatalf("连续成功并超过最短保持时间 = %s, want static", got)
+	}
+
+	// 恢复计入切换次数, 次数过多时暂停
+	if !g.RolledBack(testPolicy, "dhcp", start.Add(3*time.Minute)) || !g.Tripped {
+		t.Errorf("一小时内切换 %d 次后应暂停", len(g.Switches))
+	}
+}
@@ -1,24 +1,106 @@
 package main
 
+// Profile 一个网络环境（如家、办公室、父母家）的旁路由配置
+type Profile struct {
+	Name       string      // 配置名称
+	SSIDs      []string    // 使用该配置的WiFi名称(SSID), 等同于一条 ssid 规则
+	MatchMode  string      // 规则组合方式: all(全部满足, 默认), any(任一满足)
+	Rules      []MatchRule // 其他匹配规则: bssid, subnet, gateway_mac, dhcp_server, dns_suffix
+	StaticIP   string      // 静态IP地址, 可以带前缀长度, 如 10.0.0.50/16
+	SubnetMask string      // 子网掩码, 如 255.255.0.0; 与前缀长度都未设置时为 255.255.255.0
+	Gateway    string      // 网关地址(旁路由)
+	DNS        DNSList     // DNS服务器地址, 按优先顺序
+	Strategy   string      // 切换到旁路由的方式: address(修改IP地址, 默认), metric(保留DHCP地址, 添加跃点数更小的默认路由)
+
+	FallbackGateway string  // 旁路由不健康时改用的网关(主路由), 设置后保留静态IP只切换网关和DNS; 为空时切回动态IP
+	FallbackDNS     DNSList // 改用主路由网关时的DNS, 为空时使用 FallbackGateway
+
+	SplitRoutes     []string // 分流: 只有这些目标(CIDR, 如 10.0.0.0/8)经过旁路由, 其他流量仍使用DHCP网关
+	SplitRoutesFile string   // 分流目标列表文件, 每行一个 CIDR, # 开头为注释; 相对路径相对于程序所在目录
+
+	IPv6Mode    string  // 静态IP时的IPv6处理方式: 空(不处理), static(静态IPv6), disable(停用IPv6自动配置和DNS)
+	StaticIPv6  string  // 静态IPv6地址, 带前缀长度, 如 fd00::100/64; 不带前缀长度时为 /64
+	GatewayIPv6 string  // IPv6网关地址
+	DNSv6       DNSList // IPv6 DNS服务器, 按优先顺序; 为空时不使用IPv6 DNS
+
+	HealthChecks []HealthProbe // 旁路由健康检查, 为空时只Ping网关
+	HealthMode   string        // 健康检查组合方式: all(全部通过, 默认), any(任一通过), quorum(至少 HealthQuorum 项通过)
+	HealthQuorum int           // quorum 方式至少需要通过的检查数量, 为0时为过半数
+}
+
+// DNSList 按优先顺序排列的DNS服务器, 第一个为首选DNS
+// 兼容旧版本配置文件中逗号分隔的字符串写法
+type DNSList []string
+
+// InterfaceSetting 单独管理的网卡, 按名称或MAC地址固定
+type InterfaceSetting struct {
+	Name    string // 网卡名称, 如 "WLAN"、"以太网 2"
+	MAC     string // 网卡MAC地址, 设置后按MAC地址查找, 网卡改名后仍然有效
+	IPMode  string // 该网卡的IP模式, 为空时使用全局 IPMode
+	Profile string // 该网卡使用的网络配置名称, 为空时按规则匹配全部网络配置
+}
+
 // Config 配置结构
 type Config struct {
-	HomeSSID  string // 家庭WiFi的SSID
-	StaticIP  string // 静态IP地址
-	Gateway   string // 网关地址
-	DNS       string // DNS服务器地址
-	AutoStart bool   // 是否开机自启
-	IPMode    string // IP模式: adaptive(自适应), dynamic(动态IP), static(静态IP)
+	Profiles  []Profile // 网络配置列表, 自适应模式下按顺序匹配当前网络
+	AutoStart bool      // 是否开机自启
+	IPMode    string    // IP模式: adaptive(自适应), dynamic(动态IP), static(静态IP), override(动态IP+旁路由)
+	Backend   string    // 网络后端: auto(按系统自动选择), netsh, nmcli, networkd
+	Locale    string    // netsh 输出语言: auto(自动识别), zh-CN, zh-TW, en, ja, de, fr
+
+	InterfaceTypes string             // 管理的网卡类型: wifi(无线), ethernet(有线), both(全部)
+	Interfaces     []InterfaceSetting // 单独管理的网卡, 为空时管理 InterfaceTypes 类型的活动网卡
+
+	Switching SwitchPolicy  // 自适应模式的防抖和熔断设置
+	Monitor   MonitorPolicy // 自适应模式的检查时间设置
+
+	TranscriptMode string // 命令记录模式: off(关闭), record(记录), replay(回放)
+	TranscriptFile string // 命令记录文件路径
+
+	// 以下为旧版本的单一网络配置，加载时自动迁移到 Profiles
+	HomeSSID string `json:",omitempty"` // 家庭WiFi的SSID
+	StaticIP string `json:",omitempty"` // 静态IP地址
+	Gateway  string `json:",omitempty"` // 网关地址
+	DNS      string `json:",omitempty"` // DNS服务器地址
 }
 
 // NetworkStatus 网络状态结构
 type NetworkStatus struct {
-	WiFiName         string // WiFi名称
-	WiFiConnected    bool   // WiFi连接状态
-	IPAddress        string // 当前IP地址
-	Gateway          string // 当前网关
-	GatewayReachable bool   // 网关是否可达
-	DNS              string // 当前DNS
-	DNSReachable     bool   // DNS是否可达
-	IPAssignment     string // IP分配方式: "自动(DHCP)" 或 "手动"
-	DNSAssignment    string // DNS分配方式: "自动(DHCP)" 或 "手动"
+	Interface        string            // 网络接口名称
+	InterfaceType    string            // 网卡类型: wifi 或 ethernet
+	WiFiName         string            // WiFi名称
+	WiFiConnected    bool              // WiFi连接状态
+	IPAddress        string            // 当前IP地址
+	Gateway          string            // 当前网关
+	GatewayReachable bool              // 网关是否可达
+	GatewayRTT       float64           // 网关平均往返时间(毫秒)
+	DNS              string            // 当前首选DNS
+	DNSReachable     bool              // 首选DNS是否可达
+	DNSServers       []DNSServerStatus // 当前全部DNS服务器, 按优先顺序
+	IPAssignment     string            // IP分配方式: "自动(DHCP)" 或 "手动"
+	DNSAssignment    string            // DNS分配方式: "自动(DHCP)" 或 "手动"
+	ActiveProfile    string            // 当前生效的网络配置名称, 动态IP时为空
+	SplitRoutes      []string          // 已添加的经过旁路由的分流路由目标(CIDR)
+	SplitGateway     string            // 分流路由经过的网关(旁路由)
+	IPv6Addresses    []string          // IPv6地址(带前缀长度), 不包含链路本地地址
+	IPv6Gateways     []string          // IPv6默认网关
+	IPv6DNS          []string          // IPv6 DNS服务器
+}
+
+// DNSServerStatus 一个DNS服务器的连通性
+type DNSServerStatus struct {
+	Address   string  // DNS服务器地址
+	Reachable bool    // 是否可达
+	RTT       float64 // 平均往返时间(毫秒)
+}
+
+// SwitchResult 一次切换的结果, 切换失败或切换后网关不可达时记录是否已恢复切换前的配置
+type SwitchResult struct {
+	Time          string // 切换时间
+	Interface     string // 网卡名称
+	Target        string // 切换目标, 如网络配置名称或动态IP
+	Success       bool   // 是否切换成功且通过连通性检查
+	RolledBack    bool   // 是否已恢复切换前的配置
+	Error         string // 切换或连通性检查失败的原因
+	RollbackError string // 恢复切换前的配置失败的原因
 }